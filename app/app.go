@@ -6,6 +6,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"sync"
 
 	"go.uber.org/zap"
@@ -20,10 +22,12 @@ import (
 
 // App represents the application struct instance
 type App struct {
-	stopOnce sync.Once
-	Server   *http.Server
-	Cfg      *config.Manager
-	dbCloser repositories.Closer
+	stopOnce  sync.Once
+	Server    *http.Server
+	Cfg       *config.Manager
+	dbCloser  repositories.Closer
+	alerts    *services.Alerts
+	scheduler *services.Scheduler
 }
 
 // Init initializes the application
@@ -35,14 +39,27 @@ func Init(configPath string) (*App, error) {
 	if err = logging.Init(configManager); err != nil {
 		return nil, fmt.Errorf("could not initialize logger: %v", err)
 	}
+	currencies, err := configManager.Currencies()
+	if err != nil {
+		return nil, err
+	}
+	if err = models.SetCurrencies(currencies); err != nil {
+		return nil, fmt.Errorf("could not initialize currency catalogue: %v", err)
+	}
 
-	var expensesRepo repositories.Expenses
+	var driver repositories.Driver
 	switch configManager.AppDBType() {
 	case models.BoltDBType:
-		expensesRepo, err = repositories.NewBoltDriver(configManager.BoltDBFileName())
+		boltDriver, err := repositories.NewBoltDriver(configManager.BoltDBFileName())
 		if err != nil {
 			return nil, err
 		}
+		if email := configManager.BoltDBLegacyOwnerEmail(); email != "" {
+			if err = boltDriver.MigrateLegacyExpenses(strings.ToLower(strings.TrimSpace(email))); err != nil {
+				return nil, fmt.Errorf("could not migrate legacy expenses: %v", err)
+			}
+		}
+		driver = boltDriver
 	case models.MariaDBType:
 		dbSettings := repositories.MariaDBSettings{
 			URL:                configManager.MariaDBUrl(),
@@ -50,16 +67,75 @@ func Init(configPath string) (*App, error) {
 			MaxIdleConnections: configManager.MariaDBMaxIdleConnections(),
 			ConnMaxLifetime:    configManager.MariaDBConnMaxLifetime(),
 		}
-		expensesRepo, err = repositories.NewMariaDBDriver(dbSettings)
+		driver, err = repositories.NewMariaDBDriver(dbSettings)
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	ratesSvc := services.Rates{
+		RatesRepo: driver,
+	}
+	if err = importRatesFile(ratesSvc, configManager.RatesFile()); err != nil {
+		return nil, fmt.Errorf("could not import rates file: %v", err)
+	}
+
+	blobs, err := repositories.NewFileSystemBlobs(configManager.AttachmentsDir())
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize attachments storage: %v", err)
+	}
+	attachmentsSvc := services.Attachments{
+		AttachmentsRepo: driver,
+		ExpensesRepo:    driver,
+		Blobs:           blobs,
+		MaxSize:         configManager.AttachmentsMaxSize(),
+	}
+
+	expensesSvc := services.Expenses{
+		ExpensesRepo:   driver,
+		CategoriesRepo: driver,
+		RatesRepo:      driver,
+		Attachments:    attachmentsSvc,
+		MaxBatchSize:   configManager.BatchMaxSize(),
+	}
+	budgetsSvc := services.Budgets{
+		BudgetsRepo: driver,
+		Expenses:    expensesSvc,
+	}
+	alertsSvc := &services.Alerts{
+		AlertsRepo: driver,
+		Budgets:    budgetsSvc,
+		Webhooks: services.Webhooks{
+			URLs:         configManager.WebhooksURLs(),
+			Secret:       configManager.WebhooksSecret(),
+			MaxAttempts:  configManager.WebhooksMaxAttempts(),
+			RetryBackoff: configManager.WebhooksRetryBackoff(),
+			Client:       &http.Client{Timeout: configManager.WebhooksTimeout()},
+		},
+	}
+	expensesSvc.BudgetAlerts = alertsSvc
+	recurringSvc := services.Recurring{
+		RecurringRepo: driver,
+		Expenses:      expensesSvc,
+	}
+
 	routerCfg := controllers.RouterConfig{
-		ExpensesSvc: services.Expenses{
-			ExpensesRepo: expensesRepo,
+		ExpensesSvc: expensesSvc,
+		CategoriesSvc: services.Categories{
+			CategoriesRepo: driver,
+		},
+		AuthSvc: services.Auth{
+			UsersRepo:   driver,
+			SessionTTL:  configManager.AuthSessionTTL(),
+			AdminEmails: configManager.AuthAdminEmails(),
 		},
+		RatesSvc:       ratesSvc,
+		BudgetsSvc:     budgetsSvc,
+		AlertsSvc:      alertsSvc,
+		RecurringSvc:   recurringSvc,
+		AttachmentsSvc: attachmentsSvc,
+		PublicRoutes:   configManager.AuthPublicRoutes(),
+		WriteTimeout:   configManager.AppWriteTimeout(),
 	}
 	app := &App{
 		Cfg: configManager,
@@ -70,13 +146,42 @@ func Init(configPath string) (*App, error) {
 			WriteTimeout: configManager.AppWriteTimeout(),
 			ErrorLog:     logging.HTTPServerLogger(),
 		},
-		dbCloser: expensesRepo,
+		dbCloser: driver,
+		alerts:   alertsSvc,
+		scheduler: &services.Scheduler{
+			Recurring: recurringSvc,
+			Interval:  configManager.SchedulerInterval(),
+		},
 	}
 	return app, nil
 }
 
-// Start starts the application
+// importRatesFile imports the days of an optional exchange rates file that have no rates stored yet,
+// so that restarts neither rewrite the file nor overwrite the rates saved by PUT /rates.
+// The format is picked by the file extension
+func importRatesFile(service services.Rates, filename string) error {
+	if filename == "" {
+		return nil
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), ".")
+	imported, err := service.ImportMissingRates(format, f)
+	if err != nil {
+		return err
+	}
+	logging.Logger.Info("imported missing exchange rates", zap.String("file", filename), zap.Int("days", imported))
+	return nil
+}
+
+// Start starts the application along with the budget alerts and the recurring expenses scheduler
 func (a *App) Start() error {
+	a.alerts.Start()
+	a.scheduler.Start()
 	logging.Logger.Info(
 		"http server is ready to handle requests",
 		zap.String("listen", a.Cfg.AppListen()),
@@ -90,7 +195,7 @@ func (a *App) Start() error {
 	return nil
 }
 
-// Stop shuts down the http server
+// Stop shuts down the http server, the recurring expenses scheduler and the budget alerts
 func (a *App) Stop() error {
 	var err error
 	a.stopOnce.Do(func() {
@@ -98,17 +203,25 @@ func (a *App) Stop() error {
 		defer cancel()
 
 		logging.Logger.Info("shutting down the http server")
-		if e := a.Server.Shutdown(ctx); err != nil {
+		// the server, the scheduler, the alerts and the db are all stopped whatever fails,
+		// the first error gets returned
+		if e := a.Server.Shutdown(ctx); e != nil {
 			logging.Logger.Error("error on server shutdown", zap.Error(e))
 			err = e
-			return
+		} else {
+			logging.Logger.Info("http server was shut down")
 		}
 
-		logging.Logger.Info("http server was shut down")
+		a.scheduler.Stop()
+		if e := a.alerts.Stop(ctx); e != nil {
+			logging.Logger.Error("budget alerts were cancelled on shutdown", zap.Error(e))
+		}
 
-		err = a.dbCloser.Close()
-		if err != nil {
-			logging.Logger.Error("could not stop db", zap.Error(err))
+		if e := a.dbCloser.Close(); e != nil {
+			logging.Logger.Error("could not stop db", zap.Error(e))
+			if err == nil {
+				err = e
+			}
 		}
 	})
 	return err
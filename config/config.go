@@ -18,10 +18,31 @@ const (
 	appShutdownTimeout = "app.shutdown_timeout"
 	appDBType          = "app.db_type"
 
+	authSessionTTL   = "auth.session_ttl"
+	authPublicRoutes = "auth.public_routes"
+	authAdminEmails  = "auth.admin_emails"
+
+	currencies = "currencies"
+	ratesFile  = "rates.file"
+
+	webhooksURLs         = "webhooks.urls"
+	webhooksSecret       = "webhooks.secret"
+	webhooksTimeout      = "webhooks.timeout"
+	webhooksMaxAttempts  = "webhooks.max_attempts"
+	webhooksRetryBackoff = "webhooks.retry_backoff"
+
+	schedulerInterval = "scheduler.interval"
+
+	attachmentsDir     = "attachments.dir"
+	attachmentsMaxSize = "attachments.max_size"
+
+	batchMaxSize = "batch.max_size"
+
 	loggingLevel  = "logging.level"
 	loggingOutput = "logging.output"
 
-	boltDBFileName = "boltdb.filename"
+	boltDBFileName         = "boltdb.filename"
+	boltDBLegacyOwnerEmail = "boltdb.legacy_owner_email"
 
 	mariaDBURL                = "mariadb.url"
 	mariaDBMaxOpenConnections = "mariadb.max_open_connections"
@@ -83,6 +104,83 @@ func (m *Manager) AppDBType() string {
 	return m.CfgReader.GetString(appDBType)
 }
 
+// AuthSessionTTL retrieves the lifetime of user sessions created on login
+func (m *Manager) AuthSessionTTL() time.Duration {
+	return m.CfgReader.GetDuration(authSessionTTL)
+}
+
+// AuthPublicRoutes retrieves the list of routes that do not require authentication
+func (m *Manager) AuthPublicRoutes() []string {
+	return m.CfgReader.GetStringSlice(authPublicRoutes)
+}
+
+// AuthAdminEmails retrieves the list of emails that get the admin role on signup
+func (m *Manager) AuthAdminEmails() []string {
+	return m.CfgReader.GetStringSlice(authAdminEmails)
+}
+
+// Currencies retrieves the catalogue of supported ISO 4217 currencies, falling back to the default catalogue
+func (m *Manager) Currencies() ([]models.Currency, error) {
+	if !m.CfgReader.IsSet(currencies) {
+		return models.DefaultCurrencies, nil
+	}
+	var list []models.Currency
+	if err := m.CfgReader.UnmarshalKey(currencies, &list); err != nil {
+		return nil, fmt.Errorf("could not read %s: %v", currencies, err)
+	}
+	return list, nil
+}
+
+// RatesFile retrieves the optional ECB style CSV or XML exchange rates file imported on startup
+func (m *Manager) RatesFile() string {
+	return m.CfgReader.GetString(ratesFile)
+}
+
+// WebhooksURLs retrieves the list of URLs budget alerts get delivered to
+func (m *Manager) WebhooksURLs() []string {
+	return m.CfgReader.GetStringSlice(webhooksURLs)
+}
+
+// WebhooksSecret retrieves the secret webhook request bodies get signed with
+func (m *Manager) WebhooksSecret() string {
+	return m.CfgReader.GetString(webhooksSecret)
+}
+
+// WebhooksTimeout retrieves the timeout of a single webhook request
+func (m *Manager) WebhooksTimeout() time.Duration {
+	return m.CfgReader.GetDuration(webhooksTimeout)
+}
+
+// WebhooksMaxAttempts retrieves the max amount of delivery attempts per webhook URL
+func (m *Manager) WebhooksMaxAttempts() int {
+	return m.CfgReader.GetInt(webhooksMaxAttempts)
+}
+
+// WebhooksRetryBackoff retrieves the delay before the first webhook retry, it doubles after each retry
+func (m *Manager) WebhooksRetryBackoff() time.Duration {
+	return m.CfgReader.GetDuration(webhooksRetryBackoff)
+}
+
+// SchedulerInterval retrieves how often the scheduler materializes due recurring expenses
+func (m *Manager) SchedulerInterval() time.Duration {
+	return m.CfgReader.GetDuration(schedulerInterval)
+}
+
+// AttachmentsDir retrieves the directory the content of expense attachments is stored in
+func (m *Manager) AttachmentsDir() string {
+	return m.CfgReader.GetString(attachmentsDir)
+}
+
+// AttachmentsMaxSize retrieves the max size of a single expense attachment in bytes
+func (m *Manager) AttachmentsMaxSize() int64 {
+	return int64(m.CfgReader.GetSizeInBytes(attachmentsMaxSize))
+}
+
+// BatchMaxSize retrieves the max amount of operations of a single expenses batch and of expenses deleted at once
+func (m *Manager) BatchMaxSize() int {
+	return m.CfgReader.GetInt(batchMaxSize)
+}
+
 // LoggingLevel retrieves the application logging level from configuration file
 func (m *Manager) LoggingLevel() string {
 	return m.CfgReader.GetString(loggingLevel)
@@ -118,6 +216,11 @@ func (m *Manager) BoltDBFileName() string {
 	return m.CfgReader.GetString(boltDBFileName)
 }
 
+// BoltDBLegacyOwnerEmail retrieves the email of the user that owns the expenses stored before expenses had owners
+func (m *Manager) BoltDBLegacyOwnerEmail() string {
+	return m.CfgReader.GetString(boltDBLegacyOwnerEmail)
+}
+
 // setDefaults sets application default configs
 func (m *Manager) setDefaults() {
 	m.CfgReader.SetDefault(appListen, "0.0.0.0:8080")
@@ -125,6 +228,15 @@ func (m *Manager) setDefaults() {
 	m.CfgReader.SetDefault(appWriteTimeout, 10*time.Second)
 	m.CfgReader.SetDefault(appShutdownTimeout, 15*time.Second)
 	m.CfgReader.SetDefault(appDBType, models.BoltDBType)
+	m.CfgReader.SetDefault(authSessionTTL, 24*time.Hour)
+	m.CfgReader.SetDefault(authPublicRoutes, []string{"/metrics", "/health", "/signup", "/login"})
+	m.CfgReader.SetDefault(webhooksTimeout, 5*time.Second)
+	m.CfgReader.SetDefault(webhooksMaxAttempts, 5)
+	m.CfgReader.SetDefault(webhooksRetryBackoff, time.Second)
+	m.CfgReader.SetDefault(schedulerInterval, time.Minute)
+	m.CfgReader.SetDefault(attachmentsDir, "attachments")
+	m.CfgReader.SetDefault(attachmentsMaxSize, "10MB")
+	m.CfgReader.SetDefault(batchMaxSize, 100)
 	m.CfgReader.SetDefault(loggingLevel, zap.InfoLevel.String())
 	m.CfgReader.SetDefault(loggingOutput, []string{"app.log"})
 	m.CfgReader.SetDefault(mariaDBMaxOpenConnections, 100)
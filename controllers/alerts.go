@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
+)
+
+type alertsGetter interface {
+	GetAlerts(userID, budgetID string) ([]models.BudgetAlert, error)
+}
+
+type getAlertsResponse struct {
+	Items []models.BudgetAlert `json:"items"`
+}
+
+func getAlerts(service alertsGetter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		alerts, err := service.GetAlerts(callerID(r), id)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		transport.SendJSON(w, http.StatusOK, getAlertsResponse{Items: alerts})
+	})
+}
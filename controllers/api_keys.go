@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
+)
+
+type apiKeyCreator interface {
+	CreateAPIKey(models.CreateAPIKeyRequest) (models.APIKey, string, error)
+}
+
+type apiKeysGetter interface {
+	GetAPIKeys(userID string) ([]models.APIKey, error)
+}
+
+type apiKeyDeleter interface {
+	DeleteAPIKey(userID, id string) error
+}
+
+type createAPIKeyResponse struct {
+	models.APIKey
+	Key string `json:"key"`
+}
+
+type getAPIKeysResponse struct {
+	Items []models.APIKey `json:"items"`
+}
+
+func createAPIKey(service apiKeyCreator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.CreateAPIKeyRequest
+		err := parseBody(r, &req)
+		if err != nil {
+			logging.Logger.Error("could not unmarshal create api key body", zap.Error(err))
+			transport.SendHTTPError(w, err)
+			return
+		}
+		if err = req.Validate(); err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		req.UserID = callerID(r)
+		apiKey, key, err := service.CreateAPIKey(req)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		logging.Logger.Info("successfully created api key")
+		res := createAPIKeyResponse{
+			APIKey: apiKey,
+			Key:    key,
+		}
+		transport.SendJSON(w, http.StatusCreated, res)
+	})
+}
+
+func getAPIKeys(service apiKeysGetter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys, err := service.GetAPIKeys(callerID(r))
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		transport.SendJSON(w, http.StatusOK, getAPIKeysResponse{Items: keys})
+	})
+}
+
+func deleteAPIKey(service apiKeyDeleter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		err = service.DeleteAPIKey(callerID(r), id)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		logging.Logger.Info("successfully deleted api key")
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
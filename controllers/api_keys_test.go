@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// fakeAuth represents an authentication service that resolves every API key to a member with a given key scope
+type fakeAuth struct {
+	AuthenticationService
+	scope   string
+	managed bool
+}
+
+func (a *fakeAuth) AuthenticateAPIKey(string) (models.User, string, error) {
+	return models.User{ID: "cc2c8d11-825a-4e75-aa14-cca8610723d6", Role: models.MemberRole}, a.scope, nil
+}
+
+func (a *fakeAuth) CreateAPIKey(req models.CreateAPIKeyRequest) (models.APIKey, string, error) {
+	a.managed = true
+	return models.APIKey{UserID: req.UserID, Scope: req.Scope}, "exp_key", nil
+}
+
+func (a *fakeAuth) GetAPIKeys(string) ([]models.APIKey, error) {
+	a.managed = true
+	return []models.APIKey{}, nil
+}
+
+func (a *fakeAuth) DeleteAPIKey(string, string) error {
+	a.managed = true
+	return nil
+}
+
+func TestAPIKeyManagementRequiresWriteScope(t *testing.T) {
+	tests := []struct {
+		name       string
+		scope      string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "read only key can not create keys",
+			scope:      models.ReadOnlyScope,
+			method:     http.MethodPost,
+			path:       "/api-keys",
+			body:       `{"name":"escalated","scope":"read_write"}`,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "read only key can not revoke keys",
+			scope:      models.ReadOnlyScope,
+			method:     http.MethodDelete,
+			path:       "/api-keys/" + testExpenseID,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "read only key can not list keys",
+			scope:      models.ReadOnlyScope,
+			method:     http.MethodGet,
+			path:       "/api-keys",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "read write key lists keys",
+			scope:      models.ReadWriteScope,
+			method:     http.MethodGet,
+			path:       "/api-keys",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "read write key creates keys",
+			scope:      models.ReadWriteScope,
+			method:     http.MethodPost,
+			path:       "/api-keys",
+			body:       `{"name":"ci","scope":"read_write"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "read write key revokes keys",
+			scope:      models.ReadWriteScope,
+			method:     http.MethodDelete,
+			path:       "/api-keys/" + testExpenseID,
+			wantStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			auth := &fakeAuth{scope: test.scope}
+			router := NewRouter(RouterConfig{AuthSvc: auth})
+
+			r := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			r.Header.Set(models.ContentType, models.ApplicationJSONType)
+			r.Header.Set(models.APIKeyHeader, "exp_key")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, r)
+
+			if rec.Code != test.wantStatus {
+				t.Fatalf("expected status: %d, got: %d, body: %s", test.wantStatus, rec.Code, rec.Body.String())
+			}
+			if wantManaged := test.wantStatus != http.StatusForbidden; auth.managed != wantManaged {
+				t.Fatalf("expected keys to be managed: %v, got: %v", wantManaged, auth.managed)
+			}
+		})
+	}
+}
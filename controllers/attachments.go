@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
+)
+
+const (
+	attachmentIDRouteParam = "attachmentId"
+	attachmentFormField    = "file"
+)
+
+type attachmentsGetter interface {
+	GetAttachments(userID, expenseID string) ([]models.Attachment, error)
+}
+
+type attachmentGetter interface {
+	GetAttachment(userID, expenseID, id string) (models.Attachment, io.ReadCloser, error)
+}
+
+type attachmentCreator interface {
+	CreateAttachment(models.CreateAttachmentRequest) (models.Attachment, error)
+}
+
+type attachmentDeleter interface {
+	DeleteAttachment(userID, expenseID, id string) error
+}
+
+type getAttachmentsResponse struct {
+	Items []models.Attachment `json:"items"`
+}
+
+func getAttachments(service attachmentsGetter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expenseID, err := parseUUIDParam(r, idsRouteParam)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		attachments, err := service.GetAttachments(callerID(r), expenseID)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		transport.SendJSON(w, http.StatusOK, getAttachmentsResponse{Items: attachments})
+	})
+}
+
+func getAttachment(service attachmentGetter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expenseID, err := parseUUIDParam(r, idsRouteParam)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		id, err := parseUUIDParam(r, attachmentIDRouteParam)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		attachment, content, err := service.GetAttachment(callerID(r), expenseID, id)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		defer content.Close()
+
+		// only images are displayed in place, other attachments get downloaded,
+		// and browsers must stick to the content type detected on upload
+		dispositionType := "attachment"
+		if strings.HasPrefix(attachment.ContentType, "image/") {
+			dispositionType = "inline"
+		}
+		disposition := mime.FormatMediaType(dispositionType, map[string]string{"filename": attachment.Filename})
+		w.Header().Set(models.ContentType, attachment.ContentType)
+		w.Header().Set(models.ContentTypeOptionsHeader, models.NoSniffContentTypeOption)
+		w.Header().Set(models.ContentLengthHeader, strconv.FormatInt(attachment.Size, 10))
+		w.Header().Set(models.ContentDispositionHeader, disposition)
+		w.Header().Set(models.ETagHeader, `"`+attachment.SHA256+`"`)
+		w.WriteHeader(http.StatusOK)
+		if _, err = io.Copy(w, content); err != nil {
+			logging.Logger.Error("could not stream attachment", zap.String("id", id), zap.Error(err))
+		}
+	})
+}
+
+func createAttachment(service attachmentCreator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expenseID, err := parseIDParam(r)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		reader, err := r.MultipartReader()
+		if err != nil {
+			transport.SendHTTPError(w, models.FormatValidationError{Message: err.Error()})
+			return
+		}
+
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				logging.Logger.Error("could not read multipart body", zap.Error(err))
+				transport.SendHTTPError(w, models.FormatValidationError{Message: err.Error()})
+				return
+			}
+			if part.FormName() != attachmentFormField {
+				continue
+			}
+
+			req := models.CreateAttachmentRequest{
+				UserID:    callerID(r),
+				ExpenseID: expenseID,
+				Filename:  part.FileName(),
+				Content:   part,
+			}
+			attachment, err := service.CreateAttachment(req)
+			if err != nil {
+				transport.SendHTTPError(w, err)
+				return
+			}
+			logging.Logger.Info("successfully created attachment")
+			w.Header().Set(models.LocationHeader, "/expenses/"+expenseID+"/attachments/"+attachment.ID)
+			transport.SendJSON(w, http.StatusCreated, attachment)
+			return
+		}
+
+		err = models.DataValidationError{
+			Message: "missing multipart file field: " + attachmentFormField,
+		}
+		transport.SendHTTPError(w, err)
+	})
+}
+
+func deleteAttachment(service attachmentDeleter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expenseID, err := parseUUIDParam(r, idsRouteParam)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		id, err := parseUUIDParam(r, attachmentIDRouteParam)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		err = service.DeleteAttachment(callerID(r), expenseID, id)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		logging.Logger.Info("successfully deleted attachment")
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/repositories"
+	"github.com/steevehook/expenses-rest-api/services"
+)
+
+const testPNG = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+// attachmentsTestRouter represents a router whose attachments are kept in a temporary BoltDB file and blobs directory,
+// requests are authenticated as the member user of fakeAuth
+type attachmentsTestRouter struct {
+	http.Handler
+	service services.Attachments
+	// expenseID represents an expense of the caller
+	expenseID string
+	// foreignExpenseID represents an expense of another user
+	foreignExpenseID string
+}
+
+func newAttachmentsTestRouter(t *testing.T) attachmentsTestRouter {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "expenses-attachments")
+	if err != nil {
+		t.Fatalf("could not create temporary dir: %v", err)
+	}
+	db, err := repositories.NewBoltDriver(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatalf("could not open bolt driver: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = db.Close()
+		_ = os.RemoveAll(dir)
+	})
+	blobs, err := repositories.NewFileSystemBlobs(filepath.Join(dir, "blobs"))
+	if err != nil {
+		t.Fatalf("could not create blobs: %v", err)
+	}
+
+	auth := &fakeAuth{scope: models.ReadWriteScope}
+	caller, _, _ := auth.AuthenticateAPIKey("")
+	price := models.Money{Amount: 125000, Currency: "USD"}
+	expense, err := db.CreateExpense(models.CreateExpenseRequest{UserID: caller.ID, Title: "lunch", Price: price})
+	if err != nil {
+		t.Fatalf("could not create expense: %v", err)
+	}
+	foreign, err := db.CreateExpense(models.CreateExpenseRequest{UserID: "alice", Title: "lunch", Price: price})
+	if err != nil {
+		t.Fatalf("could not create expense: %v", err)
+	}
+
+	service := services.Attachments{AttachmentsRepo: db, ExpensesRepo: db, Blobs: blobs, MaxSize: 1 << 20}
+	return attachmentsTestRouter{
+		Handler:          NewRouter(RouterConfig{AuthSvc: auth, AttachmentsSvc: service}),
+		service:          service,
+		expenseID:        expense.ID,
+		foreignExpenseID: foreign.ID,
+	}
+}
+
+func (r attachmentsTestRouter) serve(t *testing.T, method, path, filename, content string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, contentType := &bytes.Buffer{}, models.ApplicationJSONType
+	if method == http.MethodPost {
+		form := multipart.NewWriter(body)
+		part, err := form.CreateFormFile(attachmentFormField, filename)
+		if err != nil {
+			t.Fatalf("could not create multipart body: %v", err)
+		}
+		_, _ = part.Write([]byte(content))
+		_ = form.Close()
+		contentType = form.FormDataContentType()
+	}
+
+	req := httptest.NewRequest(method, path, body)
+	req.Header.Set(models.ContentType, contentType)
+	req.Header.Set(models.APIKeyHeader, "exp_key")
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAttachmentUploadAndDownload(t *testing.T) {
+	tests := []struct {
+		name            string
+		filename        string
+		content         string
+		wantContentType string
+		wantDisposition string
+	}{
+		{
+			name:            "image named as a pdf",
+			filename:        "receipt.pdf",
+			content:         testPNG,
+			wantContentType: "image/png",
+			wantDisposition: `inline; filename=receipt.pdf`,
+		},
+		{
+			name:            "pdf",
+			filename:        "receipt.pdf",
+			content:         "%PDF-1.4\n%\xe2\xe3\xcf\xd3\n",
+			wantContentType: "application/pdf",
+			wantDisposition: `attachment; filename=receipt.pdf`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := newAttachmentsTestRouter(t)
+			path := "/expenses/" + router.expenseID + "/attachments"
+			rec := router.serve(t, http.MethodPost, path, tt.filename, tt.content)
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("expected status: %d, got: %d, body: %s", http.StatusCreated, rec.Code, rec.Body.String())
+			}
+			var attachment models.Attachment
+			if err := json.Unmarshal(rec.Body.Bytes(), &attachment); err != nil {
+				t.Fatalf("could not decode attachment: %v", err)
+			}
+			if attachment.ContentType != tt.wantContentType {
+				t.Fatalf("expected content type: %s, got: %s", tt.wantContentType, attachment.ContentType)
+			}
+
+			rec = router.serve(t, http.MethodGet, path+"/"+attachment.ID, "", "")
+			if rec.Code != http.StatusOK || rec.Body.String() != tt.content {
+				t.Fatalf("expected the uploaded content, got status: %d, body: %q", rec.Code, rec.Body.String())
+			}
+			headers := map[string]string{
+				models.ContentType:              tt.wantContentType,
+				models.ContentTypeOptionsHeader: models.NoSniffContentTypeOption,
+				models.ContentDispositionHeader: tt.wantDisposition,
+			}
+			for header, want := range headers {
+				if got := rec.Header().Get(header); got != want {
+					t.Errorf("expected %s: %s, got: %s", header, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestAttachmentUploadRejectsUnsupportedContentTypes(t *testing.T) {
+	router := newAttachmentsTestRouter(t)
+	path := "/expenses/" + router.expenseID + "/attachments"
+	rec := router.serve(t, http.MethodPost, path, "receipt.png", "<html><script>alert(1)</script></html>")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status: %d, got: %d, body: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "unsupported attachment content type: text/html") {
+		t.Fatalf("expected the content type to be rejected, got: %s", rec.Body.String())
+	}
+
+	rec = router.serve(t, http.MethodGet, path, "", "")
+	if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != `{"items":[]}` {
+		t.Fatalf("expected no attachments, got status: %d, body: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAttachmentsOfAnotherUserAreNotFound(t *testing.T) {
+	router := newAttachmentsTestRouter(t)
+	attachment, err := router.service.CreateAttachment(models.CreateAttachmentRequest{
+		UserID:    "alice",
+		ExpenseID: router.foreignExpenseID,
+		Content:   strings.NewReader(testPNG),
+	})
+	if err != nil {
+		t.Fatalf("could not create attachment: %v", err)
+	}
+
+	path := "/expenses/" + router.foreignExpenseID + "/attachments"
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{method: http.MethodGet, path: path},
+		{method: http.MethodGet, path: path + "/" + attachment.ID},
+		{method: http.MethodPost, path: path},
+		{method: http.MethodDelete, path: path + "/" + attachment.ID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec := router.serve(t, tt.method, tt.path, "receipt.png", testPNG)
+			if rec.Code != http.StatusNotFound {
+				t.Fatalf("expected status: %d, got: %d, body: %s", http.StatusNotFound, rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	_, content, err := router.service.GetAttachment("alice", router.foreignExpenseID, attachment.ID)
+	if err != nil {
+		t.Fatalf("expected the attachment to be kept, got: %v", err)
+	}
+	_ = content.Close()
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
+)
+
+const batchPathSegment = "batch"
+
+type expensesBatcher interface {
+	BatchExpenses(models.BatchExpensesRequest) (models.BatchReport, error)
+}
+
+type batchResponse struct {
+	BestEffort bool                  `json:"best_effort"`
+	Committed  bool                  `json:"committed"`
+	Results    []batchResultResponse `json:"results"`
+}
+
+// batchResultResponse represents the outcome of a batch operation, with the status code of its standalone endpoint.
+// Operations of batches that were rolled back have the failed dependency status code
+type batchResultResponse struct {
+	Index   int               `json:"index"`
+	Op      string            `json:"op"`
+	ID      string            `json:"id,omitempty"`
+	Status  int               `json:"status"`
+	Expense *models.Expense   `json:"expense,omitempty"`
+	Error   *models.HTTPError `json:"error,omitempty"`
+}
+
+// batchExpenses applies a batch of create, update and delete operations in a single transaction.
+// Batches that were rolled back respond with the conflict status code, along with the results of every operation
+func batchExpenses(service expensesBatcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.BatchExpensesRequest
+		err := parseBody(r, &req)
+		if err != nil {
+			logging.Logger.Error("could not unmarshal batch expenses body", zap.Error(err))
+			transport.SendHTTPError(w, err)
+			return
+		}
+		if err = req.Validate(); err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		req.UserID = callerID(r)
+		report, err := service.BatchExpenses(req)
+		if err != nil {
+			logging.Logger.Debug("could not apply expenses batch", zap.Error(err))
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		res := batchResponse{
+			BestEffort: report.BestEffort,
+			Committed:  report.Committed,
+			Results:    make([]batchResultResponse, 0, len(report.Results)),
+		}
+		for i, result := range report.Results {
+			item := batchResultResponse{Index: i, Op: result.Op, ID: result.ID, Expense: result.Expense}
+			switch {
+			case result.Err != nil:
+				httpErr := transport.ToHTTPError(result.Err)
+				item.Status, item.Error = httpErr.Code, &httpErr
+			case !report.Committed:
+				item.Status = http.StatusFailedDependency
+			case result.Op == models.CreateBatchOperation:
+				item.Status = http.StatusCreated
+			case result.Op == models.DeleteBatchOperation:
+				item.Status = http.StatusNoContent
+			default:
+				item.Status = http.StatusOK
+			}
+			res.Results = append(res.Results, item)
+		}
+		logging.Logger.Info(
+			"successfully applied expenses batch",
+			zap.Int("operations", len(res.Results)),
+			zap.Bool("committed", res.Committed),
+		)
+		status := http.StatusOK
+		if !res.Committed {
+			status = http.StatusConflict
+		}
+		transport.SendJSON(w, status, res)
+	})
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// fakeBatcher represents an expenses batcher that fails the operations of missing expenses
+type fakeBatcher struct{}
+
+func (fakeBatcher) BatchExpenses(req models.BatchExpensesRequest) (models.BatchReport, error) {
+	report := models.BatchReport{BestEffort: req.BestEffort, Committed: true}
+	for _, op := range req.Operations {
+		result := models.BatchResult{Op: op.Op, ID: op.ID}
+		if op.ID == "missing" {
+			result.Err = models.ResourceNotFoundError{Message: "could not find expense with id: missing"}
+			report.Committed = report.Committed && req.BestEffort
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+func TestBatchExpensesStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		wantStatus   int
+		wantStatuses []int
+	}{
+		{
+			name:         "committed",
+			body:         `{"operations":[{"op":"delete","id":"e1"},{"op":"delete","id":"e2"}]}`,
+			wantStatus:   http.StatusOK,
+			wantStatuses: []int{http.StatusNoContent, http.StatusNoContent},
+		},
+		{
+			name:         "best effort with failures",
+			body:         `{"best_effort":true,"operations":[{"op":"delete","id":"e1"},{"op":"delete","id":"missing"}]}`,
+			wantStatus:   http.StatusOK,
+			wantStatuses: []int{http.StatusNoContent, http.StatusNotFound},
+		},
+		{
+			name:         "rolled back",
+			body:         `{"operations":[{"op":"delete","id":"e1"},{"op":"delete","id":"missing"}]}`,
+			wantStatus:   http.StatusConflict,
+			wantStatuses: []int{http.StatusFailedDependency, http.StatusNotFound},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rec := serve(batchExpenses(fakeBatcher{}), http.MethodPost, "/expenses", test.body)
+			if rec.Code != test.wantStatus {
+				t.Fatalf("expected status: %d, got: %d, body: %s", test.wantStatus, rec.Code, rec.Body.String())
+			}
+			var res batchResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+				t.Fatalf("could not decode response: %v", err)
+			}
+			if res.Committed != (test.wantStatus == http.StatusOK) {
+				t.Fatalf("unexpected committed: %v", res.Committed)
+			}
+			for i, result := range res.Results {
+				if result.Status != test.wantStatuses[i] {
+					t.Fatalf("expected operation %d status: %d, got: %d", i, test.wantStatuses[i], result.Status)
+				}
+			}
+		})
+	}
+}
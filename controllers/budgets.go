@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
+)
+
+const atQueryParam = "at"
+
+type budgetsGetter interface {
+	GetBudgets(userID string) ([]models.Budget, error)
+}
+
+type budgetGetter interface {
+	GetBudget(userID, id string) (models.Budget, error)
+}
+
+type budgetCreator interface {
+	CreateBudget(models.CreateBudgetRequest) (models.Budget, error)
+}
+
+type budgetUpdater interface {
+	UpdateBudget(models.UpdateBudgetRequest) (models.Budget, error)
+}
+
+type budgetDeleter interface {
+	DeleteBudget(userID, id string) error
+}
+
+type budgetStatusGetter interface {
+	GetBudgetStatus(models.BudgetStatusRequest) (models.BudgetStatus, error)
+}
+
+type getBudgetsResponse struct {
+	Items []models.Budget `json:"items"`
+}
+
+func getBudgets(service budgetsGetter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		budgets, err := service.GetBudgets(callerID(r))
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		transport.SendJSON(w, http.StatusOK, getBudgetsResponse{Items: budgets})
+	})
+}
+
+func getBudget(service budgetGetter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		budget, err := service.GetBudget(callerID(r), id)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		transport.SendJSON(w, http.StatusOK, budget)
+	})
+}
+
+func createBudget(service budgetCreator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.CreateBudgetRequest
+		err := parseBody(r, &req)
+		if err != nil {
+			logging.Logger.Error("could not unmarshal create budget body", zap.Error(err))
+			transport.SendHTTPError(w, err)
+			return
+		}
+		if err = req.Validate(); err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		req.UserID = callerID(r)
+		budget, err := service.CreateBudget(req)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		logging.Logger.Info("successfully created budget")
+		w.Header().Set(models.LocationHeader, "/budgets/"+budget.ID)
+		transport.SendJSON(w, http.StatusCreated, budget)
+	})
+}
+
+func updateBudget(service budgetUpdater) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.UpdateBudgetRequest
+		err := parseBody(r, &req)
+		if err != nil {
+			logging.Logger.Error("could not unmarshal update budget body", zap.Error(err))
+			transport.SendHTTPError(w, err)
+			return
+		}
+		if err = req.Validate(); err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		id, err := parseIDParam(r)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		req.ID = id
+		req.UserID = callerID(r)
+
+		budget, err := service.UpdateBudget(req)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		logging.Logger.Info("successfully updated budget")
+		transport.SendJSON(w, http.StatusOK, budget)
+	})
+}
+
+func deleteBudget(service budgetDeleter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		err = service.DeleteBudget(callerID(r), id)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		logging.Logger.Info("successfully deleted budget")
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func getBudgetStatus(service budgetStatusGetter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		at, err := parseTimeQueryParam(r, atQueryParam, false)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		req := models.BudgetStatusRequest{
+			ID:     id,
+			UserID: callerID(r),
+			At:     at,
+		}
+		status, err := service.GetBudgetStatus(req)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		transport.SendJSON(w, http.StatusOK, status)
+	})
+}
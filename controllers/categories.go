@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
+)
+
+const reassignQueryParam = "reassign"
+
+type categoriesGetter interface {
+	GetCategories(userID string) ([]models.Category, error)
+}
+
+type categoryGetter interface {
+	GetCategory(userID, id string) (models.Category, error)
+}
+
+type categoryCreator interface {
+	CreateCategory(models.CategoryRequest) (models.Category, error)
+}
+
+type categoryUpdater interface {
+	UpdateCategory(models.CategoryRequest) (models.Category, error)
+}
+
+type categoryDeleter interface {
+	DeleteCategory(models.DeleteCategoryRequest) error
+}
+
+type getCategoriesResponse struct {
+	Items []models.Category `json:"items"`
+}
+
+func getCategories(service categoriesGetter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		categories, err := service.GetCategories(callerID(r))
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		transport.SendJSON(w, http.StatusOK, getCategoriesResponse{Items: categories})
+	})
+}
+
+func getCategory(service categoryGetter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		category, err := service.GetCategory(callerID(r), id)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		transport.SendJSON(w, http.StatusOK, category)
+	})
+}
+
+func createCategory(service categoryCreator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.CategoryRequest
+		err := parseBody(r, &req)
+		if err != nil {
+			logging.Logger.Error("could not unmarshal create category body", zap.Error(err))
+			transport.SendHTTPError(w, err)
+			return
+		}
+		if err = req.Validate(); err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		req.UserID = callerID(r)
+		category, err := service.CreateCategory(req)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		logging.Logger.Info("successfully created category")
+		transport.SendJSON(w, http.StatusCreated, category)
+	})
+}
+
+func updateCategory(service categoryUpdater) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.CategoryRequest
+		err := parseBody(r, &req)
+		if err != nil {
+			logging.Logger.Error("could not unmarshal update category body", zap.Error(err))
+			transport.SendHTTPError(w, err)
+			return
+		}
+		if err = req.Validate(); err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		id, err := parseIDParam(r)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		req.ID = id
+		req.UserID = callerID(r)
+
+		category, err := service.UpdateCategory(req)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		logging.Logger.Info("successfully updated category")
+		transport.SendJSON(w, http.StatusOK, category)
+	})
+}
+
+func deleteCategory(service categoryDeleter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		reassign, err := parseBoolQueryParam(r, reassignQueryParam)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		req := models.DeleteCategoryRequest{
+			ID:       id,
+			UserID:   callerID(r),
+			Reassign: reassign,
+		}
+		err = service.DeleteCategory(req)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		logging.Logger.Info("successfully deleted category")
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func parseBoolQueryParam(r *http.Request, paramName string) (bool, error) {
+	param := r.URL.Query().Get(paramName)
+	if param == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(param)
+	if err != nil {
+		e := models.DataValidationError{
+			Message: fmt.Sprintf("invalid value: %s for param: %s", param, paramName),
+		}
+		return false, e
+	}
+	return value, nil
+}
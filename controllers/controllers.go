@@ -9,9 +9,11 @@ import (
 	"strings"
 
 	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
 
 	"github.com/steevehook/expenses-rest-api/logging"
 	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
 )
 
 // routeParam fetches params from context and converts it into julienschmidt/httprouter.Params struct
@@ -27,6 +29,33 @@ func routeParam(r *http.Request, name string) string {
 	return ps.ByName(name)
 }
 
+// callerID fetches the ID of the authenticated user that performs the request
+func callerID(r *http.Request) string {
+	user, _ := models.UserFromContext(r.Context())
+	return user.ID
+}
+
+// callerCan checks whether the authenticated user and the API key used for the request grant a given permission
+func callerCan(r *http.Request, permission models.Permission) bool {
+	user, ok := models.UserFromContext(r.Context())
+	return ok && user.HasPermission(permission) && models.ScopeAllows(models.ScopeFromContext(r.Context()), permission)
+}
+
+// authorize rejects requests of callers that lack a given permission
+func authorize(permission models.Permission, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !callerCan(r, permission) {
+			err := models.ForbiddenError{
+				Message: fmt.Sprintf("missing permission: %s", permission),
+			}
+			logging.Logger.Debug("caller lacks permission", zap.String("permission", string(permission)))
+			transport.SendHTTPError(w, err)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
 // parseBody parses JSON request body
 func parseBody(r *http.Request, v interface{}) error {
 	bs, err := ioutil.ReadAll(r.Body)
@@ -38,8 +67,10 @@ func parseBody(r *http.Request, v interface{}) error {
 	}
 
 	err = json.Unmarshal(bs, v)
-	switch err.(type) {
-	case *json.UnsupportedTypeError, *json.UnsupportedValueError:
+	if e, ok := err.(models.DataValidationError); ok {
+		return e
+	}
+	if err != nil {
 		return models.InvalidJSONError{
 			Message: err.Error(),
 		}
@@ -49,7 +80,12 @@ func parseBody(r *http.Request, v interface{}) error {
 
 // parseIDParam parses id route param and validates it
 func parseIDParam(r *http.Request) (string, error) {
-	id := routeParam(r, idRouteParam)
+	return parseUUIDParam(r, idRouteParam)
+}
+
+// parseUUIDParam parses a given route param and validates it
+func parseUUIDParam(r *http.Request, name string) (string, error) {
+	id := routeParam(r, name)
 	_, err := uuid.Parse(id)
 	if err != nil {
 		e := models.FormatValidationError{
@@ -76,14 +112,14 @@ func parseIDsParam(r *http.Request) ([]string, error) {
 	return dedupe(ids), nil
 }
 
-// dedupe parses a list of strings and removes duplicates
+// dedupe parses a list of strings and removes duplicates, keeping the order of the first occurrences
 func dedupe(values []string) []string {
-	unique, res := map[string]string{}, make([]string, 0)
+	unique, res := map[string]bool{}, make([]string, 0)
 	for _, v := range values {
-		unique[v] = v
-	}
-	for _, v := range unique {
-		res = append(res, v)
+		if !unique[v] {
+			unique[v] = true
+			res = append(res, v)
+		}
 	}
 	return res
 }
@@ -11,7 +11,7 @@ import (
 )
 
 type expenseCreator interface {
-	CreateExpense(models.CreateExpenseRequest) error
+	CreateExpense(models.CreateExpenseRequest) (models.Expense, error)
 }
 
 func createExpense(service expenseCreator) http.Handler {
@@ -28,7 +28,8 @@ func createExpense(service expenseCreator) http.Handler {
 			return
 		}
 
-		err = service.CreateExpense(req)
+		req.UserID = callerID(r)
+		expense, err := service.CreateExpense(req)
 		if err != nil {
 			logging.Logger.Debug("could not create expense", zap.Error(err))
 			transport.SendHTTPError(w, err)
@@ -36,6 +37,7 @@ func createExpense(service expenseCreator) http.Handler {
 		}
 
 		logging.Logger.Info("successfully created expense")
-		w.WriteHeader(http.StatusCreated)
+		w.Header().Set(models.LocationHeader, "/expenses/"+expense.ID)
+		transport.SendJSON(w, http.StatusCreated, expense)
 	})
 }
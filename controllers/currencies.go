@@ -0,0 +1,18 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
+)
+
+type getCurrenciesResponse struct {
+	Items []models.Currency `json:"items"`
+}
+
+func getCurrencies() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		transport.SendJSON(w, http.StatusOK, getCurrenciesResponse{Items: models.Currencies()})
+	})
+}
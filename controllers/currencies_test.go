@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+func TestGetCurrencies(t *testing.T) {
+	defer func() {
+		_ = models.SetCurrencies(models.DefaultCurrencies)
+	}()
+	catalogue := []models.Currency{{Code: "USD", Symbol: "$", MinorUnits: 2}, {Code: "JPY", Symbol: "¥"}}
+	if err := models.SetCurrencies(catalogue); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/currencies", nil)
+	r.Header.Set(models.APIKeyHeader, "exp_key")
+	rec := httptest.NewRecorder()
+	NewRouter(RouterConfig{AuthSvc: &fakeAuth{scope: models.ReadOnlyScope}}).ServeHTTP(rec, r)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status: %d, got: %d", http.StatusOK, rec.Code)
+	}
+	var res getCurrenciesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("could not decode response: %v", err)
+	}
+	if want := []models.Currency{catalogue[1], catalogue[0]}; !reflect.DeepEqual(res.Items, want) {
+		t.Fatalf("expected currencies: %+v, got: %+v", want, res.Items)
+	}
+}
@@ -3,23 +3,44 @@ package controllers
 import (
 	"net/http"
 
+	"go.uber.org/zap"
+
 	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
 	"github.com/steevehook/expenses-rest-api/transport"
 )
 
 type expenseDeleter interface {
-	DeleteExpense(id string) error
+	DeleteExpense(userID, id string) error
+	DeleteExpenses(userID string, ids []string) (models.DeleteExpensesReport, error)
 }
 
+// deleteExpense deletes a single expense, or a comma separated list of expenses in a single transaction.
+// Lists report the IDs that were not found, while a single missing expense results in not found
 func deleteExpense(service expenseDeleter) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		id, err := parseIDParam(r)
+		ids, err := parseIDsParam(r)
 		if err != nil {
 			transport.SendHTTPError(w, err)
 			return
 		}
 
-		err = service.DeleteExpense(id)
+		if len(ids) > 1 {
+			report, err := service.DeleteExpenses(callerID(r), ids)
+			if err != nil {
+				transport.SendHTTPError(w, err)
+				return
+			}
+			logging.Logger.Info(
+				"successfully deleted expenses",
+				zap.Int("deleted", len(report.Deleted)),
+				zap.Int("not_found", len(report.NotFound)),
+			)
+			transport.SendJSON(w, http.StatusOK, report)
+			return
+		}
+
+		err = service.DeleteExpense(callerID(r), ids[0])
 		if err != nil {
 			transport.SendHTTPError(w, err)
 			return
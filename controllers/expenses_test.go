@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/services"
+	"github.com/steevehook/expenses-rest-api/transport"
+)
+
+const (
+	testExpenseID      = "9311744c-3746-3502-84c9-d06e8b5ea2d6"
+	testOtherExpenseID = "4bb9c1a0-ded7-3f16-93fb-bd2cbac9a815"
+)
+
+// fakeExpensesRepo represents an expenses repository that fails every call with a given error
+type fakeExpensesRepo struct {
+	err error
+}
+
+func (r fakeExpensesRepo) GetAllExpenses(models.GetAllExpensesRequest) ([]models.Expense, error) {
+	return []models.Expense{}, r.err
+}
+
+func (r fakeExpensesRepo) StreamExpenses(models.GetAllExpensesRequest, func(models.Expense) error) error {
+	return r.err
+}
+
+func (r fakeExpensesRepo) GetExpensesByIDs(string, []string) ([]models.Expense, error) {
+	return []models.Expense{}, r.err
+}
+
+func (r fakeExpensesRepo) CreateExpense(req models.CreateExpenseRequest) (models.Expense, error) {
+	if r.err != nil {
+		return models.Expense{}, r.err
+	}
+	return models.Expense{ID: testExpenseID, OwnerID: req.UserID, Title: req.Title}, nil
+}
+
+func (r fakeExpensesRepo) CreateExpenses([]models.CreateExpenseRequest) ([]models.Expense, error) {
+	return []models.Expense{}, r.err
+}
+
+func (r fakeExpensesRepo) GetExistingSourceIDs([]string) (map[string]bool, error) {
+	return map[string]bool{}, r.err
+}
+
+func (r fakeExpensesRepo) ApplyExpensesBatch(string, []models.BatchOperation, bool) ([]models.BatchResult, error) {
+	return []models.BatchResult{}, r.err
+}
+
+func (r fakeExpensesRepo) UpdateExpense(req models.UpdateExpenseRequest) (models.Expense, error) {
+	if r.err != nil {
+		return models.Expense{}, r.err
+	}
+	return models.Expense{ID: req.ID, OwnerID: req.UserID, Title: req.Title}, nil
+}
+
+func (r fakeExpensesRepo) DeleteExpense(string, string) error {
+	return r.err
+}
+
+func (r fakeExpensesRepo) Count(models.GetAllExpensesRequest) (int, error) {
+	return 0, r.err
+}
+
+func (r fakeExpensesRepo) GetTags(string) ([]models.TagUsage, error) {
+	return []models.TagUsage{}, r.err
+}
+
+func (r fakeExpensesRepo) Summarize(models.SummaryRequest) ([]models.SummaryBucket, error) {
+	return []models.SummaryBucket{}, r.err
+}
+
+func (r fakeExpensesRepo) Close() error {
+	return nil
+}
+
+func TestMain(m *testing.M) {
+	logging.Logger = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+func TestExpenseHandlersSurfaceStorageErrors(t *testing.T) {
+	storageErrors := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantType string
+	}{
+		{
+			name:     "transient storage failure",
+			err:      driver.ErrBadConn,
+			wantCode: http.StatusServiceUnavailable,
+			wantType: transport.StorageUnavailableErrorType,
+		},
+		{
+			name:     "conflict",
+			err:      models.ConflictError{Message: "already exists"},
+			wantCode: http.StatusConflict,
+			wantType: transport.ConflictErrorType,
+		},
+		{
+			name:     "not found",
+			err:      models.ResourceNotFoundError{Message: "not found"},
+			wantCode: http.StatusNotFound,
+			wantType: transport.ResourceNotFoundErrorType,
+		},
+		{
+			name:     "unknown failure",
+			err:      errors.New("disk is on fire"),
+			wantCode: http.StatusInternalServerError,
+			wantType: transport.ServiceErrorType,
+		},
+	}
+	requests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		handler func(services.Expenses) http.Handler
+	}{
+		{
+			name:    "create",
+			method:  http.MethodPost,
+			path:    "/expenses",
+			body:    `{"title":"coffee","price":2.5,"currency":"USD"}`,
+			handler: func(s services.Expenses) http.Handler { return createExpense(s) },
+		},
+		{
+			name:    "update",
+			method:  http.MethodPatch,
+			path:    "/expenses/" + testExpenseID,
+			body:    `{"title":"tea"}`,
+			handler: func(s services.Expenses) http.Handler { return updateExpense(s) },
+		},
+		{
+			name:    "delete",
+			method:  http.MethodDelete,
+			path:    "/expenses/" + testExpenseID,
+			handler: func(s services.Expenses) http.Handler { return deleteExpense(s) },
+		},
+		{
+			name:    "bulk delete",
+			method:  http.MethodDelete,
+			path:    "/expenses/" + testExpenseID + "," + testOtherExpenseID,
+			handler: func(s services.Expenses) http.Handler { return deleteExpense(s) },
+		},
+		{
+			name:    "get all",
+			method:  http.MethodGet,
+			path:    "/expenses",
+			handler: func(s services.Expenses) http.Handler { return getAllExpenses(s) },
+		},
+	}
+
+	for _, req := range requests {
+		for _, storageErr := range storageErrors {
+			t.Run(req.name+"/"+storageErr.name, func(t *testing.T) {
+				svc := services.Expenses{ExpensesRepo: fakeExpensesRepo{err: storageErr.err}}
+				rec := serve(req.handler(svc), req.method, req.path, req.body)
+
+				if rec.Code != storageErr.wantCode {
+					t.Fatalf("expected status: %d, got: %d", storageErr.wantCode, rec.Code)
+				}
+				var httpErr models.HTTPError
+				if err := json.NewDecoder(rec.Body).Decode(&httpErr); err != nil {
+					t.Fatalf("could not decode error response: %v", err)
+				}
+				if httpErr.Type != storageErr.wantType {
+					t.Fatalf("expected error type: %s, got: %s", storageErr.wantType, httpErr.Type)
+				}
+			})
+		}
+	}
+}
+
+func TestCreateExpenseReturnsCreatedExpense(t *testing.T) {
+	svc := services.Expenses{ExpensesRepo: fakeExpensesRepo{}}
+	rec := serve(createExpense(svc), http.MethodPost, "/expenses", `{"title":"coffee","price":2.5,"currency":"USD"}`)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status: %d, got: %d", http.StatusCreated, rec.Code)
+	}
+	if location := rec.Header().Get(models.LocationHeader); location != "/expenses/"+testExpenseID {
+		t.Fatalf("unexpected location header: %s", location)
+	}
+}
+
+func TestCreateExpenseRejectsMalformedJSON(t *testing.T) {
+	svc := services.Expenses{ExpensesRepo: fakeExpensesRepo{}}
+	rec := serve(createExpense(svc), http.MethodPost, "/expenses", `{"title":`)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status: %d, got: %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+// serve routes a request with an authenticated member to a given handler and records the response
+func serve(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	router := httprouter.New()
+	router.Handler(method, "/expenses", h)
+	if method == http.MethodDelete {
+		router.Handler(method, "/expenses/:"+idsRouteParam, h)
+	} else {
+		router.Handler(method, "/expenses/:"+idRouteParam, h)
+	}
+
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set(models.ContentType, models.ApplicationJSONType)
+	user := models.User{ID: "cc2c8d11-825a-4e75-aa14-cca8610723d6", Role: models.MemberRole}
+	r = r.WithContext(models.ContextWithUser(context.Background(), user))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, r)
+	return rec
+}
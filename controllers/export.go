@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
+)
+
+const (
+	exportCSVPathSegment = "export.csv"
+	exportCSVFilename    = "expenses.csv"
+	columnsQueryParam    = "columns"
+)
+
+type expensesExporter interface {
+	ExportExpenses(models.GetAllExpensesRequest, func(models.Expense) error) error
+}
+
+// exportExpensesCSV streams the expenses matched by the filters of the list endpoint as CSV, ignoring pagination.
+// The optional columns are picked by the columns param, every applicable optional column is included without it
+func exportExpensesCSV(service expensesExporter, writeTimeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := parseExpensesFilters(r)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		if len(req.Sort) == 0 {
+			req.Sort = models.DefaultSort
+		}
+		if err = req.Validate(); err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		columns := exportColumns(r, req)
+		if err = models.ValidateExportColumns(columns, req.ConvertTo); err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		header := append(append([]string{}, models.ExpenseExportColumns...), columns...)
+		writer := transport.NewCSVWriter(w, exportCSVFilename, header, writeTimeout)
+		err = service.ExportExpenses(req, func(expense models.Expense) error {
+			return writer.Write(models.ExpenseExportRow(expense, columns))
+		})
+		if err == nil {
+			err = writer.Close()
+		}
+		if err != nil {
+			writer.Fail(err)
+			return
+		}
+		logging.Logger.Info("successfully exported expenses", zap.String("format", "csv"))
+	})
+}
+
+// exportColumns parses the optional export columns, defaulting to the ones that apply to the request
+func exportColumns(r *http.Request, req models.GetAllExpensesRequest) []string {
+	if param := strings.TrimSpace(r.URL.Query().Get(columnsQueryParam)); param != "" {
+		columns := strings.Split(param, ",")
+		for i := range columns {
+			columns[i] = strings.TrimSpace(columns[i])
+		}
+		return columns
+	}
+
+	columns := []string{models.CategoryIDExportColumn, models.TagsExportColumn}
+	if req.AllUsers {
+		columns = append([]string{models.OwnerIDExportColumn}, columns...)
+	}
+	if req.ConvertTo != "" {
+		columns = append(columns, models.ConvertedPriceExportColumn, models.ConvertedCurrencyExportColumn)
+	}
+	return columns
+}
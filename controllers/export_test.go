@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// fakeExporter represents an expenses exporter of a fixed list of expenses, failing after a given amount of them
+type fakeExporter struct {
+	expenses  []models.Expense
+	failAfter int
+	req       models.GetAllExpensesRequest
+}
+
+func (e *fakeExporter) ExportExpenses(req models.GetAllExpensesRequest, fn func(models.Expense) error) error {
+	e.req = req
+	for i, expense := range e.expenses {
+		if e.failAfter > 0 && i == e.failAfter {
+			return errors.New("storage failure")
+		}
+		if err := fn(expense); err != nil {
+			return err
+		}
+	}
+	if e.failAfter > 0 && e.failAfter >= len(e.expenses) {
+		return errors.New("storage failure")
+	}
+	return nil
+}
+
+func exportedExpenses(n int) []models.Expense {
+	createdAt := time.Date(2021, time.March, 4, 10, 30, 0, 0, time.UTC)
+	expenses := make([]models.Expense, 0, n)
+	for i := 0; i < n; i++ {
+		expenses = append(expenses, models.Expense{
+			ID:         fmt.Sprintf("e%d", i),
+			Title:      "lunch",
+			Price:      models.Money{Amount: 125000, Currency: "EUR"},
+			Tags:       []string{"food"},
+			CreatedAt:  createdAt,
+			ModifiedAt: createdAt,
+		})
+	}
+	return expenses
+}
+
+func TestExportExpensesCSV(t *testing.T) {
+	exporter := &fakeExporter{expenses: exportedExpenses(450)}
+	rec := serve(exportExpensesCSV(exporter, time.Minute), http.MethodGet, "/expenses/export.csv?columns=tags", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status: %d, got: %d, body: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if contentType := rec.Header().Get(models.ContentType); !strings.HasPrefix(contentType, models.TextCSVType) {
+		t.Fatalf("expected csv content type, got: %s", contentType)
+	}
+	if disposition := rec.Header().Get(models.ContentDispositionHeader); disposition != "attachment; filename=expenses.csv" {
+		t.Fatalf("unexpected content disposition: %s", disposition)
+	}
+	if !rec.Flushed {
+		t.Fatal("expected the response to be flushed while streaming")
+	}
+	if !reflect.DeepEqual(exporter.req.Sort, models.DefaultSort) {
+		t.Fatalf("expected the default sort, got: %v", exporter.req.Sort)
+	}
+
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("could not read csv: %v", err)
+	}
+	if len(rows) != 451 {
+		t.Fatalf("expected: %d rows, got: %d", 451, len(rows))
+	}
+	wantHeader := append(append([]string{}, models.ExpenseExportColumns...), models.TagsExportColumn)
+	if strings.Join(rows[0], ",") != strings.Join(wantHeader, ",") {
+		t.Fatalf("expected header: %v, got: %v", wantHeader, rows[0])
+	}
+	if got := strings.Join(rows[1], ","); got != "e0,lunch,12.50,EUR,2021-03-04T10:30:00Z,2021-03-04T10:30:00Z,food" {
+		t.Fatalf("unexpected first row: %s", got)
+	}
+}
+
+func TestExportExpensesCSVErrors(t *testing.T) {
+	t.Run("invalid column", func(t *testing.T) {
+		exporter := &fakeExporter{expenses: exportedExpenses(1)}
+		rec := serve(exportExpensesCSV(exporter, time.Minute), http.MethodGet, "/expenses/export.csv?columns=password", "")
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected status: %d, got: %d", http.StatusBadRequest, rec.Code)
+		}
+	})
+
+	t.Run("failure before the first row", func(t *testing.T) {
+		exporter := &fakeExporter{failAfter: 1}
+		rec := serve(exportExpensesCSV(exporter, time.Minute), http.MethodGet, "/expenses/export.csv", "")
+		if rec.Code != http.StatusInternalServerError {
+			t.Fatalf("expected status: %d, got: %d", http.StatusInternalServerError, rec.Code)
+		}
+		if contentType := rec.Header().Get(models.ContentType); !strings.HasPrefix(contentType, models.ApplicationJSONType) {
+			t.Fatalf("expected a json error, got content type: %s", contentType)
+		}
+	})
+
+	t.Run("failure after the first row", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r != http.ErrAbortHandler {
+				t.Fatalf("expected the response to be aborted, got: %v", r)
+			}
+		}()
+		exporter := &fakeExporter{expenses: exportedExpenses(3), failAfter: 2}
+		serve(exportExpensesCSV(exporter, time.Minute), http.MethodGet, "/expenses/export.csv", "")
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/steevehook/expenses-rest-api/models"
 	"github.com/steevehook/expenses-rest-api/transport"
@@ -14,6 +15,17 @@ import (
 const (
 	pageQueryParam     = "page"
 	pageSizeQueryParam = "page_size"
+	tagQueryParam      = "tag"
+	tagMatchQueryParam = "tag_match"
+	createdFromParam   = "created_from"
+	createdToParam     = "created_to"
+	minPriceParam      = "min_price"
+	maxPriceParam      = "max_price"
+	currencyParam      = "currency"
+	titleQueryParam    = "q"
+	sortQueryParam     = "sort"
+	cursorQueryParam   = "cursor"
+	convertToParam     = "convert_to"
 
 	defaultPage     = 1
 	defaultPageSize = 10
@@ -21,14 +33,16 @@ const (
 
 type allExpensesGetter interface {
 	GetAllExpenses(models.GetAllExpensesRequest) ([]models.Expense, error)
-	ExpensesCount() (int, error)
+	ExpensesCount(models.GetAllExpensesRequest) (int, error)
 }
 
 type getAllExpensesResponse struct {
-	Items    []models.Expense `json:"items"`
-	Total    int              `json:"total"`
-	NextPage string           `json:"next_page,omitempty"`
-	PrevPage string           `json:"prev_page,omitempty"`
+	Items      []models.Expense `json:"items"`
+	Total      int              `json:"total"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+	PrevCursor string           `json:"prev_cursor,omitempty"`
+	NextPage   string           `json:"next_page,omitempty"`
+	PrevPage   string           `json:"prev_page,omitempty"`
 }
 
 func getAllExpenses(service allExpensesGetter) http.Handler {
@@ -43,35 +57,122 @@ func getAllExpenses(service allExpensesGetter) http.Handler {
 			transport.SendHTTPError(w, err)
 			return
 		}
-		req := models.GetAllExpensesRequest{
-			Page:     page,
-			PageSize: pageSize,
+		req, err := parseExpensesFilters(r)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		req.Page, req.PageSize = page, pageSize
+		if token := r.URL.Query().Get(cursorQueryParam); token != "" {
+			cursor, err := models.DecodeCursor(token)
+			if err != nil {
+				transport.SendHTTPError(w, err)
+				return
+			}
+			if len(req.Sort) == 0 {
+				req.Sort = cursor.Sort
+			}
+			req.Cursor = &cursor
+		}
+		if len(req.Sort) == 0 {
+			req.Sort = models.DefaultSort
+		}
+		if err = req.Validate(); err != nil {
+			transport.SendHTTPError(w, err)
+			return
 		}
 
-		expenses, err := service.GetAllExpenses(req)
+		var expenses []models.Expense
+		var hasNext, hasPrev bool
+		if req.Cursor != nil {
+			expenses, hasNext, hasPrev, err = getCursorPage(service, req)
+		} else {
+			expenses, err = service.GetAllExpenses(req)
+		}
 		if err != nil {
 			transport.SendHTTPError(w, err)
 			return
 		}
-		count, err := service.ExpensesCount()
+		count, err := service.ExpensesCount(req)
 		if err != nil {
 			transport.SendHTTPError(w, err)
 			return
 		}
+		if req.Cursor == nil {
+			hasNext, hasPrev = page*pageSize < count, page > 1
+		}
+
 		res := getAllExpensesResponse{
 			Items: expenses,
 			Total: count,
 		}
-		if page*pageSize+1 <= count {
-			res.NextPage = fmt.Sprintf("%s?%s", r.URL.Path, encodePageParams(page+1, pageSize))
+		if len(expenses) > 0 && hasNext {
+			next := models.Cursor{Sort: req.Sort, Key: expenses[len(expenses)-1]}
+			res.NextCursor = next.Encode()
+		}
+		if len(expenses) > 0 && hasPrev {
+			prev := models.Cursor{Sort: req.Sort, Before: true, Key: expenses[0]}
+			res.PrevCursor = prev.Encode()
 		}
-		if (page-1)*pageSize < count && page-1 > 0 {
-			res.PrevPage = fmt.Sprintf("%s?%s", r.URL.Path, encodePageParams(page-1, pageSize))
+
+		switch {
+		case req.Cursor != nil:
+			if res.NextCursor != "" {
+				res.NextPage = fmt.Sprintf("%s?%s", r.URL.Path, encodeCursorParams(r.URL.Query(), res.NextCursor, pageSize))
+			}
+			if res.PrevCursor != "" {
+				res.PrevPage = fmt.Sprintf("%s?%s", r.URL.Path, encodeCursorParams(r.URL.Query(), res.PrevCursor, pageSize))
+			}
+		default:
+			if hasNext {
+				res.NextPage = fmt.Sprintf("%s?%s", r.URL.Path, encodePageParams(r.URL.Query(), page+1, pageSize))
+			}
+			if hasPrev && (page-1)*pageSize < count {
+				res.PrevPage = fmt.Sprintf("%s?%s", r.URL.Path, encodePageParams(r.URL.Query(), page-1, pageSize))
+			}
 		}
 		transport.SendJSON(w, http.StatusOK, res)
 	})
 }
 
+// getCursorPage fetches the page of expenses next to the request cursor, along with whether there are more pages around.
+// One extra expense gets fetched in order to find out whether there is one more page in the cursor direction,
+// while the opposite direction is looked up past the edge of the page, since its expenses may have been deleted
+func getCursorPage(service allExpensesGetter, req models.GetAllExpensesRequest) ([]models.Expense, bool, bool, error) {
+	size := req.PageSize
+	req.PageSize++
+	expenses, err := service.GetAllExpenses(req)
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	backward := req.Cursor.Before
+	more := len(expenses) > size
+	switch {
+	case more && backward:
+		expenses = expenses[1:]
+	case more:
+		expenses = expenses[:size]
+	}
+	if len(expenses) == 0 {
+		return expenses, false, false, nil
+	}
+
+	edge := models.Cursor{Sort: req.Sort, Before: !backward, Key: expenses[0]}
+	if backward {
+		edge.Key = expenses[len(expenses)-1]
+	}
+	req.Cursor, req.PageSize, req.ConvertTo = &edge, 1, ""
+	around, err := service.GetAllExpenses(req)
+	if err != nil {
+		return nil, false, false, err
+	}
+	if backward {
+		return expenses, len(around) > 0, more, nil
+	}
+	return expenses, more, len(around) > 0, nil
+}
+
 func parseQueryParam(r *http.Request, paramName string, defaultValue int) (int, error) {
 	param := r.URL.Query().Get(paramName)
 	if strings.TrimSpace(param) == "" {
@@ -87,9 +188,92 @@ func parseQueryParam(r *http.Request, paramName string, defaultValue int) (int,
 	return intParam, nil
 }
 
-func encodePageParams(page, pageSize int) string {
-	params := url.Values{}
-	params.Add(pageQueryParam, strconv.Itoa(page))
-	params.Add(pageSizeQueryParam, strconv.Itoa(pageSize))
+// parseExpensesFilters parses the filters, the sort and the conversion of a fetch all expenses request
+func parseExpensesFilters(r *http.Request) (models.GetAllExpensesRequest, error) {
+	req := models.GetAllExpensesRequest{
+		UserID:     callerID(r),
+		AllUsers:   callerCan(r, models.ReadAllExpensesPermission),
+		Tags:       r.URL.Query()[tagQueryParam],
+		TagMatch:   r.URL.Query().Get(tagMatchQueryParam),
+		Currencies: r.URL.Query()[currencyParam],
+		Query:      r.URL.Query().Get(titleQueryParam),
+		ConvertTo:  strings.ToUpper(strings.TrimSpace(r.URL.Query().Get(convertToParam))),
+	}
+	var err error
+	if req.Sort, err = models.ParseSort(r.URL.Query().Get(sortQueryParam)); err != nil {
+		return models.GetAllExpensesRequest{}, err
+	}
+	if err = parseFilterParams(r, &req); err != nil {
+		return models.GetAllExpensesRequest{}, err
+	}
+	return req, nil
+}
+
+// parseFilterParams parses the date and price range filters of a fetch all expenses request
+func parseFilterParams(r *http.Request, req *models.GetAllExpensesRequest) error {
+	var err error
+	if req.CreatedFrom, err = parseTimeQueryParam(r, createdFromParam, false); err != nil {
+		return err
+	}
+	if req.CreatedTo, err = parseTimeQueryParam(r, createdToParam, true); err != nil {
+		return err
+	}
+	if req.MinPrice, err = parseDecimalQueryParam(r, minPriceParam, models.PriceScale); err != nil {
+		return err
+	}
+	req.MaxPrice, err = parseDecimalQueryParam(r, maxPriceParam, models.PriceScale)
+	return err
+}
+
+// parseTimeQueryParam parses RFC3339 times or plain dates, plain dates span the whole day when endOfDay is set
+func parseTimeQueryParam(r *http.Request, paramName string, endOfDay bool) (time.Time, error) {
+	param := strings.TrimSpace(r.URL.Query().Get(paramName))
+	if param == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, param); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse(models.DateLayout, param)
+	if err != nil {
+		e := models.DataValidationError{
+			Message: fmt.Sprintf("invalid value: %s for param: %s, expected a date or RFC3339 time", param, paramName),
+		}
+		return time.Time{}, e
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return t, nil
+}
+
+// parseDecimalQueryParam parses a decimal number into an integer of a given amount of decimal digits
+func parseDecimalQueryParam(r *http.Request, paramName string, digits int) (int64, error) {
+	param := strings.TrimSpace(r.URL.Query().Get(paramName))
+	if param == "" {
+		return 0, nil
+	}
+	decimalParam, err := models.ParseDecimal(param, digits)
+	if err != nil || decimalParam < 0 {
+		e := models.DataValidationError{
+			Message: fmt.Sprintf("invalid value: %s for param: %s", param, paramName),
+		}
+		return 0, e
+	}
+	return decimalParam, nil
+}
+
+// encodeCursorParams encodes the query params of the page at a given cursor, keeping the rest of the given params
+func encodeCursorParams(params url.Values, cursor string, pageSize int) string {
+	params.Del(pageQueryParam)
+	params.Set(cursorQueryParam, cursor)
+	params.Set(pageSizeQueryParam, strconv.Itoa(pageSize))
+	return params.Encode()
+}
+
+// encodePageParams encodes the query params of another page, keeping the rest of the given params, such as filters
+func encodePageParams(params url.Values, page, pageSize int) string {
+	params.Set(pageQueryParam, strconv.Itoa(page))
+	params.Set(pageSizeQueryParam, strconv.Itoa(pageSize))
 	return params.Encode()
 }
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// fakeListing represents an expenses listing sorted by ID, cursors point at the expenses around the key ID
+type fakeListing struct {
+	expenses []models.Expense
+}
+
+func (l fakeListing) GetAllExpenses(req models.GetAllExpensesRequest) ([]models.Expense, error) {
+	res := make([]models.Expense, 0)
+	if req.Cursor == nil {
+		for i := (req.Page - 1) * req.PageSize; i < len(l.expenses) && len(res) < req.PageSize; i++ {
+			res = append(res, l.expenses[i])
+		}
+		return res, nil
+	}
+	for _, expense := range l.expenses {
+		if !req.Cursor.Before && expense.ID > req.Cursor.Key.ID {
+			res = append(res, expense)
+		}
+		if req.Cursor.Before && expense.ID < req.Cursor.Key.ID {
+			res = append(res, expense)
+		}
+	}
+	if req.Cursor.Before && len(res) > req.PageSize {
+		return res[len(res)-req.PageSize:], nil
+	}
+	if len(res) > req.PageSize {
+		return res[:req.PageSize], nil
+	}
+	return res, nil
+}
+
+func (l fakeListing) ExpensesCount(models.GetAllExpensesRequest) (int, error) {
+	return len(l.expenses), nil
+}
+
+func TestGetAllExpensesCursorLinks(t *testing.T) {
+	listing := fakeListing{}
+	for _, id := range []string{"e1", "e2", "e3", "e4", "e5"} {
+		listing.expenses = append(listing.expenses, models.Expense{ID: id, Price: models.Money{Currency: "USD"}})
+	}
+	tests := []struct {
+		name     string
+		cursor   models.Cursor
+		wantIDs  []string
+		wantNext bool
+		wantPrev bool
+	}{
+		{
+			name:     "forward from the middle",
+			cursor:   models.Cursor{Key: models.Expense{ID: "e1"}},
+			wantIDs:  []string{"e2", "e3"},
+			wantNext: true,
+			wantPrev: true,
+		},
+		{
+			name:     "forward onto the first page",
+			cursor:   models.Cursor{Key: models.Expense{ID: "e0"}},
+			wantIDs:  []string{"e1", "e2"},
+			wantNext: true,
+		},
+		{
+			name:     "forward onto the last page",
+			cursor:   models.Cursor{Key: models.Expense{ID: "e3"}},
+			wantIDs:  []string{"e4", "e5"},
+			wantPrev: true,
+		},
+		{
+			name:     "backward onto the first page",
+			cursor:   models.Cursor{Before: true, Key: models.Expense{ID: "e3"}},
+			wantIDs:  []string{"e1", "e2"},
+			wantNext: true,
+		},
+		{
+			name:     "backward onto the last page",
+			cursor:   models.Cursor{Before: true, Key: models.Expense{ID: "e9"}},
+			wantIDs:  []string{"e4", "e5"},
+			wantPrev: true,
+		},
+		{
+			name:    "past the end",
+			cursor:  models.Cursor{Key: models.Expense{ID: "e9"}},
+			wantIDs: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.cursor.Sort = models.DefaultSort
+			path := "/expenses?page_size=2&cursor=" + test.cursor.Encode()
+			rec := serve(getAllExpenses(listing), http.MethodGet, path, "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status: %d, got: %d, body: %s", http.StatusOK, rec.Code, rec.Body.String())
+			}
+
+			var res getAllExpensesResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+				t.Fatalf("could not decode response: %v", err)
+			}
+			ids := make([]string, 0, len(res.Items))
+			for _, expense := range res.Items {
+				ids = append(ids, expense.ID)
+			}
+			if !reflect.DeepEqual(ids, test.wantIDs) {
+				t.Fatalf("expected items: %v, got: %v", test.wantIDs, ids)
+			}
+			if hasNext := res.NextCursor != ""; hasNext != test.wantNext {
+				t.Fatalf("expected next cursor: %v, got: %v", test.wantNext, hasNext)
+			}
+			if hasPrev := res.PrevCursor != ""; hasPrev != test.wantPrev {
+				t.Fatalf("expected prev cursor: %v, got: %v", test.wantPrev, hasPrev)
+			}
+		})
+	}
+}
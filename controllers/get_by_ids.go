@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/steevehook/expenses-rest-api/models"
 	"github.com/steevehook/expenses-rest-api/transport"
@@ -24,7 +25,14 @@ func getExpensesByIDs(service expensesByIDsGetter) http.Handler {
 		}
 
 		req := models.GetExpensesByIDsRequest{
-			IDs: ids,
+			UserID:    callerID(r),
+			AllUsers:  callerCan(r, models.ReadAllExpensesPermission),
+			IDs:       ids,
+			ConvertTo: strings.ToUpper(strings.TrimSpace(r.URL.Query().Get(convertToParam))),
+		}
+		if err = req.Validate(); err != nil {
+			transport.SendHTTPError(w, err)
+			return
 		}
 		expenses, err := service.GetExpensesByIDs(req)
 		if err != nil {
@@ -0,0 +1,17 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/steevehook/expenses-rest-api/transport"
+)
+
+type healthResponse struct {
+	Status string `json:"status"`
+}
+
+func health() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		transport.SendJSON(w, http.StatusOK, healthResponse{Status: "ok"})
+	})
+}
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
+)
+
+const (
+	importPathSegment   = "import"
+	modeQueryParam      = "mode"
+	mapQueryParam       = "map"
+	currencyQueryParam  = "currency"
+	dateOrderQueryParam = "date_order"
+	dryRunQueryParam    = "dry_run"
+	maxImportFileSize   = 32 << 20
+	importMappingFormat = "column:field"
+)
+
+// statementFormats maps the content types of bank statements to statement formats
+var statementFormats = map[string]string{
+	models.ApplicationOFXType:  models.OFXStatementFormat,
+	models.ApplicationQFXType:  models.OFXStatementFormat,
+	models.ApplicationQIFType:  models.QIFStatementFormat,
+	models.ApplicationXQIFType: models.QIFStatementFormat,
+}
+
+type expensesImporter interface {
+	ImportExpenses(models.ImportExpensesRequest) (models.ImportReport, error)
+	ImportStatement(models.ImportStatementRequest) (models.ImportReport, error)
+}
+
+// importExpenses creates expenses from a CSV file with a header or from an OFX, QFX or QIF bank statement,
+// picked by the content type. CSV columns are mapped to expense fields by repeated map=column:field params,
+// or matched by the field name
+func importExpenses(service expensesImporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get(models.ContentType))
+		_, isStatement := statementFormats[mediaType]
+		if mediaType != models.TextCSVType && !isStatement {
+			transport.SendHTTPError(w, models.DataValidationError{
+				Message: "import file content-type must be one of: " + strings.Join([]string{
+					models.TextCSVType,
+					models.ApplicationOFXType,
+					models.ApplicationQFXType,
+					models.ApplicationQIFType,
+					models.ApplicationXQIFType,
+				}, ","),
+			})
+			return
+		}
+		dryRun, err := parseBoolQueryParam(r, dryRunQueryParam)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		var report models.ImportReport
+		content := http.MaxBytesReader(w, r.Body, maxImportFileSize)
+		if isStatement {
+			report, err = importStatement(service, r, statementFormats[mediaType], dryRun, content)
+		} else {
+			report, err = importCSV(service, r, dryRun, content)
+		}
+		if err != nil {
+			logging.Logger.Debug("could not import expenses", zap.Error(err))
+			transport.SendHTTPError(w, err)
+			return
+		}
+		logging.Logger.Info(
+			"successfully imported expenses",
+			zap.Int("created", report.Created),
+			zap.Int("skipped", report.Skipped),
+			zap.Int("failed", report.Failed),
+			zap.Bool("dry_run", report.DryRun),
+		)
+		transport.SendJSON(w, http.StatusOK, report)
+	})
+}
+
+func importCSV(service expensesImporter, r *http.Request, dryRun bool, content io.Reader) (models.ImportReport, error) {
+	query := r.URL.Query()
+	req := models.ImportExpensesRequest{
+		UserID:   callerID(r),
+		Mode:     query.Get(modeQueryParam),
+		Mapping:  map[string]string{},
+		Currency: query.Get(currencyQueryParam),
+		DryRun:   dryRun,
+		Content:  content,
+	}
+	for _, m := range query[mapQueryParam] {
+		parts := strings.SplitN(m, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return models.ImportReport{}, models.DataValidationError{
+				Message: "invalid map: " + m + ", expected format: " + importMappingFormat,
+			}
+		}
+		req.Mapping[parts[0]] = strings.TrimSpace(parts[1])
+	}
+	if err := req.Validate(); err != nil {
+		return models.ImportReport{}, err
+	}
+	return service.ImportExpenses(req)
+}
+
+func importStatement(
+	service expensesImporter,
+	r *http.Request,
+	format string,
+	dryRun bool,
+	content io.Reader,
+) (models.ImportReport, error) {
+	query := r.URL.Query()
+	req := models.ImportStatementRequest{
+		UserID:    callerID(r),
+		Format:    format,
+		Mode:      query.Get(modeQueryParam),
+		Currency:  query.Get(currencyQueryParam),
+		DateOrder: query.Get(dateOrderQueryParam),
+		DryRun:    dryRun,
+		Content:   content,
+	}
+	if err := req.Validate(); err != nil {
+		return models.ImportReport{}, err
+	}
+	return service.ImportStatement(req)
+}
@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// readingImporter represents an expenses importer that reads the whole file and imports nothing
+type readingImporter struct{}
+
+func (readingImporter) ImportExpenses(req models.ImportExpensesRequest) (models.ImportReport, error) {
+	_, err := io.Copy(ioutil.Discard, req.Content)
+	return models.ImportReport{}, err
+}
+
+func (readingImporter) ImportStatement(req models.ImportStatementRequest) (models.ImportReport, error) {
+	_, err := io.Copy(ioutil.Discard, req.Content)
+	return models.ImportReport{}, err
+}
+
+func TestImportExpensesRejectsLargeFiles(t *testing.T) {
+	body := "title,price,currency\n" + strings.Repeat("lunch,12.50,USD\n", maxImportFileSize/16+1)
+	r := httptest.NewRequest(http.MethodPost, "/expenses/import", strings.NewReader(body))
+	r.Header.Set(models.ContentType, models.TextCSVType)
+	user := models.User{ID: "cc2c8d11-825a-4e75-aa14-cca8610723d6", Role: models.MemberRole}
+	r = r.WithContext(models.ContextWithUser(context.Background(), user))
+
+	rec := httptest.NewRecorder()
+	importExpenses(readingImporter{}).ServeHTTP(rec, r)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status: %d, got: %d, body: %s", http.StatusRequestEntityTooLarge, rec.Code, rec.Body.String())
+	}
+}
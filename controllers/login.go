@@ -2,13 +2,50 @@ package controllers
 
 import (
 	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
 )
 
 type loginner interface {
-	Login()
+	Login(models.LoginRequest) (string, time.Time, error)
+}
+
+type loginResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 func login(service loginner) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.LoginRequest
+		err := parseBody(r, &req)
+		if err != nil {
+			logging.Logger.Error("could not unmarshal login body", zap.Error(err))
+			transport.SendHTTPError(w, err)
+			return
+		}
+		if err = req.Validate(); err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		token, expiresAt, err := service.Login(req)
+		if err != nil {
+			logging.Logger.Debug("could not log in user", zap.Error(err))
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		logging.Logger.Info("successfully logged in user")
+		res := loginResponse{
+			Token:     token,
+			ExpiresAt: expiresAt,
+		}
+		transport.SendJSON(w, http.StatusOK, res)
 	})
 }
@@ -2,13 +2,34 @@ package controllers
 
 import (
 	"net/http"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/middleware"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
 )
 
 type logoutter interface {
-	Signup()
+	Logout(token string) error
 }
 
 func logout(service logoutter) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := middleware.BearerToken(r)
+		if !ok {
+			err := models.UnauthorizedError{
+				Message: "logout requires a bearer token",
+			}
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		err := service.Logout(token)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		logging.Logger.Info("successfully logged out user")
+		w.WriteHeader(http.StatusNoContent)
 	})
 }
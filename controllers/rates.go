@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"io"
+	"mime"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
+)
+
+const (
+	dateRouteParam = "date"
+	fromQueryParam = "from"
+	toQueryParam   = "to"
+
+	// maxRatesFileSize limits the size of imported rates files, the full ECB history is a few megabytes
+	maxRatesFileSize = 32 << 20
+)
+
+type ratesPutter interface {
+	PutRates(models.DailyRates) (models.DailyRates, error)
+}
+
+type ratesGetter interface {
+	GetRates(models.GetRatesRequest) ([]models.DailyRates, error)
+}
+
+type ratesImporter interface {
+	ImportRates(format string, body io.Reader) (int, error)
+}
+
+type getRatesResponse struct {
+	Items []models.DailyRates `json:"items"`
+}
+
+type importRatesResponse struct {
+	Imported int `json:"imported"`
+}
+
+func putRates(service ratesPutter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var rates models.DailyRates
+		if err := parseBody(r, &rates); err != nil {
+			logging.Logger.Error("could not unmarshal put rates body", zap.Error(err))
+			transport.SendHTTPError(w, err)
+			return
+		}
+		rates.Date = routeParam(r, dateRouteParam)
+
+		rates, err := service.PutRates(rates)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		logging.Logger.Info("successfully saved the rates", zap.String("date", rates.Date))
+		transport.SendJSON(w, http.StatusOK, rates)
+	})
+}
+
+func getRates(service ratesGetter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := models.GetRatesRequest{
+			From: r.URL.Query().Get(fromQueryParam),
+			To:   r.URL.Query().Get(toQueryParam),
+		}
+		if err := req.Validate(); err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		rates, err := service.GetRates(req)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		transport.SendJSON(w, http.StatusOK, getRatesResponse{Items: rates})
+	})
+}
+
+func importRates(service ratesImporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get(models.ContentType))
+		var format string
+		switch mediaType {
+		case models.TextCSVType:
+			format = models.CSVRatesFormat
+		case models.ApplicationXMLType, models.TextXMLType:
+			format = models.XMLRatesFormat
+		default:
+			err := models.DataValidationError{
+				Message: "rates file content-type must be one of: " +
+					models.TextCSVType + "," + models.ApplicationXMLType + "," + models.TextXMLType,
+			}
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		imported, err := service.ImportRates(format, http.MaxBytesReader(w, r.Body, maxRatesFileSize))
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		logging.Logger.Info("successfully imported the rates", zap.Int("days", imported))
+		transport.SendJSON(w, http.StatusOK, importRatesResponse{Imported: imported})
+	})
+}
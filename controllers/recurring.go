@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
+)
+
+type recurringExpensesGetter interface {
+	GetRecurringExpenses(userID string) ([]models.RecurringExpense, error)
+}
+
+type recurringExpenseGetter interface {
+	GetRecurringExpense(userID, id string) (models.RecurringExpense, error)
+}
+
+type recurringExpenseCreator interface {
+	CreateRecurringExpense(models.CreateRecurringExpenseRequest) (models.RecurringExpense, error)
+}
+
+type recurringExpenseUpdater interface {
+	UpdateRecurringExpense(models.UpdateRecurringExpenseRequest) (models.RecurringExpense, error)
+}
+
+type recurringExpenseDeleter interface {
+	DeleteRecurringExpense(userID, id string) error
+}
+
+type getRecurringExpensesResponse struct {
+	Items []models.RecurringExpense `json:"items"`
+}
+
+func getRecurringExpenses(service recurringExpensesGetter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recurring, err := service.GetRecurringExpenses(callerID(r))
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		transport.SendJSON(w, http.StatusOK, getRecurringExpensesResponse{Items: recurring})
+	})
+}
+
+func getRecurringExpense(service recurringExpenseGetter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		recurring, err := service.GetRecurringExpense(callerID(r), id)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		transport.SendJSON(w, http.StatusOK, recurring)
+	})
+}
+
+func createRecurringExpense(service recurringExpenseCreator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.CreateRecurringExpenseRequest
+		err := parseBody(r, &req)
+		if err != nil {
+			logging.Logger.Error("could not unmarshal create recurring expense body", zap.Error(err))
+			transport.SendHTTPError(w, err)
+			return
+		}
+		if err = req.Validate(); err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		req.UserID = callerID(r)
+		recurring, err := service.CreateRecurringExpense(req)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		logging.Logger.Info("successfully created recurring expense")
+		w.Header().Set(models.LocationHeader, "/recurring-expenses/"+recurring.ID)
+		transport.SendJSON(w, http.StatusCreated, recurring)
+	})
+}
+
+func updateRecurringExpense(service recurringExpenseUpdater) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.UpdateRecurringExpenseRequest
+		err := parseBody(r, &req)
+		if err != nil {
+			logging.Logger.Error("could not unmarshal update recurring expense body", zap.Error(err))
+			transport.SendHTTPError(w, err)
+			return
+		}
+		if err = req.Validate(); err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		id, err := parseIDParam(r)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		req.ID = id
+		req.UserID = callerID(r)
+
+		recurring, err := service.UpdateRecurringExpense(req)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		logging.Logger.Info("successfully updated recurring expense")
+		transport.SendJSON(w, http.StatusOK, recurring)
+	})
+}
+
+func deleteRecurringExpense(service recurringExpenseDeleter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		err = service.DeleteRecurringExpense(callerID(r), id)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		logging.Logger.Info("successfully deleted recurring expense")
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
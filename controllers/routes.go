@@ -11,6 +11,7 @@ import (
 	"github.com/justinas/alice"
 
 	"github.com/steevehook/expenses-rest-api/middleware"
+	"github.com/steevehook/expenses-rest-api/models"
 )
 
 const (
@@ -25,6 +26,11 @@ type ExpensesService interface {
 	expenseCreator
 	expenseUpdater
 	expenseDeleter
+	tagsGetter
+	expensesSummarizer
+	expensesExporter
+	expensesImporter
+	expensesBatcher
 }
 
 // AuthenticationService represents the Authentication service interface
@@ -32,12 +38,74 @@ type AuthenticationService interface {
 	loginner
 	signupper
 	logoutter
+	apiKeyCreator
+	apiKeysGetter
+	apiKeyDeleter
+	userRoleUpdater
+	middleware.Authenticator
+}
+
+// CategoriesService represents the expense Categories service interface
+type CategoriesService interface {
+	categoriesGetter
+	categoryGetter
+	categoryCreator
+	categoryUpdater
+	categoryDeleter
+}
+
+// BudgetsService represents the Budgets service interface
+type BudgetsService interface {
+	budgetsGetter
+	budgetGetter
+	budgetCreator
+	budgetUpdater
+	budgetDeleter
+	budgetStatusGetter
+}
+
+// AlertsService represents the budget Alerts service interface
+type AlertsService interface {
+	alertsGetter
+}
+
+// RecurringService represents the Recurring expenses service interface
+type RecurringService interface {
+	recurringExpensesGetter
+	recurringExpenseGetter
+	recurringExpenseCreator
+	recurringExpenseUpdater
+	recurringExpenseDeleter
+}
+
+// AttachmentsService represents the expense Attachments service interface
+type AttachmentsService interface {
+	attachmentsGetter
+	attachmentGetter
+	attachmentCreator
+	attachmentDeleter
+}
+
+// RatesService represents the exchange Rates service interface
+type RatesService interface {
+	ratesPutter
+	ratesGetter
+	ratesImporter
 }
 
 // RouterConfig represents the application router config
 type RouterConfig struct {
-	ExpensesSvc ExpensesService
-	AuthSvc     AuthenticationService
+	ExpensesSvc    ExpensesService
+	AuthSvc        AuthenticationService
+	CategoriesSvc  CategoriesService
+	RatesSvc       RatesService
+	BudgetsSvc     BudgetsService
+	AlertsSvc      AlertsService
+	RecurringSvc   RecurringService
+	AttachmentsSvc AttachmentsService
+	PublicRoutes   []string
+	// WriteTimeout represents the server write timeout, streamed responses extend it after every flush
+	WriteTimeout time.Duration
 }
 
 func recordMetrics() {
@@ -60,28 +128,156 @@ var (
 func NewRouter(cfg RouterConfig) http.Handler {
 	chain := alice.New(
 		middleware.HTTPLogger,
+		middleware.Authenticate(cfg.AuthSvc, cfg.PublicRoutes),
 	)
 	jsonBodyChain := chain.Append(
 		middleware.JSONBody,
 	)
+	multipartBodyChain := chain.Append(
+		middleware.MultipartBody,
+	)
 	route := func(h http.Handler) http.Handler {
 		return chain.Then(h)
 	}
 	routeWithBody := func(h http.Handler) http.Handler {
 		return jsonBodyChain.Then(h)
 	}
+	routeWithUpload := func(h http.Handler) http.Handler {
+		return multipartBodyChain.Then(h)
+	}
 	recordMetrics()
 
 	router := httprouter.New()
-	router.Handler(http.MethodGet, "/metrics", promhttp.Handler())
-	router.Handler(http.MethodGet, "/expenses", route(getAllExpenses(cfg.ExpensesSvc)))
-	router.Handler(http.MethodGet, "/expenses/:"+idsRouteParam, route(getExpensesByIDs(cfg.ExpensesSvc)))
-	router.Handler(http.MethodPost, "/expenses", routeWithBody(createExpense(cfg.ExpensesSvc)))
-	router.Handler(http.MethodPatch, "/expenses/:"+idRouteParam, routeWithBody(updateExpense(cfg.ExpensesSvc)))
-	router.Handler(http.MethodDelete, "/expenses/:"+idRouteParam, route(deleteExpense(cfg.ExpensesSvc)))
+	router.Handler(http.MethodGet, "/metrics", route(promhttp.Handler()))
+	router.Handler(http.MethodGet, "/health", route(health()))
+	router.Handler(http.MethodGet, "/expenses", route(
+		authorize(models.ReadExpensesPermission, getAllExpenses(cfg.ExpensesSvc)),
+	))
+	router.Handler(http.MethodGet, "/expenses/:"+idsRouteParam, route(
+		authorize(models.ReadExpensesPermission, staticParam(
+			idsRouteParam,
+			summaryPathSegment,
+			getSummary(cfg.ExpensesSvc),
+			staticParam(
+				idsRouteParam,
+				exportCSVPathSegment,
+				exportExpensesCSV(cfg.ExpensesSvc, cfg.WriteTimeout),
+				getExpensesByIDs(cfg.ExpensesSvc),
+			),
+		)),
+	))
+	router.Handler(http.MethodPost, "/expenses", routeWithBody(
+		authorize(models.WriteExpensesPermission, createExpense(cfg.ExpensesSvc)),
+	))
+	router.Handler(http.MethodPost, "/expenses/:"+idRouteParam, staticParam(
+		idRouteParam,
+		importPathSegment,
+		route(authorize(models.WriteExpensesPermission, importExpenses(cfg.ExpensesSvc))),
+		staticParam(
+			idRouteParam,
+			batchPathSegment,
+			routeWithBody(authorize(models.WriteExpensesPermission, batchExpenses(cfg.ExpensesSvc))),
+			route(NotFound()),
+		),
+	))
+	router.Handler(http.MethodPatch, "/expenses/:"+idRouteParam, routeWithBody(
+		authorize(models.WriteExpensesPermission, updateExpense(cfg.ExpensesSvc)),
+	))
+	// GET and DELETE expense routes share the ids wildcard, since httprouter allows a single wildcard name per path segment
+	router.Handler(http.MethodDelete, "/expenses/:"+idsRouteParam, route(
+		authorize(models.WriteExpensesPermission, deleteExpense(cfg.ExpensesSvc)),
+	))
+	router.Handler(http.MethodGet, "/expenses/:"+idsRouteParam+"/attachments", route(
+		authorize(models.ReadExpensesPermission, getAttachments(cfg.AttachmentsSvc)),
+	))
+	router.Handler(http.MethodGet, "/expenses/:"+idsRouteParam+"/attachments/:"+attachmentIDRouteParam, route(
+		authorize(models.ReadExpensesPermission, getAttachment(cfg.AttachmentsSvc)),
+	))
+	router.Handler(http.MethodPost, "/expenses/:"+idRouteParam+"/attachments", routeWithUpload(
+		authorize(models.WriteExpensesPermission, createAttachment(cfg.AttachmentsSvc)),
+	))
+	router.Handler(http.MethodDelete, "/expenses/:"+idsRouteParam+"/attachments/:"+attachmentIDRouteParam, route(
+		authorize(models.WriteExpensesPermission, deleteAttachment(cfg.AttachmentsSvc)),
+	))
+	router.Handler(http.MethodGet, "/currencies", route(getCurrencies()))
+	router.Handler(http.MethodGet, "/tags", route(
+		authorize(models.ReadExpensesPermission, getTags(cfg.ExpensesSvc)),
+	))
+	router.Handler(http.MethodGet, "/budgets", route(
+		authorize(models.ReadExpensesPermission, getBudgets(cfg.BudgetsSvc)),
+	))
+	router.Handler(http.MethodGet, "/budgets/:"+idRouteParam, route(
+		authorize(models.ReadExpensesPermission, getBudget(cfg.BudgetsSvc)),
+	))
+	router.Handler(http.MethodGet, "/budgets/:"+idRouteParam+"/status", route(
+		authorize(models.ReadExpensesPermission, getBudgetStatus(cfg.BudgetsSvc)),
+	))
+	router.Handler(http.MethodGet, "/budgets/:"+idRouteParam+"/alerts", route(
+		authorize(models.ReadExpensesPermission, getAlerts(cfg.AlertsSvc)),
+	))
+	router.Handler(http.MethodPost, "/budgets", routeWithBody(
+		authorize(models.WriteExpensesPermission, createBudget(cfg.BudgetsSvc)),
+	))
+	router.Handler(http.MethodPatch, "/budgets/:"+idRouteParam, routeWithBody(
+		authorize(models.WriteExpensesPermission, updateBudget(cfg.BudgetsSvc)),
+	))
+	router.Handler(http.MethodDelete, "/budgets/:"+idRouteParam, route(
+		authorize(models.WriteExpensesPermission, deleteBudget(cfg.BudgetsSvc)),
+	))
+	router.Handler(http.MethodGet, "/recurring-expenses", route(
+		authorize(models.ReadExpensesPermission, getRecurringExpenses(cfg.RecurringSvc)),
+	))
+	router.Handler(http.MethodGet, "/recurring-expenses/:"+idRouteParam, route(
+		authorize(models.ReadExpensesPermission, getRecurringExpense(cfg.RecurringSvc)),
+	))
+	router.Handler(http.MethodPost, "/recurring-expenses", routeWithBody(
+		authorize(models.WriteExpensesPermission, createRecurringExpense(cfg.RecurringSvc)),
+	))
+	router.Handler(http.MethodPatch, "/recurring-expenses/:"+idRouteParam, routeWithBody(
+		authorize(models.WriteExpensesPermission, updateRecurringExpense(cfg.RecurringSvc)),
+	))
+	router.Handler(http.MethodDelete, "/recurring-expenses/:"+idRouteParam, route(
+		authorize(models.WriteExpensesPermission, deleteRecurringExpense(cfg.RecurringSvc)),
+	))
+	router.Handler(http.MethodGet, "/rates", route(
+		authorize(models.ReadExpensesPermission, getRates(cfg.RatesSvc)),
+	))
+	router.Handler(http.MethodPut, "/rates/:"+dateRouteParam, routeWithBody(
+		authorize(models.ManageRatesPermission, putRates(cfg.RatesSvc)),
+	))
+	router.Handler(http.MethodPost, "/rates/import", route(
+		authorize(models.ManageRatesPermission, importRates(cfg.RatesSvc)),
+	))
+	router.Handler(http.MethodGet, "/categories", route(
+		authorize(models.ReadExpensesPermission, getCategories(cfg.CategoriesSvc)),
+	))
+	router.Handler(http.MethodGet, "/categories/:"+idRouteParam, route(
+		authorize(models.ReadExpensesPermission, getCategory(cfg.CategoriesSvc)),
+	))
+	router.Handler(http.MethodPost, "/categories", routeWithBody(
+		authorize(models.WriteExpensesPermission, createCategory(cfg.CategoriesSvc)),
+	))
+	router.Handler(http.MethodPatch, "/categories/:"+idRouteParam, routeWithBody(
+		authorize(models.WriteExpensesPermission, updateCategory(cfg.CategoriesSvc)),
+	))
+	router.Handler(http.MethodDelete, "/categories/:"+idRouteParam, route(
+		authorize(models.WriteExpensesPermission, deleteCategory(cfg.CategoriesSvc)),
+	))
 	router.Handler(http.MethodPost, "/login", routeWithBody(login(cfg.AuthSvc)))
 	router.Handler(http.MethodPost, "/signup", routeWithBody(signup(cfg.AuthSvc)))
-	router.Handler(http.MethodPost, "/logout", routeWithBody(logout(cfg.AuthSvc)))
+	router.Handler(http.MethodPost, "/logout", route(logout(cfg.AuthSvc)))
+	router.Handler(http.MethodPost, "/api-keys", routeWithBody(
+		authorize(models.ManageAPIKeysPermission, createAPIKey(cfg.AuthSvc)),
+	))
+	router.Handler(http.MethodGet, "/api-keys", route(
+		authorize(models.ManageAPIKeysPermission, getAPIKeys(cfg.AuthSvc)),
+	))
+	router.Handler(http.MethodDelete, "/api-keys/:"+idRouteParam, route(
+		authorize(models.ManageAPIKeysPermission, deleteAPIKey(cfg.AuthSvc)),
+	))
+	router.Handler(http.MethodPatch, "/users/:"+idRouteParam, routeWithBody(
+		authorize(models.ManageUsersPermission, updateUserRole(cfg.AuthSvc)),
+	))
 	router.NotFound = route(NotFound())
 
 	return router
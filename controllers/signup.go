@@ -2,13 +2,40 @@ package controllers
 
 import (
 	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
 )
 
 type signupper interface {
-	Signup()
+	Signup(models.SignupRequest) (models.User, error)
 }
 
 func signup(service signupper) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.SignupRequest
+		err := parseBody(r, &req)
+		if err != nil {
+			logging.Logger.Error("could not unmarshal signup body", zap.Error(err))
+			transport.SendHTTPError(w, err)
+			return
+		}
+		if err = req.Validate(); err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		user, err := service.Signup(req)
+		if err != nil {
+			logging.Logger.Debug("could not sign up user", zap.Error(err))
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		logging.Logger.Info("successfully signed up user")
+		transport.SendJSON(w, http.StatusCreated, user)
 	})
 }
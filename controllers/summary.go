@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
+)
+
+const (
+	summaryPathSegment = "summary"
+	groupByQueryParam  = "group_by"
+)
+
+type expensesSummarizer interface {
+	Summarize(models.SummaryRequest) ([]models.SummaryBucket, error)
+}
+
+type getSummaryResponse struct {
+	GroupBy   string                 `json:"group_by"`
+	ConvertTo string                 `json:"convert_to,omitempty"`
+	Items     []models.SummaryBucket `json:"items"`
+}
+
+func getSummary(service expensesSummarizer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		from, err := parseTimeQueryParam(r, fromQueryParam, false)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		to, err := parseTimeQueryParam(r, toQueryParam, true)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		req := models.SummaryRequest{
+			UserID:    callerID(r),
+			AllUsers:  callerCan(r, models.ReadAllExpensesPermission),
+			GroupBy:   strings.ToLower(strings.TrimSpace(r.URL.Query().Get(groupByQueryParam))),
+			From:      from,
+			To:        to,
+			ConvertTo: strings.ToUpper(strings.TrimSpace(r.URL.Query().Get(convertToParam))),
+		}
+		if req.GroupBy == "" {
+			req.GroupBy = models.MonthSummaryGroup
+		}
+		if err = req.Validate(); err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+
+		buckets, err := service.Summarize(req)
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		res := getSummaryResponse{
+			GroupBy:   req.GroupBy,
+			ConvertTo: req.ConvertTo,
+			Items:     buckets,
+		}
+		transport.SendJSON(w, http.StatusOK, res)
+	})
+}
+
+// staticParam serves a given handler when a route param equals a static path segment, and the fallback otherwise.
+// The router does not allow static path segments next to route params, such as /expenses/summary and /expenses/:ids
+func staticParam(paramName, segment string, h, fallback http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if routeParam(r, paramName) == segment {
+			h.ServeHTTP(w, r)
+			return
+		}
+		fallback.ServeHTTP(w, r)
+	})
+}
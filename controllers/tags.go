@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
+)
+
+type tagsGetter interface {
+	GetTags(userID string) ([]models.TagUsage, error)
+}
+
+type getTagsResponse struct {
+	Items []models.TagUsage `json:"items"`
+}
+
+func getTags(service tagsGetter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tags, err := service.GetTags(callerID(r))
+		if err != nil {
+			transport.SendHTTPError(w, err)
+			return
+		}
+		transport.SendJSON(w, http.StatusOK, getTagsResponse{Items: tags})
+	})
+}
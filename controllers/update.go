@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"net/http"
+	"strings"
 
 	"go.uber.org/zap"
 
@@ -11,7 +12,7 @@ import (
 )
 
 type expenseUpdater interface {
-	UpdateExpense(models.UpdateExpenseRequest) error
+	UpdateExpense(models.UpdateExpenseRequest) (models.Expense, error)
 }
 
 func updateExpense(service expenseUpdater) http.Handler {
@@ -34,13 +35,31 @@ func updateExpense(service expenseUpdater) http.Handler {
 			return
 		}
 		req.ID = id
+		req.UserID = callerID(r)
 
-		err = service.UpdateExpense(req)
+		expense, err := service.UpdateExpense(req)
 		if err != nil {
 			transport.SendHTTPError(w, err)
 			return
 		}
 		logging.Logger.Info("successfully updated the expense")
+		if prefersRepresentation(r) {
+			w.Header().Set(models.PreferenceAppliedHeader, models.ReturnRepresentationPreference)
+			transport.SendJSON(w, http.StatusOK, expense)
+			return
+		}
 		w.WriteHeader(http.StatusNoContent)
 	})
 }
+
+// prefersRepresentation checks whether the client asked for the resource in the response via the Prefer header
+func prefersRepresentation(r *http.Request) bool {
+	for _, header := range r.Header.Values(models.PreferHeader) {
+		for _, preference := range strings.Split(header, ",") {
+			if strings.EqualFold(strings.TrimSpace(preference), models.ReturnRepresentationPreference) {
+				return true
+			}
+		}
+	}
+	return false
+}
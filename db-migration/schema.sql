@@ -1,9 +1,140 @@
 CREATE TABLE IF NOT EXISTS `expenses`(
     `id` CHAR(36) UNIQUE NOT NULL,
-    `price` FLOAT NOT NULL,
+    `owner_id` CHAR(36) NOT NULL,
+    `price` DECIMAL(19, 4) NOT NULL,
     `title` VARCHAR (500) NOT NULL,
-    `currency` ENUM('USD', 'EUR', 'GBP', 'MDL') NOT NULL DEFAULT 'USD',
+    `currency` CHAR(3) NOT NULL,
+    `category_id` VARCHAR (36) NOT NULL DEFAULT '',
+    `source_id` VARCHAR (100) NULL,
+    `transaction_id` VARCHAR (255) NULL,
+    `posted_at` DATETIME NULL,
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `modified_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE (source_id),
+    INDEX (owner_id),
+    INDEX (owner_id, category_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `users`(
+    `id` CHAR(36) UNIQUE NOT NULL,
+    `email` VARCHAR (255) UNIQUE NOT NULL,
+    `password_hash` VARCHAR (255) NOT NULL,
+    `role` ENUM('admin', 'member', 'read_only') NOT NULL DEFAULT 'member',
     `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     `modified_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     PRIMARY KEY (id)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `sessions`(
+    `id` CHAR(64) UNIQUE NOT NULL,
+    `user_id` CHAR(36) NOT NULL,
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `expires_at` DATETIME NOT NULL,
+    PRIMARY KEY (id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `api_keys`(
+    `id` CHAR(36) UNIQUE NOT NULL,
+    `user_id` CHAR(36) NOT NULL,
+    `name` VARCHAR (255) NOT NULL,
+    `prefix` VARCHAR (16) NOT NULL,
+    `hash` CHAR(64) UNIQUE NOT NULL,
+    `scope` ENUM('read_only', 'read_write') NOT NULL DEFAULT 'read_only',
+    `expires_at` DATETIME NULL,
+    `last_used_at` DATETIME NULL,
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `categories`(
+    `id` CHAR(36) UNIQUE NOT NULL,
+    `owner_id` CHAR(36) NOT NULL,
+    `name` VARCHAR (100) NOT NULL,
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `modified_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE (owner_id, name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `expense_tags`(
+    `expense_id` CHAR(36) NOT NULL,
+    `tag` VARCHAR (50) NOT NULL,
+    PRIMARY KEY (expense_id, tag),
+    INDEX (tag),
+    FOREIGN KEY (expense_id) REFERENCES expenses(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `exchange_rates`(
+    `date` DATE NOT NULL,
+    `base` CHAR(3) NOT NULL,
+    `currency` CHAR(3) NOT NULL,
+    `rate` DECIMAL(24, 10) NOT NULL,
+    PRIMARY KEY (date, currency)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `budgets`(
+    `id` CHAR(36) UNIQUE NOT NULL,
+    `owner_id` CHAR(36) NOT NULL,
+    `amount` DECIMAL(19, 4) NOT NULL,
+    `currency` CHAR(3) NOT NULL,
+    `period` ENUM('weekly', 'monthly', 'yearly') NOT NULL DEFAULT 'monthly',
+    `category_id` VARCHAR (36) NOT NULL DEFAULT '',
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `modified_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX (owner_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `budget_alerts`(
+    `id` CHAR(36) UNIQUE NOT NULL,
+    `budget_id` CHAR(36) NOT NULL,
+    `owner_id` CHAR(36) NOT NULL,
+    `threshold` SMALLINT NOT NULL,
+    `period_start` DATE NOT NULL,
+    `spent` DECIMAL(19, 4) NOT NULL,
+    `budget_amount` DECIMAL(19, 4) NOT NULL,
+    `currency` CHAR(3) NOT NULL,
+    `percent_used` DECIMAL(10, 2) NOT NULL,
+    `status` ENUM('pending', 'delivered', 'failed', 'skipped') NOT NULL DEFAULT 'pending',
+    `attempts` INT NOT NULL DEFAULT 0,
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE (budget_id, period_start, threshold),
+    FOREIGN KEY (budget_id) REFERENCES budgets(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `recurring_expenses`(
+    `id` CHAR(36) UNIQUE NOT NULL,
+    `owner_id` CHAR(36) NOT NULL,
+    `title` VARCHAR (500) NOT NULL,
+    `amount` DECIMAL(19, 4) NOT NULL,
+    `currency` CHAR(3) NOT NULL,
+    `category_id` VARCHAR (36) NOT NULL DEFAULT '',
+    `tags` VARCHAR (1000) NOT NULL DEFAULT '',
+    `frequency` ENUM('daily', 'weekly', 'monthly', 'yearly') NOT NULL,
+    `schedule_interval` INT NOT NULL DEFAULT 1,
+    `month_day` TINYINT NOT NULL DEFAULT 0,
+    `start_date` DATE NOT NULL,
+    `end_date` DATE NULL,
+    `last_run` DATE NULL,
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `modified_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX (owner_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `expense_attachments`(
+    `id` CHAR(36) UNIQUE NOT NULL,
+    `expense_id` CHAR(36) NOT NULL,
+    `owner_id` CHAR(36) NOT NULL,
+    `filename` VARCHAR (255) NOT NULL,
+    `content_type` VARCHAR (100) NOT NULL,
+    `size` BIGINT NOT NULL,
+    `sha256` CHAR(64) NOT NULL,
+    `created_at` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    INDEX (owner_id, expense_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
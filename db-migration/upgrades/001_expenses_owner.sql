@@ -0,0 +1,13 @@
+-- Scopes the expenses of databases created before expenses had owners.
+-- Existing expenses get assigned to the user of @legacy_owner_email, which must sign up first, for instance:
+--   SET @legacy_owner_email = 'admin@example.com';
+ALTER TABLE `expenses`
+    ADD COLUMN IF NOT EXISTS `owner_id` CHAR(36) NOT NULL DEFAULT '' AFTER `id`,
+    ADD INDEX IF NOT EXISTS `owner_id` (`owner_id`);
+
+UPDATE `expenses`
+SET `owner_id` = (SELECT `id` FROM `users` WHERE `email` = LOWER(TRIM(@legacy_owner_email)))
+WHERE `owner_id` = ''
+  AND EXISTS (SELECT 1 FROM `users` WHERE `email` = LOWER(TRIM(@legacy_owner_email)));
+
+ALTER TABLE `expenses` ALTER COLUMN `owner_id` DROP DEFAULT;
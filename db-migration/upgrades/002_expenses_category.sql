@@ -0,0 +1,4 @@
+-- Adds the category of expenses to databases created before expenses had categories.
+ALTER TABLE `expenses`
+    ADD COLUMN IF NOT EXISTS `category_id` VARCHAR (36) NOT NULL DEFAULT '' AFTER `currency`,
+    ADD INDEX IF NOT EXISTS `owner_id_2` (`owner_id`, `category_id`);
@@ -0,0 +1,9 @@
+-- Stores the prices of databases created before prices became fixed-point money as exact decimals.
+-- FLOAT prices only approximate their amounts, so the converted amounts get rounded to the 2 minor unit digits
+-- of the currencies supported back then.
+ALTER TABLE `expenses` MODIFY `price` DECIMAL(19, 4) NOT NULL;
+
+UPDATE `expenses`
+SET `price` = ROUND(`price`, 2)
+WHERE `price` <> ROUND(`price`, 2)
+  AND `currency` IN ('USD', 'EUR', 'GBP', 'MDL');
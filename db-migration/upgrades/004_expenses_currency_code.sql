@@ -0,0 +1,3 @@
+-- Allows any ISO 4217 currency of the configured currency catalogue in databases created before the catalogue,
+-- whose currency column only allowed a fixed list of currencies.
+ALTER TABLE `expenses` MODIFY `currency` CHAR(3) NOT NULL;
@@ -0,0 +1,5 @@
+-- Adds the source of expenses to databases created before recurring expenses,
+-- the source ID keeps every occurrence of a recurring expense from being created twice.
+ALTER TABLE `expenses`
+    ADD COLUMN IF NOT EXISTS `source_id` VARCHAR (100) NULL AFTER `category_id`,
+    ADD UNIQUE INDEX IF NOT EXISTS `source_id` (`source_id`);
@@ -0,0 +1,4 @@
+-- Adds the bank transaction of expenses to databases created before bank statements could be imported.
+ALTER TABLE `expenses`
+    ADD COLUMN IF NOT EXISTS `transaction_id` VARCHAR (255) NULL AFTER `source_id`,
+    ADD COLUMN IF NOT EXISTS `posted_at` DATETIME NULL AFTER `transaction_id`;
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
+)
+
+const bearerPrefix = "Bearer "
+
+// Authenticator represents the feature of resolving callers out of their credentials
+type Authenticator interface {
+	Authenticate(token string) (models.User, error)
+	AuthenticateAPIKey(key string) (models.User, string, error)
+}
+
+// Authenticate resolves the caller out of the bearer token or the API key and stores it in the request context.
+// Requests to any of the public routes are let through without credentials, a route ending with * matches by prefix
+func Authenticate(auth Authenticator, publicRoutes []string) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isPublicRoute(r.URL.Path, publicRoutes) {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			var user models.User
+			var scope string
+			var err error
+			token, hasToken := BearerToken(r)
+			apiKey := strings.TrimSpace(r.Header.Get(models.APIKeyHeader))
+			switch {
+			case hasToken:
+				user, err = auth.Authenticate(token)
+			case apiKey != "":
+				user, scope, err = auth.AuthenticateAPIKey(apiKey)
+			default:
+				err = models.UnauthorizedError{
+					Message: "missing bearer token or api key",
+				}
+			}
+			if err != nil {
+				logging.Logger.Debug("could not authenticate request", zap.Error(err))
+				if _, ok := err.(models.UnauthorizedError); ok {
+					w.Header().Set("WWW-Authenticate", "Bearer")
+				}
+				transport.SendHTTPError(w, err)
+				return
+			}
+
+			ctx := models.ContextWithUser(r.Context(), user)
+			ctx = models.ContextWithScope(ctx, scope)
+			h.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// BearerToken extracts the token from the Authorization: Bearer <token> request header
+func BearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get(models.AuthorizationHeader)
+	if !strings.HasPrefix(header, bearerPrefix) {
+		return "", false
+	}
+	token := strings.TrimSpace(header[len(bearerPrefix):])
+	return token, token != ""
+}
+
+func isPublicRoute(path string, publicRoutes []string) bool {
+	for _, route := range publicRoutes {
+		if strings.HasSuffix(route, "*") && strings.HasPrefix(path, strings.TrimSuffix(route, "*")) {
+			return true
+		}
+		if path == route {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+func TestMain(m *testing.M) {
+	logging.Logger = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// fakeAuthenticator represents an authenticator of a single session token and a single API key
+type fakeAuthenticator struct{}
+
+func (fakeAuthenticator) Authenticate(token string) (models.User, error) {
+	if token != "session-token" {
+		return models.User{}, models.UnauthorizedError{Message: "invalid session token"}
+	}
+	return models.User{ID: "u1"}, nil
+}
+
+func (fakeAuthenticator) AuthenticateAPIKey(key string) (models.User, string, error) {
+	if key != "exp_key" {
+		return models.User{}, "", models.UnauthorizedError{Message: "invalid api key"}
+	}
+	return models.User{ID: "u2"}, models.ReadOnlyScope, nil
+}
+
+// caller represents the caller a request was let through with
+type caller struct {
+	served bool
+	user   models.User
+	scope  string
+}
+
+func authenticate(r *http.Request) (*httptest.ResponseRecorder, *caller) {
+	c := &caller{}
+	h := Authenticate(fakeAuthenticator{}, []string{"/login", "/docs/*"})(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c.served = true
+			c.user, _ = models.UserFromContext(r.Context())
+			c.scope = models.ScopeFromContext(r.Context())
+		}),
+	)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec, c
+}
+
+func TestAuthenticatePublicRoutes(t *testing.T) {
+	tests := []struct {
+		path   string
+		public bool
+	}{
+		{path: "/login", public: true},
+		{path: "/docs/", public: true},
+		{path: "/docs/openapi.yaml", public: true},
+		{path: "/login/other", public: false},
+		{path: "/docs", public: false},
+		{path: "/expenses", public: false},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			rec, c := authenticate(httptest.NewRequest(http.MethodGet, test.path, nil))
+			if c.served != test.public {
+				t.Fatalf("expected served: %v, got: %v with status: %d", test.public, c.served, rec.Code)
+			}
+		})
+	}
+}
+
+func TestAuthenticateRejectsMissingOrInvalidCredentials(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		value  string
+	}{
+		{name: "missing credentials"},
+		{name: "empty bearer token", header: models.AuthorizationHeader, value: "Bearer "},
+		{name: "invalid bearer token", header: models.AuthorizationHeader, value: "Bearer wrong"},
+		{name: "invalid api key", header: models.APIKeyHeader, value: "exp_wrong"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/expenses", nil)
+			if test.header != "" {
+				r.Header.Set(test.header, test.value)
+			}
+			rec, c := authenticate(r)
+			if c.served {
+				t.Fatal("expected the request not to be served")
+			}
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("expected status: %d, got: %d", http.StatusUnauthorized, rec.Code)
+			}
+			if got := rec.Header().Get("WWW-Authenticate"); got != "Bearer" {
+				t.Fatalf("expected the WWW-Authenticate header, got: %q", got)
+			}
+		})
+	}
+}
+
+func TestAuthenticateStoresTheCaller(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/expenses", nil)
+	r.Header.Set(models.AuthorizationHeader, "Bearer session-token")
+	if _, c := authenticate(r); !c.served || c.user.ID != "u1" || c.scope != "" {
+		t.Fatalf("expected the session user without a scope, got: %+v", c)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/expenses", nil)
+	r.Header.Set(models.APIKeyHeader, "exp_key")
+	if _, c := authenticate(r); !c.served || c.user.ID != "u2" || c.scope != models.ReadOnlyScope {
+		t.Fatalf("expected the api key user along with the key scope, got: %+v", c)
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/transport"
+)
+
+// MultipartBody rejects endpoints that have missing multipart/form-data content type, such as file uploads
+func MultipartBody(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get(models.ContentType))
+		if err != nil || mediaType != models.MultipartFormDataType {
+			err := models.FormatValidationError{
+				Message: "missing multipart body or multipart/form-data content-type",
+			}
+			logging.Logger.Error("missing " + models.MultipartFormDataType + " content type")
+			transport.SendHTTPError(w, err)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
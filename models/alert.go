@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	// PendingAlertStatus represents alerts whose webhook delivery is still in progress
+	PendingAlertStatus = "pending"
+	// DeliveredAlertStatus represents alerts delivered to every configured webhook
+	DeliveredAlertStatus = "delivered"
+	// FailedAlertStatus represents alerts that could not be delivered to some webhook after every retry
+	FailedAlertStatus = "failed"
+	// SkippedAlertStatus represents alerts that were not delivered, since no webhooks are configured
+	SkippedAlertStatus = "skipped"
+
+	// BudgetThresholdEvent represents the webhook event sent when spending crosses a budget threshold
+	BudgetThresholdEvent = "budget.threshold_crossed"
+)
+
+// BudgetAlertThresholds represents the percentages of a budget that fire an alert once per period when crossed
+var BudgetAlertThresholds = []int{80, 100}
+
+// BudgetAlert represents the alert fired when the spending of a budget period crosses a threshold
+type BudgetAlert struct {
+	ID           string    `json:"id" db:"id"`
+	BudgetID     string    `json:"budget_id" db:"budget_id"`
+	OwnerID      string    `json:"owner_id" db:"owner_id"`
+	Threshold    int       `json:"threshold" db:"threshold"`
+	PeriodStart  time.Time `json:"period_start" db:"period_start"`
+	Spent        Money     `json:"spent" db:"-"`
+	BudgetAmount Money     `json:"budget_amount" db:"-"`
+	PercentUsed  float64   `json:"percent_used" db:"percent_used"`
+	Status       string    `json:"status" db:"status"`
+	Attempts     int       `json:"attempts" db:"attempts"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// WebhookEvent represents the body of webhook requests
+type WebhookEvent struct {
+	Event     string      `json:"event"`
+	Data      interface{} `json:"data"`
+	CreatedAt time.Time   `json:"created_at"`
+}
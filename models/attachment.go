@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// AttachmentContentTypes represents the content types of receipts that may be attached to expenses
+var AttachmentContentTypes = []string{"application/pdf", "image/gif", "image/jpeg", "image/png", "image/webp"}
+
+// Attachment represents the metadata of a file attached to an expense, such as a receipt image or PDF.
+// The content itself is kept in the blob storage under the attachment ID
+type Attachment struct {
+	ID          string    `json:"id" db:"id"`
+	ExpenseID   string    `json:"expense_id" db:"expense_id"`
+	OwnerID     string    `json:"owner_id" db:"owner_id"`
+	Filename    string    `json:"filename" db:"filename"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	Size        int64     `json:"size" db:"size"`
+	SHA256      string    `json:"sha256" db:"sha256"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// IsAttachmentContentType checks whether files of a given content type may be attached to expenses
+func IsAttachmentContentType(contentType string) bool {
+	for _, t := range AttachmentContentTypes {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// CreateBatchOperation represents the batch operation that creates an expense
+	CreateBatchOperation = "create"
+	// UpdateBatchOperation represents the batch operation that updates an expense
+	UpdateBatchOperation = "update"
+	// DeleteBatchOperation represents the batch operation that deletes an expense
+	DeleteBatchOperation = "delete"
+)
+
+// BatchExpensesRequest represents http request for applying a batch of expense operations in a single transaction.
+// Batches are all or nothing unless they are best effort, which applies every operation that does not fail
+type BatchExpensesRequest struct {
+	UserID     string           `json:"-"`
+	BestEffort bool             `json:"best_effort"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// Validate validates the batch expenses incoming request, the operations get validated one by one
+func (r BatchExpensesRequest) Validate() error {
+	if len(r.Operations) == 0 {
+		return DataValidationError{Message: "operations should not be empty"}
+	}
+	return nil
+}
+
+// BatchOperation represents a single operation of a batch, the expense of updates only holds the changed fields
+type BatchOperation struct {
+	Op      string        `json:"op"`
+	ID      string        `json:"id,omitempty"`
+	Expense *BatchExpense `json:"expense,omitempty"`
+}
+
+// BatchExpense represents the expense fields of create and update batch operations
+type BatchExpense struct {
+	Title      string   `json:"title"`
+	Price      *Money   `json:"price"`
+	CategoryID *string  `json:"category_id"`
+	Tags       []string `json:"tags"`
+}
+
+// UnmarshalJSON decodes the expense fields, the price is decoded the same way as the expense price
+func (e *BatchExpense) UnmarshalJSON(data []byte) error {
+	type expense BatchExpense
+	var raw struct {
+		expense
+		Price    json.RawMessage `json:"price"`
+		Currency string          `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	price, err := priceJSON{Price: raw.Price, Currency: raw.Currency}.money()
+	if err != nil {
+		return err
+	}
+	*e = BatchExpense(raw.expense)
+	e.Price = price
+	return nil
+}
+
+// Validate validates a single batch operation
+func (o BatchOperation) Validate() error {
+	switch o.Op {
+	case CreateBatchOperation:
+		if o.Expense == nil {
+			return DataValidationError{Message: "expense should not be empty"}
+		}
+		return o.CreateRequest("").Validate()
+	case UpdateBatchOperation, DeleteBatchOperation:
+		if _, err := uuid.Parse(o.ID); err != nil {
+			return DataValidationError{Message: fmt.Sprintf("invalid expense id: %s", o.ID)}
+		}
+		if o.Op == DeleteBatchOperation {
+			return nil
+		}
+		if o.Expense == nil {
+			return DataValidationError{Message: "expense should not be empty"}
+		}
+		return o.UpdateRequest("").Validate()
+	}
+	return DataValidationError{
+		Message: "op must be one of: " + CreateBatchOperation + "," + UpdateBatchOperation + "," + DeleteBatchOperation,
+	}
+}
+
+// CreateRequest returns the create expense request of a create operation for a given user
+func (o BatchOperation) CreateRequest(userID string) CreateExpenseRequest {
+	req := CreateExpenseRequest{UserID: userID}
+	if o.Expense != nil {
+		req.Title, req.Tags = o.Expense.Title, o.Expense.Tags
+		if o.Expense.Price != nil {
+			req.Price = *o.Expense.Price
+		}
+		if o.Expense.CategoryID != nil {
+			req.CategoryID = *o.Expense.CategoryID
+		}
+	}
+	return req
+}
+
+// UpdateRequest returns the update expense request of an update operation for a given user
+func (o BatchOperation) UpdateRequest(userID string) UpdateExpenseRequest {
+	req := UpdateExpenseRequest{ID: o.ID, UserID: userID}
+	if o.Expense != nil {
+		req.Title, req.Price, req.CategoryID, req.Tags = o.Expense.Title, o.Expense.Price, o.Expense.CategoryID, o.Expense.Tags
+	}
+	return req
+}
+
+// BatchResult represents the outcome of a batch operation, operations that failed carry the reason
+type BatchResult struct {
+	Op      string
+	ID      string
+	Expense *Expense
+	Err     error
+}
+
+// BatchReport represents the outcome of a batch, operation by operation.
+// Nothing was applied when the batch was not committed, since either all or none of its operations get applied
+type BatchReport struct {
+	BestEffort bool
+	Committed  bool
+	Results    []BatchResult
+}
+
+// DeleteExpensesReport represents the outcome of deleting a list of expenses, IDs of missing expenses are not found
+type DeleteExpensesReport struct {
+	Deleted  []string `json:"deleted"`
+	NotFound []string `json:"not_found"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestBatchOperationValidate(t *testing.T) {
+	id := "9311744c-3746-3502-84c9-d06e8b5ea2d6"
+	price := Money{Amount: 35000, Currency: "EUR"}
+	tests := []struct {
+		name    string
+		op      BatchOperation
+		wantErr bool
+	}{
+		{
+			name: "create",
+			op:   BatchOperation{Op: CreateBatchOperation, Expense: &BatchExpense{Title: "coffee", Price: &price}},
+		},
+		{
+			name:    "create without price",
+			op:      BatchOperation{Op: CreateBatchOperation, Expense: &BatchExpense{Title: "coffee"}},
+			wantErr: true,
+		},
+		{
+			name:    "create without expense",
+			op:      BatchOperation{Op: CreateBatchOperation},
+			wantErr: true,
+		},
+		{
+			name: "partial update",
+			op:   BatchOperation{Op: UpdateBatchOperation, ID: id, Expense: &BatchExpense{Title: "tea"}},
+		},
+		{
+			name:    "update without id",
+			op:      BatchOperation{Op: UpdateBatchOperation, Expense: &BatchExpense{Title: "tea"}},
+			wantErr: true,
+		},
+		{
+			name: "delete",
+			op:   BatchOperation{Op: DeleteBatchOperation, ID: id},
+		},
+		{
+			name:    "unknown op",
+			op:      BatchOperation{Op: "upsert", ID: id},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.op.Validate()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("expected error: %v, got: %v", test.wantErr, err)
+			}
+			if _, ok := err.(DataValidationError); err != nil && !ok {
+				t.Fatalf("expected a data validation error, got: %T", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	// WeeklyBudgetPeriod represents the budgets of ISO weeks, starting on Monday
+	WeeklyBudgetPeriod = "weekly"
+	// MonthlyBudgetPeriod represents the budgets of calendar months
+	MonthlyBudgetPeriod = "monthly"
+	// YearlyBudgetPeriod represents the budgets of calendar years
+	YearlyBudgetPeriod = "yearly"
+)
+
+var budgetPeriods = []string{WeeklyBudgetPeriod, MonthlyBudgetPeriod, YearlyBudgetPeriod}
+
+// Budget represents the spending budget model of a period, either overall or of a single category
+type Budget struct {
+	ID         string    `json:"id" db:"id"`
+	OwnerID    string    `json:"owner_id" db:"owner_id"`
+	Amount     Money     `json:"amount" db:"-"`
+	Period     string    `json:"period" db:"period"`
+	CategoryID string    `json:"category_id,omitempty" db:"category_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	ModifiedAt time.Time `json:"modified_at" db:"modified_at"`
+}
+
+// BudgetStatus represents how much of a budget was spent within the period of a given day
+type BudgetStatus struct {
+	Budget      Budget    `json:"budget"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	Spent       Money     `json:"spent"`
+	Remaining   Money     `json:"remaining"`
+	PercentUsed float64   `json:"percent_used"`
+	OverBudget  bool      `json:"over_budget"`
+}
+
+// NewBudgetStatus calculates the status of a budget out of the amount spent within a given period
+func NewBudgetStatus(budget Budget, start, end time.Time, spent Money) BudgetStatus {
+	status := BudgetStatus{
+		Budget:      budget,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Spent:       spent,
+		Remaining:   Money{Amount: budget.Amount.Amount - spent.Amount, Currency: budget.Amount.Currency},
+		OverBudget:  spent.Amount > budget.Amount.Amount,
+	}
+	if budget.Amount.Amount > 0 {
+		percent := float64(spent.Amount) * 100 / float64(budget.Amount.Amount)
+		status.PercentUsed = math.Round(percent*100) / 100
+	}
+	return status
+}
+
+// BudgetPeriodRange returns the inclusive bounds of the budget period a given time falls into
+func BudgetPeriodRange(period string, t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	var start, next time.Time
+	switch period {
+	case WeeklyBudgetPeriod:
+		start = day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		next = start.AddDate(0, 0, 7)
+	case YearlyBudgetPeriod:
+		start = time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		next = start.AddDate(1, 0, 0)
+	default:
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		next = start.AddDate(0, 1, 0)
+	}
+	return start, next.Add(-time.Nanosecond)
+}
+
+func validateBudgetPeriod(period string) error {
+	for _, p := range budgetPeriods {
+		if period == p {
+			return nil
+		}
+	}
+	return DataValidationError{Message: "period must be one of: " + strings.Join(budgetPeriods, ",")}
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBudgetPeriodRange(t *testing.T) {
+	at := time.Date(2020, time.March, 1, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		period    string
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			period:    WeeklyBudgetPeriod,
+			wantStart: time.Date(2020, time.February, 24, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2020, time.March, 1, 23, 59, 59, 999999999, time.UTC),
+		},
+		{
+			period:    MonthlyBudgetPeriod,
+			wantStart: time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2020, time.March, 31, 23, 59, 59, 999999999, time.UTC),
+		},
+		{
+			period:    YearlyBudgetPeriod,
+			wantStart: time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2020, time.December, 31, 23, 59, 59, 999999999, time.UTC),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.period, func(t *testing.T) {
+			start, end := BudgetPeriodRange(test.period, at)
+			if !start.Equal(test.wantStart) || !end.Equal(test.wantEnd) {
+				t.Fatalf("expected: %s - %s, got: %s - %s", test.wantStart, test.wantEnd, start, end)
+			}
+		})
+	}
+}
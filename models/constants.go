@@ -5,8 +5,66 @@ const (
 	ContentType = "Content-Type"
 	// ApplicationJSONType represents the application/json header value
 	ApplicationJSONType = "application/json"
+	// TextCSVType represents the text/csv header value
+	TextCSVType = "text/csv"
+	// ApplicationXMLType represents the application/xml header value
+	ApplicationXMLType = "application/xml"
+	// TextXMLType represents the text/xml header value
+	TextXMLType = "text/xml"
+	// ApplicationOFXType represents the application/x-ofx header value
+	ApplicationOFXType = "application/x-ofx"
+	// ApplicationQFXType represents the application/vnd.intu.qfx header value of Quicken OFX statements
+	ApplicationQFXType = "application/vnd.intu.qfx"
+	// ApplicationQIFType represents the application/qif header value
+	ApplicationQIFType = "application/qif"
+	// ApplicationXQIFType represents the application/x-qif header value
+	ApplicationXQIFType = "application/x-qif"
+	// MultipartFormDataType represents the multipart/form-data header value
+	MultipartFormDataType = "multipart/form-data"
+	// ContentLengthHeader represents the Content-Length header key
+	ContentLengthHeader = "Content-Length"
+	// ContentDispositionHeader represents the Content-Disposition header key
+	ContentDispositionHeader = "Content-Disposition"
+	// ContentTypeOptionsHeader represents the X-Content-Type-Options header key
+	ContentTypeOptionsHeader = "X-Content-Type-Options"
+	// NoSniffContentTypeOption represents the X-Content-Type-Options header value that disables content type sniffing
+	NoSniffContentTypeOption = "nosniff"
+	// ETagHeader represents the ETag header key
+	ETagHeader = "ETag"
+	// AuthorizationHeader represents the Authorization header key
+	AuthorizationHeader = "Authorization"
+	// APIKeyHeader represents the X-API-Key header key
+	APIKeyHeader = "X-API-Key"
+	// LocationHeader represents the Location header key
+	LocationHeader = "Location"
+	// WebhookSignatureHeader represents the header key of the HMAC-SHA256 signature of webhook request bodies
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	// PreferHeader represents the Prefer header key
+	PreferHeader = "Prefer"
+	// PreferenceAppliedHeader represents the Preference-Applied header key
+	PreferenceAppliedHeader = "Preference-Applied"
+	// ReturnRepresentationPreference represents the Prefer header value that asks for the resource in the response
+	ReturnRepresentationPreference = "return=representation"
 	// MariaDBType represents MariaDB app db type
 	MariaDBType = "mariadb"
 	// BoltDBType represents BoltDB app db type
 	BoltDBType = "boltdb"
+	// AdminRole represents the user role that is allowed to do everything
+	AdminRole = "admin"
+	// MemberRole represents the user role that manages own expenses
+	MemberRole = "member"
+	// ReadOnlyRole represents the user role that is only allowed to read expenses
+	ReadOnlyRole = "read_only"
+	// AnyTagMatch represents the tag filter that matches expenses with at least one of the tags
+	AnyTagMatch = "any"
+	// AllTagMatch represents the tag filter that matches expenses with all of the tags
+	AllTagMatch = "all"
+	// ReadOnlyScope represents the API key scope that only allows reading
+	ReadOnlyScope = "read_only"
+	// ReadWriteScope represents the API key scope that allows both reading and writing
+	ReadWriteScope = "read_write"
+	// CSVRatesFormat represents the ECB style CSV format of exchange rates files
+	CSVRatesFormat = "csv"
+	// XMLRatesFormat represents the ECB style XML format of exchange rates files
+	XMLRatesFormat = "xml"
 )
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"context"
+)
+
+type contextKey string
+
+const (
+	userContextKey  contextKey = "user"
+	scopeContextKey contextKey = "scope"
+)
+
+// ContextWithUser returns a copy of the given context that carries the authenticated user
+func ContextWithUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, userContextKey, user)
+}
+
+// UserFromContext retrieves the authenticated user from a given context
+func UserFromContext(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(userContextKey).(User)
+	return user, ok
+}
+
+// ContextWithScope returns a copy of the given context that carries the scope of the API key used by the caller
+func ContextWithScope(ctx context.Context, scope string) context.Context {
+	return context.WithValue(ctx, scopeContextKey, scope)
+}
+
+// ScopeFromContext retrieves the API key scope from a given context, callers without API keys have no scope
+func ScopeFromContext(ctx context.Context) string {
+	scope, _ := ctx.Value(scopeContextKey).(string)
+	return scope
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+var currencyCodeRegexp = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// DefaultCurrencies represents the currency catalogue used when the configuration does not provide one
+var DefaultCurrencies = []Currency{
+	{Code: "EUR", Symbol: "€", MinorUnits: 2},
+	{Code: "GBP", Symbol: "£", MinorUnits: 2},
+	{Code: "MDL", Symbol: "L", MinorUnits: 2},
+	{Code: "USD", Symbol: "$", MinorUnits: 2},
+}
+
+// currencies represents the catalogue of supported currencies, it is set once on application start
+var currencies = currencyIndex(DefaultCurrencies)
+
+// Currency represents an ISO 4217 currency
+type Currency struct {
+	Code       string `json:"code" mapstructure:"code"`
+	Symbol     string `json:"symbol" mapstructure:"symbol"`
+	MinorUnits int    `json:"minor_units" mapstructure:"minor_units"`
+}
+
+// SetCurrencies validates and replaces the catalogue of supported currencies
+func SetCurrencies(list []Currency) error {
+	if len(list) == 0 {
+		return fmt.Errorf("currency catalogue must not be empty")
+	}
+	index := currencyIndex(list)
+	if len(index) != len(list) {
+		return fmt.Errorf("currency catalogue must not contain duplicate codes")
+	}
+	for _, c := range list {
+		if !currencyCodeRegexp.MatchString(c.Code) {
+			return fmt.Errorf("invalid currency code: %s, expected an ISO 4217 code", c.Code)
+		}
+		if c.MinorUnits < 0 || c.MinorUnits > PriceScale {
+			return fmt.Errorf("invalid minor units of currency: %s, expected 0 to %d", c.Code, PriceScale)
+		}
+	}
+	currencies = index
+	return nil
+}
+
+// Currencies returns the catalogue of supported currencies sorted by code
+func Currencies() []Currency {
+	list := make([]Currency, 0, len(currencies))
+	for _, c := range currencies {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Code < list[j].Code
+	})
+	return list
+}
+
+// LookupCurrency finds a supported currency by a given ISO 4217 code
+func LookupCurrency(code string) (Currency, bool) {
+	c, ok := currencies[code]
+	return c, ok
+}
+
+func currencyIndex(list []Currency) map[string]Currency {
+	index := make(map[string]Currency, len(list))
+	for _, c := range list {
+		index[c.Code] = c
+	}
+	return index
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestSetCurrencies(t *testing.T) {
+	defer func() {
+		_ = SetCurrencies(DefaultCurrencies)
+	}()
+	tests := []struct {
+		name    string
+		list    []Currency
+		wantErr bool
+	}{
+		{
+			name:    "empty catalogue",
+			wantErr: true,
+		},
+		{
+			name:    "duplicate codes",
+			list:    []Currency{{Code: "USD", MinorUnits: 2}, {Code: "USD", MinorUnits: 2}},
+			wantErr: true,
+		},
+		{
+			name:    "lower case code",
+			list:    []Currency{{Code: "usd", MinorUnits: 2}},
+			wantErr: true,
+		},
+		{
+			name:    "more minor units than the price scale",
+			list:    []Currency{{Code: "USD", MinorUnits: PriceScale + 1}},
+			wantErr: true,
+		},
+		{
+			name: "valid catalogue",
+			list: []Currency{{Code: "JPY", Symbol: "¥"}, {Code: "BHD", MinorUnits: 3}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := SetCurrencies(test.list)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("expected error: %v, got: %v", test.wantErr, err)
+			}
+		})
+	}
+
+	if codes := Currencies(); len(codes) != 2 || codes[0].Code != "BHD" || codes[1].Code != "JPY" {
+		t.Fatalf("expected the valid catalogue sorted by code, got: %+v", codes)
+	}
+}
+
+func TestLookupCurrency(t *testing.T) {
+	defer func() {
+		_ = SetCurrencies(DefaultCurrencies)
+	}()
+	if err := SetCurrencies([]Currency{{Code: "JPY", Symbol: "¥"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if c, ok := LookupCurrency("JPY"); !ok || c.Symbol != "¥" || c.MinorUnits != 0 {
+		t.Fatalf("expected JPY to be supported, got: %+v", c)
+	}
+	if _, ok := LookupCurrency("USD"); ok {
+		t.Fatal("expected USD to be removed from the catalogue")
+	}
+}
+
+func TestStoredMoneyOutlivesCatalogueChanges(t *testing.T) {
+	defer func() {
+		_ = SetCurrencies(DefaultCurrencies)
+	}()
+	stored, err := ParseMoney("12.34", "USD")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err = SetCurrencies([]Currency{{Code: "USD"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	read, err := ParseStoredMoney(stored.String(), stored.Currency)
+	if err != nil || read != stored {
+		t.Fatalf("expected stored money: %+v to stay readable, got: %+v, error: %v", stored, read, err)
+	}
+	if err = read.Validate(); err == nil {
+		t.Fatal("expected new money to be validated against the minor units of the catalogue")
+	}
+	if err = SetCurrencies([]Currency{{Code: "EUR", MinorUnits: 2}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if read, err = ParseStoredMoney(stored.String(), stored.Currency); err != nil || read != stored {
+		t.Fatalf("expected stored money of removed currencies to stay readable, got: %+v, error: %v", read, err)
+	}
+}
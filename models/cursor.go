@@ -0,0 +1,84 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
+
+// Cursor represents an opaque position within a sorted expense listing
+type Cursor struct {
+	// Sort is the listing sort the cursor was issued for, the cursor is only valid for the very same sort
+	Sort []SortField
+	// Before makes the cursor point to the expenses placed right before Key instead of right after it
+	Before bool
+	// Key holds the ID and the sort field values of the expense the cursor points at
+	Key Expense
+}
+
+// cursorToken represents the wire format of a cursor, only the sort fields of the key get encoded
+type cursorToken struct {
+	Sort       string     `json:"s"`
+	Before     bool       `json:"b,omitempty"`
+	ID         string     `json:"id"`
+	Price      *Money     `json:"p,omitempty"`
+	Title      string     `json:"t,omitempty"`
+	CreatedAt  *time.Time `json:"c,omitempty"`
+	ModifiedAt *time.Time `json:"m,omitempty"`
+}
+
+// Encode encodes the cursor into an opaque URL safe token
+func (c Cursor) Encode() string {
+	token := cursorToken{
+		Sort:   EncodeSort(c.Sort),
+		Before: c.Before,
+		ID:     c.Key.ID,
+	}
+	for _, field := range c.Sort {
+		switch field.Field {
+		case PriceSortField:
+			token.Price = &c.Key.Price
+		case TitleSortField:
+			token.Title = c.Key.Title
+		case CreatedAtSortField:
+			token.CreatedAt = &c.Key.CreatedAt
+		case ModifiedAtSortField:
+			token.ModifiedAt = &c.Key.ModifiedAt
+		}
+	}
+	bs, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(bs)
+}
+
+// DecodeCursor decodes a cursor out of an opaque token created by Encode
+func DecodeCursor(token string) (Cursor, error) {
+	invalidCursorErr := DataValidationError{Message: "invalid cursor"}
+	bs, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, invalidCursorErr
+	}
+	var t cursorToken
+	if err = json.Unmarshal(bs, &t); err != nil || t.ID == "" {
+		return Cursor{}, invalidCursorErr
+	}
+	sort, err := ParseSort(t.Sort)
+	if err != nil || len(sort) == 0 {
+		return Cursor{}, invalidCursorErr
+	}
+
+	c := Cursor{
+		Sort:   sort,
+		Before: t.Before,
+		Key:    Expense{ID: t.ID, Title: t.Title},
+	}
+	if t.Price != nil {
+		c.Key.Price = *t.Price
+	}
+	if t.CreatedAt != nil {
+		c.Key.CreatedAt = *t.CreatedAt
+	}
+	if t.ModifiedAt != nil {
+		c.Key.ModifiedAt = *t.ModifiedAt
+	}
+	return c, nil
+}
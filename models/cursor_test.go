@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2021, 3, 1, 10, 30, 15, 0, time.UTC)
+	c := Cursor{
+		Sort:   []SortField{{Field: PriceSortField, Desc: true}, {Field: CreatedAtSortField}},
+		Before: true,
+		Key: Expense{
+			ID:        "e1",
+			Title:     "not encoded",
+			Price:     Money{Amount: 123400, Currency: "USD"},
+			CreatedAt: createdAt,
+		},
+	}
+
+	decoded, err := DecodeCursor(c.Encode())
+	if err != nil {
+		t.Fatalf("could not decode cursor: %v", err)
+	}
+	if !decoded.Before || EncodeSort(decoded.Sort) != EncodeSort(c.Sort) {
+		t.Fatalf("expected cursor: %+v, got: %+v", c, decoded)
+	}
+	if decoded.Key.ID != "e1" || decoded.Key.Price != c.Key.Price || !decoded.Key.CreatedAt.Equal(createdAt) {
+		t.Fatalf("expected key: %+v, got: %+v", c.Key, decoded.Key)
+	}
+	if decoded.Key.Title != "" {
+		t.Fatalf("expected title not to be encoded, got: %s", decoded.Key.Title)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	for _, token := range []string{"", "not-base64!", "e30"} {
+		if _, err := DecodeCursor(token); err == nil {
+			t.Fatalf("expected token %q to be invalid", token)
+		}
+	}
+}
@@ -53,3 +53,51 @@ func (e ResourceNotFoundError) Error() string {
 	}
 	return e.Message
 }
+
+// UnauthorizedError represents an error type for requests with missing or invalid credentials
+type UnauthorizedError struct {
+	Message string
+}
+
+func (e UnauthorizedError) Error() string {
+	if e.Message == "" {
+		return "unauthorized"
+	}
+	return e.Message
+}
+
+// ForbiddenError represents an error type for authenticated callers that lack the required permission
+type ForbiddenError struct {
+	Message string
+}
+
+func (e ForbiddenError) Error() string {
+	if e.Message == "" {
+		return "forbidden"
+	}
+	return e.Message
+}
+
+// ConflictError represents an error type for requests that conflict with the current state of a resource
+type ConflictError struct {
+	Message string
+}
+
+func (e ConflictError) Error() string {
+	if e.Message == "" {
+		return "conflict"
+	}
+	return e.Message
+}
+
+// StorageUnavailableError represents an error type for transient storage failures that are worth retrying
+type StorageUnavailableError struct {
+	Message string
+}
+
+func (e StorageUnavailableError) Error() string {
+	if e.Message == "" {
+		return "storage is temporarily unavailable"
+	}
+	return e.Message
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// OwnerIDExportColumn represents the export column of the expense owner ID
+	OwnerIDExportColumn = "owner_id"
+	// CategoryIDExportColumn represents the export column of the expense category ID
+	CategoryIDExportColumn = "category_id"
+	// TagsExportColumn represents the export column of the expense tags, joined by commas
+	TagsExportColumn = "tags"
+	// ConvertedPriceExportColumn represents the export column of the converted price, requires a currency to convert to
+	ConvertedPriceExportColumn = "converted_price"
+	// ConvertedCurrencyExportColumn represents the export column of the currency the price was converted to
+	ConvertedCurrencyExportColumn = "converted_currency"
+)
+
+// ExpenseExportColumns represents the columns every export of expenses starts with
+var ExpenseExportColumns = []string{"id", "title", "price", "currency", "created_at", "modified_at"}
+
+// OptionalExpenseExportColumns represents the columns that may follow the columns every export starts with
+var OptionalExpenseExportColumns = []string{
+	OwnerIDExportColumn,
+	CategoryIDExportColumn,
+	TagsExportColumn,
+	ConvertedPriceExportColumn,
+	ConvertedCurrencyExportColumn,
+}
+
+// ValidateExportColumns validates a list of optional export columns,
+// converted columns are only allowed when there is a currency to convert to
+func ValidateExportColumns(columns []string, convertTo string) error {
+	for _, column := range columns {
+		valid := false
+		for _, c := range OptionalExpenseExportColumns {
+			valid = valid || c == column
+		}
+		if !valid {
+			return DataValidationError{
+				Message: fmt.Sprintf(
+					"invalid column: %s, expected one of: %s", column, strings.Join(OptionalExpenseExportColumns, ","),
+				),
+			}
+		}
+		converted := column == ConvertedPriceExportColumn || column == ConvertedCurrencyExportColumn
+		if converted && convertTo == "" {
+			return DataValidationError{Message: fmt.Sprintf("column: %s requires convert_to", column)}
+		}
+	}
+	return nil
+}
+
+// formulaPrefixes represents the first characters that make spreadsheets evaluate a cell as a formula
+const formulaPrefixes = "=+-@\t\r"
+
+// ExpenseExportRow returns the values of an expense for the columns every export starts with
+// followed by a given list of optional columns. Text values are escaped, see escapeExportCell
+func ExpenseExportRow(e Expense, columns []string) []string {
+	row := []string{
+		escapeExportCell(e.ID),
+		escapeExportCell(e.Title),
+		e.Price.String(),
+		e.Price.Currency,
+		e.CreatedAt.UTC().Format(time.RFC3339),
+		e.ModifiedAt.UTC().Format(time.RFC3339),
+	}
+	for _, column := range columns {
+		var value string
+		switch column {
+		case OwnerIDExportColumn:
+			value = escapeExportCell(e.OwnerID)
+		case CategoryIDExportColumn:
+			value = escapeExportCell(e.CategoryID)
+		case TagsExportColumn:
+			value = escapeExportCell(strings.Join(e.Tags, ","))
+		case ConvertedPriceExportColumn:
+			if e.Converted != nil {
+				value = e.Converted.String()
+			}
+		case ConvertedCurrencyExportColumn:
+			if e.Converted != nil {
+				value = e.Converted.Currency
+			}
+		}
+		row = append(row, value)
+	}
+	return row
+}
+
+// escapeExportCell prefixes text values that start like a formula with a single quote,
+// so that spreadsheets opening the export show them as text instead of evaluating them.
+// Prices are never escaped, negative amounts stay numbers
+func escapeExportCell(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExpenseExportRow(t *testing.T) {
+	createdAt := time.Date(2021, time.March, 4, 10, 30, 0, 0, time.UTC)
+	expense := Expense{
+		ID:         "id",
+		OwnerID:    "owner",
+		Title:      "lunch, with tea",
+		Price:      Money{Amount: 125000, Currency: "EUR"},
+		Converted:  &Money{Amount: 137500, Currency: "USD"},
+		CategoryID: "category",
+		Tags:       []string{"food", "work"},
+		CreatedAt:  createdAt,
+		ModifiedAt: createdAt,
+	}
+
+	got := ExpenseExportRow(expense, []string{TagsExportColumn, ConvertedPriceExportColumn, ConvertedCurrencyExportColumn})
+	want := []string{
+		"id", "lunch, with tea", "12.50", "EUR", "2021-03-04T10:30:00Z", "2021-03-04T10:30:00Z",
+		"food,work", "13.75", "USD",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected: %v, got: %v", want, got)
+	}
+}
+
+func TestExpenseExportRowEscapesFormulas(t *testing.T) {
+	expense := Expense{
+		ID:    "id",
+		Title: "=HYPERLINK(\"http://example.com\")",
+		Price: Money{Amount: -125000, Currency: "EUR"},
+		Tags:  []string{"@work", "food"},
+	}
+
+	got := ExpenseExportRow(expense, []string{CategoryIDExportColumn, TagsExportColumn})
+	if got[1] != "'=HYPERLINK(\"http://example.com\")" {
+		t.Fatalf("expected the title to be escaped, got: %s", got[1])
+	}
+	if got[2] != "-12.50" {
+		t.Fatalf("expected the price not to be escaped, got: %s", got[2])
+	}
+	if got[6] != "" {
+		t.Fatalf("expected an empty category, got: %s", got[6])
+	}
+	if got[7] != "'@work,food" {
+		t.Fatalf("expected the tags to be escaped, got: %s", got[7])
+	}
+	for _, title := range []string{"+1", "-1", "\tlunch", "\rlunch"} {
+		if cell := escapeExportCell(title); cell != "'"+title {
+			t.Fatalf("expected: %q to be escaped, got: %q", title, cell)
+		}
+	}
+}
+
+func TestValidateExportColumns(t *testing.T) {
+	if err := ValidateExportColumns([]string{OwnerIDExportColumn, TagsExportColumn}, ""); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := ValidateExportColumns([]string{"password"}, ""); err == nil {
+		t.Fatal("expected an error for an unknown column")
+	}
+	if err := ValidateExportColumns([]string{ConvertedPriceExportColumn}, ""); err == nil {
+		t.Fatal("expected an error for a converted column without convert_to")
+	}
+}
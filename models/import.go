@@ -0,0 +1,127 @@
+package models
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// RejectImportMode represents the import mode that creates nothing when any of the rows fails
+	RejectImportMode = "reject"
+	// SkipImportMode represents the import mode that leaves out the rows that fail and creates the rest of them
+	SkipImportMode = "skip"
+
+	// CreatedImportStatus represents imported rows that were created
+	CreatedImportStatus = "created"
+	// SkippedImportStatus represents valid imported rows that were not created, such as already imported transactions
+	SkippedImportStatus = "skipped"
+	// FailedImportStatus represents imported rows that could not be created because they are not valid
+	FailedImportStatus = "failed"
+
+	// TitleImportField represents the import field of the expense title
+	TitleImportField = "title"
+	// PriceImportField represents the import field of the expense price amount
+	PriceImportField = "price"
+	// CurrencyImportField represents the import field of the expense price currency
+	CurrencyImportField = "currency"
+	// CategoryIDImportField represents the import field of the expense category ID
+	CategoryIDImportField = "category_id"
+	// TagsImportField represents the import field of the expense tags, separated by commas
+	TagsImportField = "tags"
+	// CreatedAtImportField represents the import field of the expense creation date or RFC3339 time
+	CreatedAtImportField = "created_at"
+)
+
+// ImportFields represents the expense fields that imported columns may be mapped to
+var ImportFields = []string{
+	TitleImportField,
+	PriceImportField,
+	CurrencyImportField,
+	CategoryIDImportField,
+	TagsImportField,
+	CreatedAtImportField,
+}
+
+// ImportExpensesRequest represents http request for importing expenses from a CSV file
+type ImportExpensesRequest struct {
+	UserID string
+	Mode   string
+	// Mapping maps the header columns of the file to import fields, columns are matched by the field name without it
+	Mapping map[string]string
+	// Currency represents the currency of the rows without one
+	Currency string
+	DryRun   bool
+	Content  io.Reader
+}
+
+// Validate validates the import expenses incoming request
+func (r ImportExpensesRequest) Validate() error {
+	if err := validateImportMode(r.Mode); err != nil {
+		return err
+	}
+	for column, field := range r.Mapping {
+		if !isImportField(field) {
+			return DataValidationError{
+				Message: fmt.Sprintf(
+					"invalid field: %s of column: %s, expected one of: %s", field, column, strings.Join(ImportFields, ","),
+				),
+			}
+		}
+	}
+	if r.Currency != "" {
+		return validateCurrency(r.Currency)
+	}
+	return nil
+}
+
+// ImportRow represents the outcome of an imported row, lines start at 1 with the header of CSV files
+type ImportRow struct {
+	Line      int    `json:"line"`
+	Status    string `json:"status"`
+	ExpenseID string `json:"expense_id,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	// Expense represents the expense that would be created by a dry run
+	Expense *Expense `json:"expense,omitempty"`
+}
+
+// ImportReport represents the outcome of an import, row by row.
+// Dry runs report what an import would do without saving anything
+type ImportReport struct {
+	Mode    string      `json:"mode"`
+	DryRun  bool        `json:"dry_run"`
+	Created int         `json:"created"`
+	Skipped int         `json:"skipped"`
+	Failed  int         `json:"failed"`
+	Rows    []ImportRow `json:"rows"`
+}
+
+// Add adds the outcome of a row to the report
+func (r *ImportReport) Add(row ImportRow) {
+	switch row.Status {
+	case CreatedImportStatus:
+		r.Created++
+	case SkippedImportStatus:
+		r.Skipped++
+	case FailedImportStatus:
+		r.Failed++
+	}
+	r.Rows = append(r.Rows, row)
+}
+
+func validateImportMode(mode string) error {
+	switch mode {
+	case "", RejectImportMode, SkipImportMode:
+		return nil
+	}
+	return DataValidationError{Message: "mode must be one of: " + RejectImportMode + "," + SkipImportMode}
+}
+
+func isImportField(field string) bool {
+	for _, f := range ImportFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -1,15 +1,110 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
 // Expense represents the expense model
 type Expense struct {
+	ID         string   `json:"id" db:"id"`
+	OwnerID    string   `json:"owner_id" db:"owner_id"`
+	Price      Money    `json:"price" db:"-"`
+	Converted  *Money   `json:"converted,omitempty" db:"-"`
+	Title      string   `json:"title" db:"title"`
+	CategoryID string   `json:"category_id,omitempty" db:"category_id"`
+	Tags       []string `json:"tags,omitempty" db:"-"`
+	// Transaction represents the bank transaction of expenses imported from bank statements
+	Transaction *BankTransaction `json:"transaction,omitempty" db:"-"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	ModifiedAt  time.Time        `json:"modified_at" db:"modified_at"`
+}
+
+// expenseJSON represents the expense fields that keep their default wire format
+type expenseJSON Expense
+
+// MarshalJSON encodes the expense price as a decimal number along with a separate currency: "price":12.34,"currency":"USD"
+func (e Expense) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		expenseJSON
+		Price    json.Number `json:"price"`
+		Currency string      `json:"currency"`
+	}{
+		expenseJSON: expenseJSON(e),
+		Price:       json.Number(e.Price.String()),
+		Currency:    e.Price.Currency,
+	})
+}
+
+// UnmarshalJSON decodes an expense whose price is either a decimal along with a separate currency, or a money object
+func (e *Expense) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		expenseJSON
+		Price    json.RawMessage `json:"price"`
+		Currency string          `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	price, err := priceJSON{Price: raw.Price, Currency: raw.Currency}.money()
+	if err != nil {
+		return err
+	}
+	*e = Expense(raw.expenseJSON)
+	e.Price = Money{}
+	if price != nil {
+		e.Price = *price
+	}
+	return nil
+}
+
+// TagUsage represents an expense tag along with the amount of expenses that use it
+type TagUsage struct {
+	Tag   string `json:"tag" db:"tag"`
+	Count int    `json:"count" db:"count"`
+}
+
+// Category represents the expense category model
+type Category struct {
 	ID         string    `json:"id" db:"id"`
-	Price      float64   `json:"price" db:"price"`
-	Title      string    `json:"title" db:"title"`
-	Currency   string    `json:"currency" db:"currency"`
+	OwnerID    string    `json:"owner_id" db:"owner_id"`
+	Name       string    `json:"name" db:"name"`
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 	ModifiedAt time.Time `json:"modified_at" db:"modified_at"`
 }
+
+// User represents the user model
+type User struct {
+	ID           string    `json:"id" db:"id"`
+	Email        string    `json:"email" db:"email"`
+	PasswordHash string    `json:"-" db:"password_hash"`
+	Role         string    `json:"role" db:"role"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	ModifiedAt   time.Time `json:"modified_at" db:"modified_at"`
+}
+
+// Session represents an authenticated user session, ID holds the hash of the session token
+type Session struct {
+	ID        string    `json:"-" db:"id"`
+	UserID    string    `json:"user_id" db:"user_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+}
+
+// APIKey represents a long-lived user API key, only the hash of the key is ever stored
+type APIKey struct {
+	ID         string     `json:"id" db:"id"`
+	UserID     string     `json:"user_id" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	Prefix     string     `json:"prefix" db:"prefix"`
+	Hash       string     `json:"-" db:"hash"`
+	Scope      string     `json:"scope" db:"scope"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Expired checks whether the API key has an expiry in the past
+func (k APIKey) Expired() bool {
+	return k.ExpiresAt != nil && time.Now().UTC().After(*k.ExpiresAt)
+}
@@ -0,0 +1,232 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// PriceScale represents the amount of decimal digits money amounts of every currency are kept, compared and stored with
+const PriceScale = 4
+
+// maxWholeDigits represents the amount of whole digits of the decimals ParseDecimal allows
+const maxWholeDigits = 14
+
+// Money represents a fixed-point amount of money, such as 123400 for 12.34.
+// Amounts are kept in PriceScale decimal digits whatever the currency, so that stored money never depends on the
+// currency catalogue: the minor units of the catalogue only limit the digits of new amounts and format amounts
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// moneyJSON represents the wire format of money, the amount is always sent as a decimal string
+type moneyJSON struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+// ParseMoney parses a decimal amount, such as 12.34, of a given supported currency,
+// allowing at most as many decimal digits as the currency minor units
+func ParseMoney(amount, currency string) (Money, error) {
+	m, err := ParseStoredMoney(amount, currency)
+	if err != nil {
+		return Money{}, DataValidationError{Message: fmt.Sprintf("invalid amount: %s", amount)}
+	}
+	if err = m.Validate(); err != nil {
+		return Money{}, err
+	}
+	return m, nil
+}
+
+// ParseStoredMoney parses a decimal amount of at most PriceScale decimal digits, such as 12.3456, of a given currency.
+// The currency catalogue is not consulted, so that money stored before a change of the catalogue stays readable
+func ParseStoredMoney(amount, currency string) (Money, error) {
+	scaled, err := ParseDecimal(amount, PriceScale)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: scaled, Currency: strings.ToUpper(strings.TrimSpace(currency))}, nil
+}
+
+// ParseAggregateMoney parses a decimal amount computed out of stored money, such as the SUM of prices.
+// Its whole part is only limited by the range of Money, amounts out of range come with a DataValidationError
+func ParseAggregateMoney(amount, currency string) (Money, error) {
+	scaled, err := parseDecimal(amount, PriceScale, 0)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: scaled, Currency: strings.ToUpper(strings.TrimSpace(currency))}, nil
+}
+
+// Validate checks that the currency is supported and that the amount fits the currency minor units
+func (m Money) Validate() error {
+	digits, ok := MinorUnits(m.Currency)
+	if !ok {
+		return DataValidationError{Message: fmt.Sprintf("unsupported currency: %s", m.Currency)}
+	}
+	if m.Amount%minorUnit(digits) != 0 {
+		return DataValidationError{
+			Message: fmt.Sprintf("invalid amount: %s, %s allows at most %d decimal digits", m, m.Currency, digits),
+		}
+	}
+	return nil
+}
+
+// MinorUnits returns the amount of minor unit digits of a given supported currency
+func MinorUnits(currency string) (int, bool) {
+	c, ok := LookupCurrency(currency)
+	return c.MinorUnits, ok
+}
+
+// String returns the decimal amount of money with at least the currency minor units digits, such as 12.30.
+// Digits past the minor units are only kept when they are not zero
+func (m Money) String() string {
+	digits, _ := MinorUnits(m.Currency)
+	s := FormatDecimal(m.Amount, PriceScale)
+	for i := PriceScale; i > digits && strings.HasSuffix(s, "0"); i-- {
+		s = s[:len(s)-1]
+	}
+	return strings.TrimSuffix(s, ".")
+}
+
+// minorUnit returns the amount, in PriceScale digits, of one minor unit of a currency of given minor unit digits
+func minorUnit(digits int) int64 {
+	unit := int64(1)
+	for i := digits; i < PriceScale; i++ {
+		unit *= 10
+	}
+	return unit
+}
+
+// MarshalJSON encodes money as: {"amount":"12.34","currency":"USD"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	amount, err := json.Marshal(m.String())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(moneyJSON{Amount: amount, Currency: m.Currency})
+}
+
+// UnmarshalJSON decodes money out of an object that holds the amount either as a string or as a number.
+// Decoded money is not validated against the currency catalogue, requests validate it before it gets stored
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var raw moneyJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return DataValidationError{Message: "price must be an object with an amount and a currency"}
+	}
+	amount, err := decimalAmount(raw.Amount)
+	if err != nil {
+		return err
+	}
+	money, err := ParseStoredMoney(amount, raw.Currency)
+	if err != nil {
+		return DataValidationError{Message: fmt.Sprintf("invalid amount: %s", amount)}
+	}
+	*m = money
+	return nil
+}
+
+// priceJSON represents the wire format of expense prices: a decimal price, sent either as a number or as a string,
+// along with a separate currency. A money object is accepted as the price as well, in which case the currency is
+// part of the object
+type priceJSON struct {
+	Price    json.RawMessage `json:"price"`
+	Currency string          `json:"currency"`
+}
+
+// money decodes the price, it returns nil when neither a price nor a currency were sent
+func (p priceJSON) money() (*Money, error) {
+	price := strings.TrimSpace(string(p.Price))
+	if price == "null" {
+		price = ""
+	}
+	if strings.HasPrefix(price, "{") {
+		var m Money
+		if err := json.Unmarshal(p.Price, &m); err != nil {
+			return nil, err
+		}
+		return &m, nil
+	}
+
+	switch {
+	case price == "" && p.Currency == "":
+		return nil, nil
+	case price == "":
+		return nil, DataValidationError{Message: "price should not be empty"}
+	case p.Currency == "":
+		return nil, DataValidationError{Message: "currency should not be empty"}
+	}
+	amount, err := decimalAmount(p.Price)
+	if err != nil {
+		return nil, err
+	}
+	m, err := ParseStoredMoney(amount, p.Currency)
+	if err != nil {
+		return nil, DataValidationError{Message: fmt.Sprintf("invalid price: %s", amount)}
+	}
+	return &m, nil
+}
+
+// decimalAmount returns the decimal amount of a JSON number or of a JSON string
+func decimalAmount(raw json.RawMessage) (string, error) {
+	amount := strings.TrimSpace(string(raw))
+	if strings.HasPrefix(amount, `"`) {
+		if err := json.Unmarshal(raw, &amount); err != nil {
+			return "", DataValidationError{Message: "invalid price amount"}
+		}
+	}
+	return amount, nil
+}
+
+// ParseDecimal parses a plain decimal number, such as -12.34, into an integer of a given amount of decimal digits
+func ParseDecimal(s string, digits int) (int64, error) {
+	return parseDecimal(s, digits, maxWholeDigits)
+}
+
+// parseDecimal parses a plain decimal number of a whole part of at most a given amount of digits,
+// or of any whole part that fits int64 when the amount of digits is zero
+func parseDecimal(s string, digits, wholeDigits int) (int64, error) {
+	invalidErr := fmt.Errorf("invalid decimal: %s", s)
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	parts := strings.SplitN(s, ".", 2)
+	whole, fraction := parts[0], ""
+	if len(parts) == 2 {
+		fraction = strings.TrimRight(parts[1], "0")
+	}
+	if whole == "" && fraction == "" || len(fraction) > digits || wholeDigits > 0 && len(whole) > wholeDigits {
+		return 0, invalidErr
+	}
+
+	var res int64
+	for _, c := range whole + fraction + strings.Repeat("0", digits-len(fraction)) {
+		if c < '0' || c > '9' {
+			return 0, invalidErr
+		}
+		if res > (math.MaxInt64-int64(c-'0'))/10 {
+			return 0, DataValidationError{Message: fmt.Sprintf("decimal out of range: %s", strings.TrimSpace(s))}
+		}
+		res = res*10 + int64(c-'0')
+	}
+	if negative {
+		res = -res
+	}
+	return res, nil
+}
+
+// FormatDecimal formats an integer of a given amount of decimal digits as a plain decimal number, such as 12.34
+func FormatDecimal(v int64, digits int) string {
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	s := fmt.Sprintf("%0*d", digits+1, v)
+	if digits == 0 {
+		return sign + s
+	}
+	return sign + s[:len(s)-digits] + "." + s[len(s)-digits:]
+}
@@ -0,0 +1,250 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{
+			name:  "string amount",
+			input: `{"amount":"12.34","currency":"USD"}`,
+			want:  Money{Amount: 123400, Currency: "USD"},
+		},
+		{
+			name:  "number amount",
+			input: `{"amount":0.3,"currency":"eur"}`,
+			want:  Money{Amount: 3000, Currency: "EUR"},
+		},
+		{
+			name:  "trailing zeros",
+			input: `{"amount":"7.5000","currency":"GBP"}`,
+			want:  Money{Amount: 75000, Currency: "GBP"},
+		},
+		{
+			name:  "more digits than the currency minor units",
+			input: `{"amount":"1.234","currency":"USD"}`,
+			want:  Money{Amount: 12340, Currency: "USD"},
+		},
+		{
+			name:  "currency missing from the catalogue",
+			input: `{"amount":"1","currency":"XXX"}`,
+			want:  Money{Amount: 10000, Currency: "XXX"},
+		},
+		{
+			name:    "more digits than the price scale",
+			input:   `{"amount":"1.23456","currency":"USD"}`,
+			wantErr: true,
+		},
+		{
+			name:    "exponent",
+			input:   `{"amount":1e3,"currency":"USD"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var m Money
+			err := json.Unmarshal([]byte(test.input), &m)
+			if test.wantErr {
+				if _, ok := err.(DataValidationError); !ok {
+					t.Fatalf("expected data validation error, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if m != test.want {
+				t.Fatalf("expected: %+v, got: %+v", test.want, m)
+			}
+		})
+	}
+}
+
+func TestMoneyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   Money
+		wantErr bool
+	}{
+		{
+			name:  "fits the minor units",
+			input: Money{Amount: 12300, Currency: "USD"},
+		},
+		{
+			name:    "more digits than the minor units",
+			input:   Money{Amount: 12340, Currency: "USD"},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported currency",
+			input:   Money{Amount: 10000, Currency: "XXX"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.input.Validate()
+			if _, ok := err.(DataValidationError); ok != test.wantErr {
+				t.Fatalf("expected data validation error: %v, got: %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		input Money
+		want  string
+	}{
+		{input: Money{Amount: 123000, Currency: "USD"}, want: "12.30"},
+		{input: Money{Amount: 123450, Currency: "USD"}, want: "12.345"},
+		{input: Money{Amount: -500, Currency: "EUR"}, want: "-0.05"},
+		{input: Money{Amount: 120000, Currency: "XXX"}, want: "12"},
+		{input: Money{Amount: 123456, Currency: "XXX"}, want: "12.3456"},
+	}
+
+	for _, test := range tests {
+		if got := test.input.String(); got != test.want {
+			t.Fatalf("expected: %s, got: %s", test.want, got)
+		}
+	}
+}
+
+func TestMoneyMarshalJSON(t *testing.T) {
+	bs, err := json.Marshal(Money{Amount: -500, Currency: "USD"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `{"amount":"-0.05","currency":"USD"}`; string(bs) != want {
+		t.Fatalf("expected: %s, got: %s", want, bs)
+	}
+}
+
+func TestExpensePriceJSON(t *testing.T) {
+	bs, err := json.Marshal(Expense{ID: "e1", Price: Money{Amount: 123000, Currency: "USD"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(bs, &fields); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(fields["price"]) != "12.30" || string(fields["currency"]) != `"USD"` {
+		t.Fatalf("expected the price as a number along with a currency, got: %s", bs)
+	}
+
+	var expense Expense
+	if err = json.Unmarshal(bs, &expense); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := (Money{Amount: 123000, Currency: "USD"}); expense.ID != "e1" || expense.Price != want {
+		t.Fatalf("expected: %+v, got: %+v", want, expense.Price)
+	}
+}
+
+func TestCreateExpenseRequestPriceJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{
+			name:  "number price along with a currency",
+			input: `{"price":12.34,"currency":"usd"}`,
+			want:  Money{Amount: 123400, Currency: "USD"},
+		},
+		{
+			name:  "string price along with a currency",
+			input: `{"price":"12.34","currency":"EUR"}`,
+			want:  Money{Amount: 123400, Currency: "EUR"},
+		},
+		{
+			name:  "money object",
+			input: `{"price":{"amount":"12.34","currency":"GBP"}}`,
+			want:  Money{Amount: 123400, Currency: "GBP"},
+		},
+		{
+			name:  "no price",
+			input: `{"title":"coffee"}`,
+		},
+		{
+			name:    "price without a currency",
+			input:   `{"price":12.34}`,
+			wantErr: true,
+		},
+		{
+			name:    "currency without a price",
+			input:   `{"currency":"USD"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var req CreateExpenseRequest
+			err := json.Unmarshal([]byte(test.input), &req)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got price: %+v", req.Price)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if req.Price != test.want {
+				t.Fatalf("expected: %+v, got: %+v", test.want, req.Price)
+			}
+		})
+	}
+}
+
+func TestParseAggregateMoney(t *testing.T) {
+	tests := []struct {
+		amount        string
+		want          int64
+		wantRangeErr  bool
+		wantSyntaxErr bool
+	}{
+		{amount: "12.3400", want: 123400},
+		{amount: "123456789012345.5", want: 1234567890123455000},
+		{amount: "922337203685477.5807", want: 9223372036854775807},
+		{amount: "-922337203685477.5807", want: -9223372036854775807},
+		{amount: "922337203685477.5808", wantRangeErr: true},
+		{amount: "10000000000000000000", wantRangeErr: true},
+		{amount: "12.34567", wantSyntaxErr: true},
+		{amount: "abc", wantSyntaxErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.amount, func(t *testing.T) {
+			got, err := ParseAggregateMoney(test.amount, "usd")
+			_, rangeErr := err.(DataValidationError)
+			switch {
+			case test.wantRangeErr && !rangeErr:
+				t.Fatalf("expected a data validation error, got: %v", err)
+			case test.wantSyntaxErr && (err == nil || rangeErr):
+				t.Fatalf("expected a syntax error, got: %v", err)
+			case !test.wantRangeErr && !test.wantSyntaxErr && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err == nil && (got.Amount != test.want || got.Currency != "USD") {
+				t.Fatalf("expected: %d USD, got: %d %s", test.want, got.Amount, got.Currency)
+			}
+		})
+	}
+	if _, err := ParseStoredMoney("123456789012345.5", "USD"); err == nil {
+		t.Fatalf("expected stored money to keep its whole digits limit")
+	}
+}
@@ -0,0 +1,72 @@
+package models
+
+// Permission represents an action a caller is allowed to perform
+type Permission string
+
+// Application permissions
+const (
+	// ReadExpensesPermission allows reading own expenses
+	ReadExpensesPermission Permission = "expenses:read"
+	// ReadAllExpensesPermission allows reading the expenses of every user
+	ReadAllExpensesPermission Permission = "expenses:read_all"
+	// WriteExpensesPermission allows creating, updating and deleting own expenses
+	WriteExpensesPermission Permission = "expenses:write"
+	// ManageUsersPermission allows changing the roles of other users
+	ManageUsersPermission Permission = "users:manage"
+	// ManageRatesPermission allows storing and importing exchange rates
+	ManageRatesPermission Permission = "rates:manage"
+	// ManageAPIKeysPermission allows creating and revoking own API keys
+	ManageAPIKeysPermission Permission = "api_keys:manage"
+)
+
+var rolePermissions = map[string][]Permission{
+	AdminRole: {
+		ReadExpensesPermission,
+		ReadAllExpensesPermission,
+		WriteExpensesPermission,
+		ManageUsersPermission,
+		ManageRatesPermission,
+		ManageAPIKeysPermission,
+	},
+	MemberRole: {
+		ReadExpensesPermission,
+		WriteExpensesPermission,
+		ManageAPIKeysPermission,
+	},
+	ReadOnlyRole: {
+		ReadExpensesPermission,
+		ReadAllExpensesPermission,
+		ManageAPIKeysPermission,
+	},
+}
+
+// readPermissions represents the list of permissions that do not change any data
+var readPermissions = map[Permission]bool{
+	ReadExpensesPermission:    true,
+	ReadAllExpensesPermission: true,
+}
+
+// ValidRole checks whether a given role is one of the known user roles
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// HasPermission checks whether the user role grants a given permission, users without a role are members
+func (u User) HasPermission(permission Permission) bool {
+	role := u.Role
+	if role == "" {
+		role = MemberRole
+	}
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeAllows checks whether a given API key scope allows a given permission
+func ScopeAllows(scope string, permission Permission) bool {
+	return scope != ReadOnlyScope || readPermissions[permission]
+}
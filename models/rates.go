@@ -0,0 +1,92 @@
+package models
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"time"
+)
+
+// DateLayout represents the layout of plain dates, such as the dates of exchange rates
+const DateLayout = "2006-01-02"
+
+var rateRegexp = regexp.MustCompile(`^[0-9]{1,14}(\.[0-9]{1,10})?$`)
+
+// DailyRates represents the exchange rates of a day, quoted as units of each currency per one unit of the base currency
+type DailyRates struct {
+	Date  string            `json:"date"`
+	Base  string            `json:"base"`
+	Rates map[string]string `json:"rates"`
+}
+
+// Validate validates a set of daily exchange rates
+func (r DailyRates) Validate() error {
+	if _, err := time.Parse(DateLayout, r.Date); err != nil {
+		return DataValidationError{Message: fmt.Sprintf("invalid date: %s, expected: %s", r.Date, DateLayout)}
+	}
+	if !currencyCodeRegexp.MatchString(r.Base) {
+		return DataValidationError{Message: fmt.Sprintf("invalid base currency: %s", r.Base)}
+	}
+	if len(r.Rates) == 0 {
+		return DataValidationError{Message: "rates should not be empty"}
+	}
+	for currency, rate := range r.Rates {
+		if !currencyCodeRegexp.MatchString(currency) {
+			return DataValidationError{Message: fmt.Sprintf("invalid currency: %s", currency)}
+		}
+		if !rateRegexp.MatchString(rate) || rate == "0" || !isPositive(rate) {
+			return DataValidationError{Message: fmt.Sprintf("invalid rate: %s of currency: %s", rate, currency)}
+		}
+	}
+	return nil
+}
+
+// Convert converts money into a given currency, rounding half away from zero to the currency minor units
+func (r DailyRates) Convert(m Money, to string) (Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+	digits, ok := MinorUnits(to)
+	if !ok {
+		return Money{}, DataValidationError{Message: fmt.Sprintf("unsupported currency: %s", to)}
+	}
+	from, fromOK := r.rate(m.Currency)
+	target, targetOK := r.rate(to)
+	if !fromOK || !targetOK {
+		return Money{}, DataValidationError{
+			Message: fmt.Sprintf("no exchange rate from %s to %s on %s", m.Currency, to, r.Date),
+		}
+	}
+
+	amount := new(big.Rat).SetFrac(big.NewInt(m.Amount), big.NewInt(minorUnit(digits)))
+	amount.Mul(amount, target)
+	amount.Quo(amount, from)
+	return Money{Amount: roundRat(amount) * minorUnit(digits), Currency: to}, nil
+}
+
+// rate returns the amount of units of a given currency per one unit of the base currency
+func (r DailyRates) rate(currency string) (*big.Rat, bool) {
+	if currency == r.Base {
+		return big.NewRat(1, 1), true
+	}
+	rate, ok := new(big.Rat).SetString(r.Rates[currency])
+	return rate, ok && rate.Sign() > 0
+}
+
+func isPositive(rate string) bool {
+	r, ok := new(big.Rat).SetString(rate)
+	return ok && r.Sign() > 0
+}
+
+// roundRat rounds a rational number half away from zero
+func roundRat(r *big.Rat) int64 {
+	num, denom := new(big.Int).Abs(r.Num()), r.Denom()
+	q, rem := new(big.Int).QuoRem(num, denom, new(big.Int))
+	if rem.Mul(rem, big.NewInt(2)).Cmp(denom) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if r.Sign() < 0 {
+		q.Neg(q)
+	}
+	return q.Int64()
+}
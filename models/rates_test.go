@@ -0,0 +1,78 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestDailyRatesConvert(t *testing.T) {
+	rates := DailyRates{
+		Date: "2020-03-02",
+		Base: "EUR",
+		Rates: map[string]string{
+			"USD": "1.25",
+			"GBP": "0.8",
+		},
+	}
+	tests := []struct {
+		name    string
+		input   Money
+		to      string
+		want    Money
+		wantErr bool
+	}{
+		{
+			name:  "from base",
+			input: Money{Amount: 100000, Currency: "EUR"},
+			to:    "USD",
+			want:  Money{Amount: 125000, Currency: "USD"},
+		},
+		{
+			name:  "into base",
+			input: Money{Amount: 100000, Currency: "USD"},
+			to:    "EUR",
+			want:  Money{Amount: 80000, Currency: "EUR"},
+		},
+		{
+			name:  "cross rate",
+			input: Money{Amount: 100000, Currency: "USD"},
+			to:    "GBP",
+			want:  Money{Amount: 64000, Currency: "GBP"},
+		},
+		{
+			name:  "rounds half away from zero",
+			input: Money{Amount: -100, Currency: "USD"},
+			to:    "EUR",
+			want:  Money{Amount: -100, Currency: "EUR"},
+		},
+		{
+			name:  "same currency",
+			input: Money{Amount: 700, Currency: "MDL"},
+			to:    "MDL",
+			want:  Money{Amount: 700, Currency: "MDL"},
+		},
+		{
+			name:    "missing rate",
+			input:   Money{Amount: 10000, Currency: "MDL"},
+			to:      "EUR",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := rates.Convert(test.input, test.to)
+			if test.wantErr {
+				if _, ok := err.(DataValidationError); !ok {
+					t.Fatalf("expected data validation error, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if m != test.want {
+				t.Fatalf("expected: %+v, got: %+v", test.want, m)
+			}
+		})
+	}
+}
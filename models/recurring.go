@@ -0,0 +1,215 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// DailyFrequency represents schedules that repeat every given amount of days
+	DailyFrequency = "daily"
+	// WeeklyFrequency represents schedules that repeat every given amount of weeks, on the weekday of the start date
+	WeeklyFrequency = "weekly"
+	// MonthlyFrequency represents schedules that repeat every given amount of months, on a given day of the month
+	MonthlyFrequency = "monthly"
+	// YearlyFrequency represents schedules that repeat every given amount of years, on the day of the start date
+	YearlyFrequency = "yearly"
+
+	maxScheduleInterval = 1000
+	// minStartDate represents the earliest start date of recurring expenses
+	minStartDate = "1970-01-01"
+	// maxDueOccurrences represents how many missed occurrences of a recurring expense get created at most,
+	// older ones are skipped
+	maxDueOccurrences = 100
+)
+
+var frequencies = []string{DailyFrequency, WeeklyFrequency, MonthlyFrequency, YearlyFrequency}
+
+// Schedule represents an RRULE like schedule, such as: every 2 weeks or monthly on the 31st.
+// Days of the month that do not exist in shorter months fall on the last day of the month
+type Schedule struct {
+	Frequency string `json:"frequency"`
+	Interval  int    `json:"interval"`
+	// MonthDay represents the day of monthly schedules, the day of the start date is used when it is zero
+	MonthDay int `json:"month_day,omitempty"`
+}
+
+// ExpenseTemplate represents the expense every occurrence of a recurring expense creates
+type ExpenseTemplate struct {
+	Title      string   `json:"title"`
+	Price      Money    `json:"price"`
+	CategoryID string   `json:"category_id,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+// RecurringExpense represents the recurring expense model, such as rent or subscriptions
+type RecurringExpense struct {
+	ID        string          `json:"id" db:"id"`
+	OwnerID   string          `json:"owner_id" db:"owner_id"`
+	Template  ExpenseTemplate `json:"template" db:"-"`
+	Schedule  Schedule        `json:"schedule" db:"-"`
+	StartDate string          `json:"start_date" db:"start_date"`
+	EndDate   string          `json:"end_date,omitempty" db:"end_date"`
+	// LastRun represents the date of the latest occurrence an expense was created for
+	LastRun    string    `json:"last_run,omitempty" db:"last_run"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	ModifiedAt time.Time `json:"modified_at" db:"modified_at"`
+}
+
+// DueOccurrences returns the dates of the occurrences after the last run, up to and including a given day.
+// Only the latest maxDueOccurrences are returned, so that a backdated start date does not create
+// years worth of expenses at once
+func (r RecurringExpense) DueOccurrences(now time.Time) []time.Time {
+	start, err := time.Parse(DateLayout, r.StartDate)
+	if err != nil {
+		return nil
+	}
+	until := now.UTC()
+	if end, err := time.Parse(DateLayout, r.EndDate); err == nil && end.Before(until) {
+		until = end
+	}
+
+	last := r.Schedule.lastOccurrence(start, until)
+	first := last - maxDueOccurrences + 1
+	if lastRun, err := time.Parse(DateLayout, r.LastRun); err == nil {
+		if n := r.Schedule.lastOccurrence(start, lastRun) + 1; n > first {
+			first = n
+		}
+	}
+	if first < 0 {
+		first = 0
+	}
+	// monthly schedules on an earlier day of the month than the start date have their first occurrence before it
+	if first == 0 && r.Schedule.occurrence(start, 0).Before(start) {
+		first = 1
+	}
+
+	occurrences := make([]time.Time, 0)
+	for n := first; n <= last; n++ {
+		occurrences = append(occurrences, r.Schedule.occurrence(start, n))
+	}
+	return occurrences
+}
+
+// ValidateDateRange checks that the end date, if any, is not before the start date
+func (r RecurringExpense) ValidateDateRange() error {
+	return validateDateRange(r.StartDate, r.EndDate)
+}
+
+// SourceID returns the source ID of the expense created for a given occurrence, see CreateExpenseRequest
+func (r RecurringExpense) SourceID(occurrence time.Time) string {
+	return fmt.Sprintf("recurring:%s:%s", r.ID, occurrence.Format(DateLayout))
+}
+
+// occurrence returns the date of the nth occurrence of the schedule, counting from a given start date
+func (s Schedule) occurrence(start time.Time, n int) time.Time {
+	step := n * s.interval()
+	switch s.Frequency {
+	case WeeklyFrequency:
+		return start.AddDate(0, 0, 7*step)
+	case MonthlyFrequency:
+		day := s.MonthDay
+		if day == 0 {
+			day = start.Day()
+		}
+		return clampedDate(start.Year(), start.Month()+time.Month(step), day)
+	case YearlyFrequency:
+		return clampedDate(start.Year()+step, start.Month(), start.Day())
+	default:
+		return start.AddDate(0, 0, step)
+	}
+}
+
+// lastOccurrence returns the number of the latest occurrence of the schedule on or before a given time,
+// counting from a given start date, or -1 when the first occurrence is after it.
+// The number gets estimated from the elapsed days, months or years and then corrected by stepping,
+// since months differ in length and days of the month get clamped
+func (s Schedule) lastOccurrence(start, t time.Time) int {
+	var n int
+	switch s.Frequency {
+	case WeeklyFrequency:
+		n = int(t.Sub(start).Hours()/24) / (7 * s.interval())
+	case MonthlyFrequency:
+		n = ((t.Year()-start.Year())*12 + int(t.Month()-start.Month())) / s.interval()
+	case YearlyFrequency:
+		n = (t.Year() - start.Year()) / s.interval()
+	default:
+		n = int(t.Sub(start).Hours()/24) / s.interval()
+	}
+	if n < 0 {
+		n = 0
+	}
+	for n >= 0 && s.occurrence(start, n).After(t) {
+		n--
+	}
+	for !s.occurrence(start, n+1).After(t) {
+		n++
+	}
+	return n
+}
+
+func (s Schedule) interval() int {
+	if s.Interval < 1 {
+		return 1
+	}
+	return s.Interval
+}
+
+// clampedDate returns the date of a given day of a month, or the last day of the month when the day does not exist
+func clampedDate(year int, month time.Month, day int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1).Day()
+	if day > last {
+		day = last
+	}
+	return first.AddDate(0, 0, day-1)
+}
+
+func validateSchedule(s Schedule) error {
+	valid := false
+	for _, f := range frequencies {
+		valid = valid || s.Frequency == f
+	}
+	if !valid {
+		return DataValidationError{Message: "frequency must be one of: " + strings.Join(frequencies, ",")}
+	}
+	if s.Interval < 0 || s.Interval > maxScheduleInterval {
+		return DataValidationError{Message: fmt.Sprintf("interval must be between 1 and %d", maxScheduleInterval)}
+	}
+	if s.MonthDay != 0 && s.Frequency != MonthlyFrequency {
+		return DataValidationError{Message: "month_day is only allowed on monthly schedules"}
+	}
+	if s.MonthDay < 0 || s.MonthDay > 31 {
+		return DataValidationError{Message: "month_day must be between 1 and 31"}
+	}
+	return nil
+}
+
+func validateTemplate(t ExpenseTemplate) error {
+	if err := validateTags(t.Tags); err != nil {
+		return err
+	}
+	return validateExpenseReqBody(t.Title, t.CategoryID, &t.Price, false)
+}
+
+func validateDateRange(startDate, endDate string) error {
+	start, err := time.Parse(DateLayout, startDate)
+	if err != nil {
+		return DataValidationError{Message: fmt.Sprintf("invalid start_date: %s, expected: %s", startDate, DateLayout)}
+	}
+	if startDate < minStartDate {
+		return DataValidationError{Message: "start_date must not be before " + minStartDate}
+	}
+	if endDate == "" {
+		return nil
+	}
+	end, err := time.Parse(DateLayout, endDate)
+	if err != nil {
+		return DataValidationError{Message: fmt.Sprintf("invalid end_date: %s, expected: %s", endDate, DateLayout)}
+	}
+	if end.Before(start) {
+		return DataValidationError{Message: "end_date must not be before start_date"}
+	}
+	return nil
+}
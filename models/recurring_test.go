@@ -0,0 +1,149 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecurringExpenseDueOccurrences(t *testing.T) {
+	now := time.Date(2021, time.May, 3, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		recurring RecurringExpense
+		want      []string
+	}{
+		{
+			name: "weekly every 2 weeks",
+			recurring: RecurringExpense{
+				Schedule:  Schedule{Frequency: WeeklyFrequency, Interval: 2},
+				StartDate: "2021-04-01",
+			},
+			want: []string{"2021-04-01", "2021-04-15", "2021-04-29"},
+		},
+		{
+			name: "monthly on the 31st",
+			recurring: RecurringExpense{
+				Schedule:  Schedule{Frequency: MonthlyFrequency, Interval: 1, MonthDay: 31},
+				StartDate: "2021-01-15",
+			},
+			want: []string{"2021-01-31", "2021-02-28", "2021-03-31", "2021-04-30"},
+		},
+		{
+			name: "yearly on leap day",
+			recurring: RecurringExpense{
+				Schedule:  Schedule{Frequency: YearlyFrequency, Interval: 1},
+				StartDate: "2020-02-29",
+			},
+			want: []string{"2020-02-29", "2021-02-28"},
+		},
+		{
+			name: "daily after the last run and until the end date",
+			recurring: RecurringExpense{
+				Schedule:  Schedule{Frequency: DailyFrequency, Interval: 1},
+				StartDate: "2021-04-25",
+				EndDate:   "2021-05-01",
+				LastRun:   "2021-04-28",
+			},
+			want: []string{"2021-04-29", "2021-04-30", "2021-05-01"},
+		},
+		{
+			name: "daily from a backdated start date",
+			recurring: RecurringExpense{
+				Schedule:  Schedule{Frequency: DailyFrequency, Interval: 1},
+				StartDate: "2011-05-03",
+				EndDate:   "2021-04-30",
+			},
+			want: dailyDates("2021-01-21", maxDueOccurrences),
+		},
+		{
+			name: "daily every 3 days after a backdated last run",
+			recurring: RecurringExpense{
+				Schedule:  Schedule{Frequency: DailyFrequency, Interval: 3},
+				StartDate: "1970-01-01",
+				LastRun:   "2021-04-21",
+			},
+			want: []string{"2021-04-24", "2021-04-27", "2021-04-30", "2021-05-03"},
+		},
+		{
+			name: "weekly after the last run",
+			recurring: RecurringExpense{
+				Schedule:  Schedule{Frequency: WeeklyFrequency, Interval: 1},
+				StartDate: "2001-01-01",
+				LastRun:   "2021-04-19",
+			},
+			want: []string{"2021-04-26", "2021-05-03"},
+		},
+		{
+			name: "monthly on an earlier day than the start date after the last run",
+			recurring: RecurringExpense{
+				Schedule:  Schedule{Frequency: MonthlyFrequency, Interval: 2, MonthDay: 5},
+				StartDate: "1999-12-20",
+				LastRun:   "2020-12-05",
+			},
+			want: []string{"2021-02-05", "2021-04-05"},
+		},
+		{
+			name: "monthly on an earlier day than the start date",
+			recurring: RecurringExpense{
+				Schedule:  Schedule{Frequency: MonthlyFrequency, Interval: 1, MonthDay: 5},
+				StartDate: "2021-02-20",
+			},
+			want: []string{"2021-03-05", "2021-04-05"},
+		},
+		{
+			name: "yearly every 2 years after the last run",
+			recurring: RecurringExpense{
+				Schedule:  Schedule{Frequency: YearlyFrequency, Interval: 2},
+				StartDate: "1971-03-01",
+				LastRun:   "2017-03-01",
+			},
+			want: []string{"2019-03-01", "2021-03-01"},
+		},
+		{
+			name: "nothing due before the start date",
+			recurring: RecurringExpense{
+				Schedule:  Schedule{Frequency: DailyFrequency, Interval: 1},
+				StartDate: "2021-06-01",
+			},
+			want: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, occurrence := range test.recurring.DueOccurrences(now) {
+				got = append(got, occurrence.Format(DateLayout))
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("expected: %v, got: %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestRecurringExpenseValidateDateRange(t *testing.T) {
+	recurring := RecurringExpense{StartDate: "2021-04-01", EndDate: "2021-03-31"}
+	if _, ok := recurring.ValidateDateRange().(DataValidationError); !ok {
+		t.Fatalf("expected a data validation error for an end date before the start date")
+	}
+	recurring.EndDate = "2021-04-01"
+	if err := recurring.ValidateDateRange(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	recurring = RecurringExpense{StartDate: "1969-12-31"}
+	if _, ok := recurring.ValidateDateRange().(DataValidationError); !ok {
+		t.Fatalf("expected a data validation error for a start date before %s", minStartDate)
+	}
+}
+
+// dailyDates returns a given amount of consecutive dates from a given date
+func dailyDates(from string, n int) []string {
+	start, _ := time.Parse(DateLayout, from)
+	dates := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		dates = append(dates, start.AddDate(0, 0, i).Format(DateLayout))
+	}
+	return dates
+}
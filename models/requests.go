@@ -1,63 +1,498 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	minPasswordLength     = 8
+	maxCategoryNameLength = 100
+	maxTags               = 20
 )
 
+var tagRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
 // GetAllExpensesRequest represents http request for fetching all expenses with pagination
 type GetAllExpensesRequest struct {
+	UserID   string
+	AllUsers bool
 	Page     int
 	PageSize int
+	Tags     []string
+	TagMatch string
+	// CreatedFrom and CreatedTo are inclusive bounds of the creation time, zero values are ignored
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	// MinPrice and MaxPrice are inclusive bounds of the price in PriceScale digits, zero values are ignored
+	MinPrice   int64
+	MaxPrice   int64
+	Currencies []string
+	// Query matches the expenses whose title contains it, ignoring case
+	Query string
+	Sort  []SortField
+	// Cursor switches from page based to keyset pagination, Page is ignored when it is set
+	Cursor *Cursor
+	// ConvertTo adds the price converted into the given currency to every expense, when set
+	ConvertTo string
+}
+
+// Validate validates the fetch all expenses incoming request
+func (r GetAllExpensesRequest) Validate() error {
+	switch r.TagMatch {
+	case "", AnyTagMatch, AllTagMatch:
+	default:
+		return DataValidationError{Message: "tag_match must be one of: " + AnyTagMatch + "," + AllTagMatch}
+	}
+	if !r.CreatedFrom.IsZero() && !r.CreatedTo.IsZero() && r.CreatedFrom.After(r.CreatedTo) {
+		return DataValidationError{Message: "created_from must not be after created_to"}
+	}
+	if r.MinPrice < 0 || r.MaxPrice < 0 {
+		return DataValidationError{Message: "min_price and max_price must not be negative"}
+	}
+	if r.MaxPrice > 0 && r.MinPrice > r.MaxPrice {
+		return DataValidationError{Message: "min_price must not be greater than max_price"}
+	}
+	for _, currency := range r.Currencies {
+		if err := validateCurrency(currency); err != nil {
+			return err
+		}
+	}
+	if r.Cursor != nil && EncodeSort(r.Cursor.Sort) != EncodeSort(r.Sort) {
+		return DataValidationError{Message: "cursor was issued for a different sort"}
+	}
+	if err := validateConvertTo(r.ConvertTo); err != nil {
+		return err
+	}
+	return validateTags(r.Tags)
 }
 
 // GetExpensesByIDsRequest represents http request for fetching a list of expenses by ids
 type GetExpensesByIDsRequest struct {
-	IDs []string
+	UserID    string
+	AllUsers  bool
+	IDs       []string
+	ConvertTo string
+}
+
+// Validate validates the fetch expenses by ids incoming request
+func (r GetExpensesByIDsRequest) Validate() error {
+	return validateConvertTo(r.ConvertTo)
+}
+
+// GetRatesRequest represents http request for fetching the exchange rates of an inclusive date range
+type GetRatesRequest struct {
+	From string
+	To   string
+}
+
+// Validate validates the fetch rates incoming request
+func (r GetRatesRequest) Validate() error {
+	for _, date := range []string{r.From, r.To} {
+		if _, err := time.Parse(DateLayout, date); date != "" && err != nil {
+			return DataValidationError{Message: fmt.Sprintf("invalid date: %s, expected: %s", date, DateLayout)}
+		}
+	}
+	if r.From != "" && r.To != "" && r.From > r.To {
+		return DataValidationError{Message: "from must not be after to"}
+	}
+	return nil
 }
 
 // CreateExpenseRequest represents http request for creating an expense
 type CreateExpenseRequest struct {
-	Title    string  `json:"title"`
-	Price    float64 `json:"price"`
-	Currency string  `json:"currency"`
+	UserID     string   `json:"-"`
+	Title      string   `json:"title"`
+	Price      Money    `json:"price"`
+	CategoryID string   `json:"category_id"`
+	Tags       []string `json:"tags"`
+	// SourceID identifies expenses created by the application, such as recurring occurrences.
+	// Creating a second expense of the same source results in a ConflictError
+	SourceID string `json:"-"`
+	// Transaction represents the bank transaction of expenses imported from bank statements
+	Transaction *BankTransaction `json:"-"`
+	// CreatedAt backdates expenses created by the application, the current time is used when it is zero
+	CreatedAt time.Time `json:"-"`
+}
+
+// UnmarshalJSON decodes the create expense request, the price is decoded the same way as the expense price
+func (r *CreateExpenseRequest) UnmarshalJSON(data []byte) error {
+	type request CreateExpenseRequest
+	var raw struct {
+		request
+		Price    json.RawMessage `json:"price"`
+		Currency string          `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	price, err := priceJSON{Price: raw.Price, Currency: raw.Currency}.money()
+	if err != nil {
+		return err
+	}
+	*r = CreateExpenseRequest(raw.request)
+	r.Price = Money{}
+	if price != nil {
+		r.Price = *price
+	}
+	return nil
 }
 
 // Validate validates the create expense incoming request
 func (r CreateExpenseRequest) Validate() error {
-	return validateExpenseReqBody(r.Title, r.Currency, r.Price, false)
+	if err := validateTags(r.Tags); err != nil {
+		return err
+	}
+	return validateExpenseReqBody(r.Title, r.CategoryID, &r.Price, false)
 }
 
 // UpdateExpenseRequest represents http request for updating an expense
 type UpdateExpenseRequest struct {
-	ID       string
-	Title    string  `json:"title"`
-	Price    float64 `json:"price"`
-	Currency string  `json:"currency"`
+	ID     string
+	UserID string `json:"-"`
+	Title  string `json:"title"`
+	Price  *Money `json:"price"`
+	// CategoryID replaces the expense category when present, an empty ID makes the expense uncategorized
+	CategoryID *string `json:"category_id"`
+	// Tags replaces the expense tags when present, an empty list removes all of them
+	Tags []string `json:"tags"`
+}
+
+// UnmarshalJSON decodes the update expense request, the price is decoded the same way as the expense price,
+// a price replaces both the amount and the currency, so a decimal price must be sent along with its currency
+func (r *UpdateExpenseRequest) UnmarshalJSON(data []byte) error {
+	type request UpdateExpenseRequest
+	var raw struct {
+		request
+		Price    json.RawMessage `json:"price"`
+		Currency string          `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	price, err := priceJSON{Price: raw.Price, Currency: raw.Currency}.money()
+	if err != nil {
+		return err
+	}
+	*r = UpdateExpenseRequest(raw.request)
+	r.Price = price
+	return nil
 }
 
 // Validate validates the update expense incoming request
 func (r UpdateExpenseRequest) Validate() error {
-	return validateExpenseReqBody(r.Title, r.Currency, r.Price, true)
+	if err := validateTags(r.Tags); err != nil {
+		return err
+	}
+	var categoryID string
+	if r.CategoryID != nil {
+		categoryID = *r.CategoryID
+	}
+	return validateExpenseReqBody(r.Title, categoryID, r.Price, true)
+}
+
+// SignupRequest represents http request for signing up a new user
+type SignupRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Validate validates the signup incoming request
+func (r SignupRequest) Validate() error {
+	email := strings.TrimSpace(r.Email)
+	if email == "" || !strings.Contains(email, "@") {
+		return DataValidationError{Message: "email must be a valid email address"}
+	}
+	if len(r.Password) < minPasswordLength {
+		return DataValidationError{Message: fmt.Sprintf("password must be at least %d characters long", minPasswordLength)}
+	}
+	return nil
+}
+
+// LoginRequest represents http request for logging in an existing user
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Validate validates the login incoming request
+func (r LoginRequest) Validate() error {
+	if strings.TrimSpace(r.Email) == "" || r.Password == "" {
+		return DataValidationError{Message: "email and password should not be empty"}
+	}
+	return nil
+}
+
+// CreateAPIKeyRequest represents http request for creating a personal API key
+type CreateAPIKeyRequest struct {
+	UserID    string     `json:"-"`
+	Name      string     `json:"name"`
+	Scope     string     `json:"scope"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// Validate validates the create API key incoming request
+func (r CreateAPIKeyRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return DataValidationError{Message: "name should not be empty"}
+	}
+	switch r.Scope {
+	case "", ReadOnlyScope, ReadWriteScope:
+	default:
+		return DataValidationError{Message: "scope must be one of: " + ReadOnlyScope + "," + ReadWriteScope}
+	}
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(time.Now()) {
+		return DataValidationError{Message: "expires_at must be in the future"}
+	}
+	return nil
+}
+
+// UpdateUserRoleRequest represents http request for changing the role of a user
+type UpdateUserRoleRequest struct {
+	ID     string `json:"-"`
+	Role   string `json:"role"`
+	UserID string `json:"-"`
+}
+
+// Validate validates the update user role incoming request
+func (r UpdateUserRoleRequest) Validate() error {
+	if !ValidRole(r.Role) {
+		return DataValidationError{
+			Message: "role must be one of: " + strings.Join([]string{AdminRole, MemberRole, ReadOnlyRole}, ","),
+		}
+	}
+	return nil
+}
+
+// CategoryRequest represents http request for creating or updating an expense category
+type CategoryRequest struct {
+	ID     string `json:"-"`
+	UserID string `json:"-"`
+	Name   string `json:"name"`
+}
+
+// Validate validates the category incoming request
+func (r CategoryRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return DataValidationError{Message: "name should not be empty"}
+	}
+	if len(r.Name) > maxCategoryNameLength {
+		return DataValidationError{Message: fmt.Sprintf("name must be at most %d characters long", maxCategoryNameLength)}
+	}
+	return nil
+}
+
+// DeleteCategoryRequest represents http request for deleting an expense category
+type DeleteCategoryRequest struct {
+	ID     string
+	UserID string
+	// Reassign moves the expenses of the category to uncategorized instead of rejecting the deletion
+	Reassign bool
+}
+
+// CreateBudgetRequest represents http request for creating a budget, budgets are monthly unless a period is given
+type CreateBudgetRequest struct {
+	UserID     string `json:"-"`
+	Amount     Money  `json:"amount"`
+	Period     string `json:"period"`
+	CategoryID string `json:"category_id"`
+}
+
+// Validate validates the create budget incoming request
+func (r CreateBudgetRequest) Validate() error {
+	if err := validateBudgetAmount(&r.Amount); err != nil {
+		return err
+	}
+	if r.Period != "" {
+		if err := validateBudgetPeriod(r.Period); err != nil {
+			return err
+		}
+	}
+	return validateCategoryID(r.CategoryID)
+}
+
+// UpdateBudgetRequest represents http request for updating a budget, an empty category ID makes the budget overall
+type UpdateBudgetRequest struct {
+	ID         string  `json:"-"`
+	UserID     string  `json:"-"`
+	Amount     *Money  `json:"amount"`
+	Period     string  `json:"period"`
+	CategoryID *string `json:"category_id"`
+}
+
+// Validate validates the update budget incoming request
+func (r UpdateBudgetRequest) Validate() error {
+	if r.Amount != nil {
+		if err := validateBudgetAmount(r.Amount); err != nil {
+			return err
+		}
+	}
+	if r.Period != "" {
+		if err := validateBudgetPeriod(r.Period); err != nil {
+			return err
+		}
+	}
+	if r.CategoryID != nil {
+		return validateCategoryID(*r.CategoryID)
+	}
+	return nil
+}
+
+// BudgetStatusRequest represents http request for fetching the status of a budget within the period of a given day
+type BudgetStatusRequest struct {
+	ID     string
+	UserID string
+	At     time.Time
+}
+
+// CreateRecurringExpenseRequest represents http request for creating a recurring expense
+type CreateRecurringExpenseRequest struct {
+	UserID    string          `json:"-"`
+	Template  ExpenseTemplate `json:"template"`
+	Schedule  Schedule        `json:"schedule"`
+	StartDate string          `json:"start_date"`
+	EndDate   string          `json:"end_date"`
+}
+
+// Validate validates the create recurring expense incoming request
+func (r CreateRecurringExpenseRequest) Validate() error {
+	if err := validateTemplate(r.Template); err != nil {
+		return err
+	}
+	if err := validateSchedule(r.Schedule); err != nil {
+		return err
+	}
+	return validateDateRange(r.StartDate, r.EndDate)
+}
+
+// UpdateRecurringExpenseRequest represents http request for updating a recurring expense,
+// the template and the schedule get replaced when present and an empty end date removes the end of the schedule
+type UpdateRecurringExpenseRequest struct {
+	ID       string           `json:"-"`
+	UserID   string           `json:"-"`
+	Template *ExpenseTemplate `json:"template"`
+	Schedule *Schedule        `json:"schedule"`
+	EndDate  *string          `json:"end_date"`
 }
 
-func validateExpenseReqBody(title, currency string, price float64, optional bool) error {
+// Validate validates the update recurring expense incoming request
+func (r UpdateRecurringExpenseRequest) Validate() error {
+	if r.Template != nil {
+		if err := validateTemplate(*r.Template); err != nil {
+			return err
+		}
+	}
+	if r.Schedule != nil {
+		if err := validateSchedule(*r.Schedule); err != nil {
+			return err
+		}
+	}
+	if r.EndDate != nil && *r.EndDate != "" {
+		if _, err := time.Parse(DateLayout, *r.EndDate); err != nil {
+			return DataValidationError{Message: fmt.Sprintf("invalid end_date: %s, expected: %s", *r.EndDate, DateLayout)}
+		}
+	}
+	return nil
+}
+
+// CreateAttachmentRequest represents http request for attaching a file to an expense
+type CreateAttachmentRequest struct {
+	UserID    string
+	ExpenseID string
+	Filename  string
+	Content   io.Reader
+}
+
+// NormalizeTags lower cases, trims, sorts and removes duplicates from a list of tags, keeping nil lists nil
+func NormalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	unique, res := map[string]bool{}, make([]string, 0, len(tags))
+	for _, tag := range tags {
+		t := strings.ToLower(strings.TrimSpace(tag))
+		if !unique[t] {
+			unique[t] = true
+			res = append(res, t)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+func validateTags(tags []string) error {
+	if len(tags) > maxTags {
+		return DataValidationError{Message: fmt.Sprintf("at most %d tags are allowed", maxTags)}
+	}
+	for _, tag := range NormalizeTags(tags) {
+		if !tagRegexp.MatchString(tag) {
+			return DataValidationError{
+				Message: fmt.Sprintf("invalid tag: %s, tags may only contain letters, digits, - and _", tag),
+			}
+		}
+	}
+	return nil
+}
+
+func validateExpenseReqBody(title, categoryID string, price *Money, optional bool) error {
 	if !optional && strings.TrimSpace(title) == "" {
 		return DataValidationError{Message: "title should not be empty"}
 	}
 
-	if !optional && price <= 0 || optional && price < 0 {
+	if err := validateCategoryID(categoryID); err != nil {
+		return err
+	}
+
+	if optional && price == nil {
+		return nil
+	}
+	if price == nil || price.Currency == "" {
+		return DataValidationError{Message: "price should not be empty"}
+	}
+	if price.Amount <= 0 {
 		return DataValidationError{Message: "price must be greater than 0"}
 	}
+	return price.Validate()
+}
 
-	currencies := []string{"USD", "EUR", "GBP", "MDL"}
-	c := strings.TrimSpace(strings.ToUpper(currency))
-	if len(c) == 0 && optional {
+func validateCategoryID(categoryID string) error {
+	if categoryID == "" {
 		return nil
 	}
-	switch c {
-	case currencies[0], currencies[1], currencies[2], currencies[3]:
+	if _, err := uuid.Parse(categoryID); err != nil {
+		return DataValidationError{Message: fmt.Sprintf("invalid category_id: %s", categoryID)}
+	}
+	return nil
+}
+
+func validateBudgetAmount(amount *Money) error {
+	if amount.Currency == "" {
+		return DataValidationError{Message: "amount should not be empty"}
+	}
+	if amount.Amount <= 0 {
+		return DataValidationError{Message: "amount must be greater than 0"}
+	}
+	return amount.Validate()
+}
+
+func validateCurrency(currency string) error {
+	c := strings.TrimSpace(strings.ToUpper(currency))
+	if _, ok := LookupCurrency(c); !ok {
+		return DataValidationError{Message: fmt.Sprintf("unsupported currency: %s", c)}
+	}
+	return nil
+}
+
+func validateConvertTo(currency string) error {
+	if currency == "" {
 		return nil
-	default:
-		return DataValidationError{Message: "currency must be one of: " + strings.Join(currencies, ",")}
 	}
+	return validateCurrency(currency)
 }
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// PriceSortField represents the expense price sort field
+	PriceSortField = "price"
+	// TitleSortField represents the expense title sort field
+	TitleSortField = "title"
+	// CreatedAtSortField represents the expense creation time sort field
+	CreatedAtSortField = "created_at"
+	// ModifiedAtSortField represents the expense last modification time sort field
+	ModifiedAtSortField = "modified_at"
+
+	descSortPrefix = "-"
+)
+
+var sortableFields = []string{PriceSortField, TitleSortField, CreatedAtSortField, ModifiedAtSortField}
+
+// DefaultSort represents the order of expense listings that do not ask for a specific one
+var DefaultSort = []SortField{{Field: CreatedAtSortField}}
+
+// SortField represents an expense field that expense listings get ordered by, ties are always broken by ID
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// String returns the sort field in its query param form, such as: -price
+func (f SortField) String() string {
+	if f.Desc {
+		return descSortPrefix + f.Field
+	}
+	return f.Field
+}
+
+// EncodeSort encodes a list of sort fields back into its query param form, such as: price,-created_at
+func EncodeSort(fields []SortField) string {
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, field.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseSort parses a comma separated list of sort fields, such as: price,-created_at
+func ParseSort(param string) ([]SortField, error) {
+	var fields []SortField
+	seen := map[string]bool{}
+	for _, part := range strings.Split(param, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field := SortField{
+			Field: strings.TrimPrefix(part, descSortPrefix),
+			Desc:  strings.HasPrefix(part, descSortPrefix),
+		}
+		if !isSortable(field.Field) {
+			return nil, DataValidationError{
+				Message: fmt.Sprintf("invalid sort field: %s, must be one of: %s", field.Field, strings.Join(sortableFields, ",")),
+			}
+		}
+		if seen[field.Field] {
+			return nil, DataValidationError{Message: fmt.Sprintf("duplicate sort field: %s", field.Field)}
+		}
+		seen[field.Field] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func isSortable(field string) bool {
+	for _, sortable := range sortableFields {
+		if field == sortable {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"time"
+)
+
+const (
+	// OFXStatementFormat represents the OFX bank statement format, both the SGML and the XML flavours, QFX included
+	OFXStatementFormat = "ofx"
+	// QIFStatementFormat represents the Quicken Interchange Format bank statement format
+	QIFStatementFormat = "qif"
+
+	// MonthFirstDateOrder represents QIF dates written month first, such as 12/31/2020
+	MonthFirstDateOrder = "mdy"
+	// DayFirstDateOrder represents QIF dates written day first, such as 31/12/2020
+	DayFirstDateOrder = "dmy"
+)
+
+// BankTransaction represents the bank transaction an expense was imported from
+type BankTransaction struct {
+	// ID represents the transaction ID given by the bank, the FITID of OFX statements
+	ID       string    `json:"id"`
+	PostedAt time.Time `json:"posted_at"`
+}
+
+// StatementTransaction represents a transaction parsed from a bank statement, debits have negative amounts
+type StatementTransaction struct {
+	ID      string
+	Account string
+	// PostedAt represents the date the transaction was posted on, UserAt the date it was initiated on when known
+	PostedAt time.Time
+	UserAt   time.Time
+	Amount   Money
+	Payee    string
+	Memo     string
+	Category string
+}
+
+// SourceID returns the source ID of the expense imported from the transaction for a given user, see CreateExpenseRequest.
+// Banks only guarantee transaction IDs to be unique per account, so the source ID is derived from the user, account and ID
+func (t StatementTransaction) SourceID(userID string) string {
+	sum := sha256.Sum256([]byte(userID + "\x00" + t.Account + "\x00" + t.ID))
+	return "statement:" + hex.EncodeToString(sum[:])
+}
+
+// ImportStatementRequest represents http request for importing expenses from a bank statement
+type ImportStatementRequest struct {
+	UserID string
+	Format string
+	Mode   string
+	// Currency represents the currency of statements without one, QIF statements never have one
+	Currency string
+	// DateOrder represents the order of QIF dates, it gets detected from the statement when empty
+	DateOrder string
+	DryRun    bool
+	Content   io.Reader
+}
+
+// Validate validates the import statement incoming request
+func (r ImportStatementRequest) Validate() error {
+	if err := validateImportMode(r.Mode); err != nil {
+		return err
+	}
+	switch r.Format {
+	case OFXStatementFormat, QIFStatementFormat:
+	default:
+		return DataValidationError{Message: "format must be one of: " + OFXStatementFormat + "," + QIFStatementFormat}
+	}
+	switch r.DateOrder {
+	case "", MonthFirstDateOrder, DayFirstDateOrder:
+	default:
+		return DataValidationError{
+			Message: "date_order must be one of: " + MonthFirstDateOrder + "," + DayFirstDateOrder,
+		}
+	}
+	if r.Currency != "" {
+		return validateCurrency(r.Currency)
+	}
+	if r.Format == QIFStatementFormat {
+		return DataValidationError{Message: "currency is required, since QIF statements have none"}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// MonthSummaryGroup represents the summary buckets of calendar months, keyed as 2006-01
+	MonthSummaryGroup = "month"
+	// WeekSummaryGroup represents the summary buckets of ISO weeks, keyed as 2006-W01
+	WeekSummaryGroup = "week"
+	// DaySummaryGroup represents the summary buckets of days, keyed as 2006-01-02
+	DaySummaryGroup = "day"
+	// CategorySummaryGroup represents the summary buckets of categories, keyed by category ID
+	CategorySummaryGroup = "category"
+	// CurrencySummaryGroup represents the summary buckets of currencies, keyed by currency code
+	CurrencySummaryGroup = "currency"
+)
+
+var summaryGroups = []string{
+	MonthSummaryGroup,
+	WeekSummaryGroup,
+	DaySummaryGroup,
+	CategorySummaryGroup,
+	CurrencySummaryGroup,
+}
+
+// SummaryRequest represents http request for fetching the aggregated spending of a period
+type SummaryRequest struct {
+	UserID   string
+	AllUsers bool
+	GroupBy  string
+	// From and To are inclusive bounds of the creation time, zero values are ignored
+	From time.Time
+	To   time.Time
+	// ConvertTo merges the buckets of every currency into one bucket of the given currency, when set
+	ConvertTo string
+}
+
+// Validate validates the fetch summary incoming request
+func (r SummaryRequest) Validate() error {
+	valid := false
+	for _, group := range summaryGroups {
+		valid = valid || r.GroupBy == group
+	}
+	if !valid {
+		return DataValidationError{Message: "group_by must be one of: " + strings.Join(summaryGroups, ",")}
+	}
+	if !r.From.IsZero() && !r.To.IsZero() && r.From.After(r.To) {
+		return DataValidationError{Message: "from must not be after to"}
+	}
+	return validateConvertTo(r.ConvertTo)
+}
+
+// SummaryBucket represents the aggregated spending of one bucket, amounts of different currencies never share a bucket
+type SummaryBucket struct {
+	Key      string `json:"key"`
+	Currency string `json:"currency"`
+	Count    int    `json:"count"`
+	Total    Money  `json:"total"`
+	Min      Money  `json:"min"`
+	Max      Money  `json:"max"`
+	Average  Money  `json:"average"`
+	// Date splits the buckets by creation day, so that they get converted with the exchange rates of that day
+	Date string `json:"-"`
+}
+
+// Add adds an amount of the bucket currency to the bucket
+func (b *SummaryBucket) Add(m Money) {
+	if b.Count == 0 || m.Amount < b.Min.Amount {
+		b.Min = m
+	}
+	if b.Count == 0 || m.Amount > b.Max.Amount {
+		b.Max = m
+	}
+	b.Total.Amount += m.Amount
+	b.Total.Currency = m.Currency
+	b.Count++
+}
+
+// Merge merges another bucket of the same currency into the bucket
+func (b *SummaryBucket) Merge(other SummaryBucket) {
+	if b.Count == 0 || other.Min.Amount < b.Min.Amount {
+		b.Min = other.Min
+	}
+	if b.Count == 0 || other.Max.Amount > b.Max.Amount {
+		b.Max = other.Max
+	}
+	b.Total.Amount += other.Total.Amount
+	b.Total.Currency = other.Total.Currency
+	b.Count += other.Count
+}
+
+// SetAverage computes the bucket average, rounded half away from zero to the currency minor units
+func (b *SummaryBucket) SetAverage() {
+	if b.Count == 0 {
+		return
+	}
+	digits, ok := MinorUnits(b.Total.Currency)
+	if !ok {
+		digits = PriceScale
+	}
+	unit := minorUnit(digits)
+	total, count := b.Total.Amount, int64(b.Count)*unit
+	avg := total / count
+	if rem := total % count; 2*abs(rem) >= count {
+		if total < 0 {
+			avg--
+		} else {
+			avg++
+		}
+	}
+	b.Average = Money{Amount: avg * unit, Currency: b.Total.Currency}
+}
+
+// SummaryKey returns the key of the bucket a given expense belongs to
+func SummaryKey(groupBy string, expense Expense) string {
+	createdAt := expense.CreatedAt.UTC()
+	switch groupBy {
+	case MonthSummaryGroup:
+		return createdAt.Format("2006-01")
+	case WeekSummaryGroup:
+		year, week := createdAt.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	case DaySummaryGroup:
+		return createdAt.Format(DateLayout)
+	case CategorySummaryGroup:
+		return expense.CategoryID
+	default:
+		return expense.Price.Currency
+	}
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+)
+
+func TestSummaryBucketSetAverage(t *testing.T) {
+	var bucket SummaryBucket
+	for _, amount := range []int64{10000, 10000, 10100} {
+		bucket.Add(Money{Amount: amount, Currency: "USD"})
+	}
+	bucket.SetAverage()
+
+	want := SummaryBucket{
+		Count:   3,
+		Total:   Money{Amount: 30100, Currency: "USD"},
+		Min:     Money{Amount: 10000, Currency: "USD"},
+		Max:     Money{Amount: 10100, Currency: "USD"},
+		Average: Money{Amount: 10000, Currency: "USD"},
+	}
+	if bucket != want {
+		t.Fatalf("expected: %+v, got: %+v", want, bucket)
+	}
+
+	bucket.Merge(SummaryBucket{Count: 1, Total: Money{Amount: -500, Currency: "USD"}, Min: Money{Amount: -500, Currency: "USD"}})
+	bucket.SetAverage()
+	if bucket.Min.Amount != -500 || bucket.Average.Amount != 7400 {
+		t.Fatalf("expected min: -500 and average: 7400, got: %+v", bucket)
+	}
+}
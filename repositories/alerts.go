@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// Alerts represents the budget Alerts repository interface. Alerts are unique per budget, period and threshold,
+// creating an alert that already exists results in a models.ConflictError
+type Alerts interface {
+	CreateAlert(alert models.BudgetAlert) error
+	UpdateAlert(alert models.BudgetAlert) error
+	// GetAlerts fetches the alerts of a budget ordered by period start and threshold
+	GetAlerts(userID, budgetID string) ([]models.BudgetAlert, error)
+}
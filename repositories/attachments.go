@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// Attachments represents the expense Attachments metadata repository interface,
+// every method is scoped to the owner user ID and the expense ID
+type Attachments interface {
+	GetAttachments(userID, expenseID string) ([]models.Attachment, error)
+	GetAttachment(userID, expenseID, id string) (models.Attachment, error)
+	CreateAttachment(attachment models.Attachment) error
+	DeleteAttachment(userID, expenseID, id string) error
+}
@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"io"
+)
+
+// Blobs represents the blob storage interface that keeps the content of files, such as expense attachments
+type Blobs interface {
+	PutBlob(id string, content io.Reader) error
+	GetBlob(id string) (io.ReadCloser, error)
+	DeleteBlob(id string) error
+}
@@ -5,7 +5,9 @@ import (
 	"crypto/md5"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/boltdb/bolt"
@@ -18,10 +20,30 @@ import (
 
 // BoltDB buckets
 var (
-	expensesBucket    = []byte("expenses")
-	expensesIDsBucket = []byte("expenses_ids")
+	expensesBucket     = []byte("expenses")
+	expensesIDsBucket  = []byte("expenses_ids")
+	expensesTagsBucket = []byte("expenses_tags")
+	usersBucket        = []byte("users")
+	usersEmailsBucket  = []byte("users_emails")
+	sessionsBucket     = []byte("sessions")
+	apiKeysBucket      = []byte("api_keys")
+	apiKeysHashBucket  = []byte("api_keys_hashes")
+	categoriesBucket   = []byte("categories")
+	ratesBucket        = []byte("rates")
+	budgetsBucket      = []byte("budgets")
+	budgetAlertsBucket = []byte("budget_alerts")
+	// expensesSourcesBucket maps the source IDs of expenses created by the application to the expense IDs
+	expensesSourcesBucket = []byte("expenses_sources")
+	// expensesSourceIDsBucket maps the IDs of expenses created by the application to their source IDs,
+	// so that deleting an expense frees its source ID like on MariaDB
+	expensesSourceIDsBucket = []byte("expenses_source_ids")
+	recurringBucket         = []byte("recurring_expenses")
+	attachmentsBucket       = []byte("attachments")
 )
 
+// streamChunkSize represents how many expenses StreamExpenses reads within a single transaction
+var streamChunkSize = 500
+
 // BoltDriver represents BoltDB repository driver
 type BoltDriver struct {
 	boltDB *bolt.DB
@@ -35,50 +57,233 @@ func NewBoltDriver(filename string) (*BoltDriver, error) {
 		return nil, err
 	}
 
+	err = db.Update(func(tx *bolt.Tx) error {
+		buckets := [][]byte{
+			expensesBucket,
+			expensesIDsBucket,
+			expensesTagsBucket,
+			usersBucket,
+			usersEmailsBucket,
+			sessionsBucket,
+			apiKeysBucket,
+			apiKeysHashBucket,
+			categoriesBucket,
+			ratesBucket,
+			budgetsBucket,
+			budgetAlertsBucket,
+			expensesSourcesBucket,
+			recurringBucket,
+			attachmentsBucket,
+		}
+		for _, name := range buckets {
+			if _, e := tx.CreateBucketIfNotExists(name); e != nil {
+				return e
+			}
+		}
+		if tx.Bucket(expensesSourceIDsBucket) != nil {
+			return nil
+		}
+		return indexExpenseSources(tx)
+	})
+	if err != nil {
+		logging.Logger.Error("could not create bolt buckets", zap.Error(err))
+		return nil, err
+	}
+
 	driver := &BoltDriver{
 		boltDB: db,
 	}
 	return driver, nil
 }
 
-// GetAllExpenses fetches all expenses with pagination possibilities from BoltDB
-func (d BoltDriver) GetAllExpenses(page, pageSize int) ([]models.Expense, error) {
-	expenses := make([]models.Expense, 0)
-	err := d.boltDB.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket(expensesBucket)
-		c := bucket.Cursor()
-		for i := 1; i <= pageSize; i++ {
-			keyLookup := []byte(strconv.Itoa(page*pageSize - pageSize + i))
-			k, v := c.Seek(keyLookup)
-			if string(k) == "" {
-				break
+// indexExpenseSources creates the source IDs of the expenses of databases created before deleting an expense
+// freed its source ID. Source IDs of expenses that were already deleted get freed
+func indexExpenseSources(tx *bolt.Tx) error {
+	index, err := tx.CreateBucket(expensesSourceIDsBucket)
+	if err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	err = tx.Bucket(expensesIDsBucket).ForEach(func(k, v []byte) error {
+		existing[string(k[bytes.LastIndexByte(k, ':')+1:])] = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sources := tx.Bucket(expensesSourcesBucket)
+	freed := make([][]byte, 0)
+	err = sources.ForEach(func(k, v []byte) error {
+		if !existing[string(v)] {
+			freed = append(freed, append([]byte{}, k...))
+			return nil
+		}
+		return index.Put(v, k)
+	})
+	if err != nil {
+		return err
+	}
+	for _, k := range freed {
+		if err = sources.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateLegacyExpenses assigns the expenses stored before expenses had owners to the user of a given email,
+// rewriting their uid:id pairs into owner scoped keys. Nothing gets migrated until the user signs up
+func (d BoltDriver) MigrateLegacyExpenses(ownerEmail string) error {
+	var migrated int
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		ids := tx.Bucket(expensesIDsBucket)
+		legacy := map[string][]byte{}
+		err := ids.ForEach(func(k, v []byte) error {
+			if !bytes.Contains(k, []byte(":")) {
+				legacy[string(k)] = append([]byte{}, v...)
 			}
+			return nil
+		})
+		if err != nil || len(legacy) == 0 {
+			return err
+		}
+		ownerID := tx.Bucket(usersEmailsBucket).Get([]byte(ownerEmail))
+		if len(ownerID) == 0 {
+			logging.Logger.Warn(
+				"could not migrate legacy expenses, owner user does not exist",
+				zap.String("email", ownerEmail),
+				zap.Int("expenses", len(legacy)),
+			)
+			return nil
+		}
 
-			expense, err := d.unmarshalExpense(v)
+		bucket := tx.Bucket(expensesBucket)
+		for id, seq := range legacy {
+			fields := map[string]json.RawMessage{}
+			if err = json.Unmarshal(bucket.Get(seq), &fields); err != nil {
+				logging.Logger.Error("could not unmarshal legacy expense", zap.String("id", id), zap.Error(err))
+				return err
+			}
+			fields["owner_id"], _ = json.Marshal(string(ownerID))
+			bs, err := json.Marshal(fields)
 			if err != nil {
 				return err
 			}
-			if bytes.Equal(keyLookup, k) {
-				expenses = append(expenses, expense)
+			if err = bucket.Put(seq, bs); err != nil {
+				return err
 			}
+			if err = ids.Delete([]byte(id)); err != nil {
+				return err
+			}
+			if err = ids.Put(expenseIDKey(string(ownerID), id), seq); err != nil {
+				return err
+			}
+			migrated++
 		}
 		return nil
 	})
+	if err != nil {
+		logging.Logger.Error("could not migrate legacy expenses", zap.Error(err))
+		return err
+	}
+	if migrated > 0 {
+		logging.Logger.Info("migrated legacy expenses", zap.String("email", ownerEmail), zap.Int("expenses", migrated))
+	}
+	return nil
+}
+
+// GetAllExpenses fetches all expenses matched by a given request with pagination possibilities from BoltDB
+func (d BoltDriver) GetAllExpenses(req models.GetAllExpensesRequest) ([]models.Expense, error) {
+	var matched []models.Expense
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		var err error
+		matched, err = d.findExpenses(tx, req)
+		return err
+	})
 	if err != nil {
 		logging.Logger.Error("could not fetch all expenses from db", zap.Error(err))
 		return []models.Expense{}, err
 	}
+	if req.Cursor != nil {
+		return cursorPage(matched, *req.Cursor, req.PageSize), nil
+	}
+
+	expenses := make([]models.Expense, 0)
+	for i := (req.Page - 1) * req.PageSize; i < len(matched) && len(expenses) < req.PageSize; i++ {
+		expenses = append(expenses, matched[i])
+	}
 	return expenses, nil
 }
 
-// GetExpensesByIDs fetches a list of expenses by a given list of IDs from BoldDB
-func (d BoltDriver) GetExpensesByIDs(ids []string) ([]models.Expense, error) {
+// StreamExpenses calls a given function for every expense that matches a request in BoltDB.
+// BoltDB has no secondary indexes, so only the sort fields of the matching expenses are kept in memory to sort them.
+// The expenses are then read in chunks of streamChunkSize keys, each within its own short transaction that ends
+// before the function gets called, so that a slow consumer does not hold a transaction open.
+// Expenses deleted or changed to no longer match while streaming get left out
+func (d BoltDriver) StreamExpenses(req models.GetAllExpensesRequest, fn func(models.Expense) error) error {
+	refs := make([]expenseRef, 0)
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		return d.forEachMatchingExpense(tx, req, func(k []byte, expense models.Expense) error {
+			refs = append(refs, newExpenseRef(append([]byte{}, k...), expense))
+			return nil
+		})
+	})
+	if err != nil {
+		logging.Logger.Error("could not stream expenses from db", zap.Error(err))
+		return err
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return lessExpense(refs[i].sortKey, refs[j].sortKey, req.Sort)
+	})
+
+	for len(refs) > 0 {
+		size := streamChunkSize
+		if size > len(refs) {
+			size = len(refs)
+		}
+		chunk := make([]models.Expense, 0, size)
+		err = d.boltDB.View(func(tx *bolt.Tx) error {
+			bucket := tx.Bucket(expensesBucket)
+			for _, ref := range refs[:size] {
+				data := bucket.Get(ref.key)
+				if data == nil {
+					continue
+				}
+				expense, err := d.unmarshalExpense(data)
+				if err != nil {
+					return err
+				}
+				if req.UserID != "" && expense.OwnerID != req.UserID || !matchesFilters(expense, req) {
+					continue
+				}
+				chunk = append(chunk, expense)
+			}
+			return nil
+		})
+		if err != nil {
+			logging.Logger.Error("could not stream expenses from db", zap.Error(err))
+			return err
+		}
+		refs = refs[size:]
+
+		for _, expense := range chunk {
+			if err = fn(expense); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetExpensesByIDs fetches a list of expenses of a given user by a given list of IDs from BoldDB
+func (d BoltDriver) GetExpensesByIDs(userID string, ids []string) ([]models.Expense, error) {
 	expenses := make([]models.Expense, 0)
 	idsLookup := make([][]byte, 0)
 	err := d.boltDB.View(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(expensesIDsBucket)
 		for _, uid := range ids {
-			id := bucket.Get([]byte(uid))
+			id := lookupExpenseID(bucket, userID, uid)
 			if len(id) == 0 {
 				logging.Logger.Debug(fmt.Sprintf("record with id: %s was not found in db", uid))
 				continue
@@ -104,130 +309,117 @@ func (d BoltDriver) GetExpensesByIDs(ids []string) ([]models.Expense, error) {
 	return expenses, nil
 }
 
-// CreateExpense creates a brand new expense and saves it into BoltDB
-func (d BoltDriver) CreateExpense(title, currency string, price float64) error {
-	var idLookup, uidLookup []byte
+// CreateExpense creates a brand new expense for a given user and saves it into BoltDB
+func (d BoltDriver) CreateExpense(req models.CreateExpenseRequest) (models.Expense, error) {
+	var expense models.Expense
 	err := d.boltDB.Update(func(tx *bolt.Tx) error {
-		bucket, err := tx.CreateBucketIfNotExists(expensesBucket)
-		if err != nil {
-			logging.Logger.Error("could not create bucket", zap.Error(err))
-			return err
-		}
-
-		next, err := bucket.NextSequence()
-		if err != nil {
-			logging.Logger.Error("could not get bucket next sequence", zap.Error(err))
-			return err
-		}
-		idData := []byte(strconv.Itoa(int(next)))
-		id := uuid.NewHash(md5.New(), uuid.NameSpaceURL, idData, 3)
-
-		expense := models.Expense{
-			ID:         id.String(),
-			Title:      title,
-			Currency:   currency,
-			Price:      price,
-			CreatedAt:  time.Now().UTC(),
-			ModifiedAt: time.Now().UTC(),
-		}
-
-		bs, err := json.Marshal(expense)
-		if err != nil {
-			logging.Logger.Error("could not marshal json when creating expense")
-			return err
-		}
-		err = bucket.Put(idData, bs)
-		if err != nil {
-			logging.Logger.Error("could not save expense in db")
-			return err
-		}
-		logging.Logger.Info("successfully saved expense in db")
-		idLookup = idData
-		uidLookup = []byte(id.String())
-		return nil
+		var err error
+		expense, err = d.createExpense(tx, req)
+		return err
 	})
 	if err != nil {
 		logging.Logger.Error("could not create expense in db", zap.Error(err))
-		return err
+		return models.Expense{}, err
 	}
-	return d.setExpenseID(idLookup, uidLookup)
+	return expense, nil
 }
 
-// UpdateExpense updates an existing expense and updates the record in BoltDB
-func (d BoltDriver) UpdateExpense(id, title, currency string, price float64) error {
-	lookupID, err := d.getExpenseID(id)
+// CreateExpenses creates a list of brand new expenses and saves them into BoltDB in a single batch,
+// either all of them get created or none of them
+func (d BoltDriver) CreateExpenses(reqs []models.CreateExpenseRequest) ([]models.Expense, error) {
+	expenses := make([]models.Expense, 0, len(reqs))
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		for _, req := range reqs {
+			expense, err := d.createExpense(tx, req)
+			if err != nil {
+				return err
+			}
+			expenses = append(expenses, expense)
+		}
+		return nil
+	})
 	if err != nil {
-		return err
+		logging.Logger.Error("could not create expenses in db", zap.Error(err))
+		return []models.Expense{}, err
 	}
-	return d.boltDB.Update(func(tx *bolt.Tx) error {
-		var modified bool
-		bucket := tx.Bucket(expensesBucket)
-		expense, err := d.unmarshalExpense(bucket.Get(lookupID))
-		if err != nil {
-			return err
-		}
-		if title != "" && title != expense.Title {
-			expense.Title = title
-			modified = true
-		}
-		if price > 0 && price != expense.Price {
-			expense.Price = price
-			modified = true
-		}
-		if currency != "" && currency != expense.Currency {
-			expense.Currency = currency
-			modified = true
-		}
-		if modified {
-			expense.ModifiedAt = time.Now().UTC()
-		}
-
-		bs, err := json.Marshal(expense)
-		if err != nil {
-			logging.Logger.Error("could not marshal expense for update in db", zap.Error(err))
-			return err
-		}
+	return expenses, nil
+}
 
-		err = bucket.Put(lookupID, bs)
-		if err != nil {
-			logging.Logger.Error("could not update expense in db", zap.Error(err))
-			return err
+// GetExistingSourceIDs finds which of the given source IDs belong to expenses that were already created
+func (d BoltDriver) GetExistingSourceIDs(sourceIDs []string) (map[string]bool, error) {
+	existing := map[string]bool{}
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		sources := tx.Bucket(expensesSourcesBucket)
+		for _, sourceID := range sourceIDs {
+			if sources.Get([]byte(sourceID)) != nil {
+				existing[sourceID] = true
+			}
 		}
 		return nil
 	})
+	if err != nil {
+		logging.Logger.Error("could not get expense sources from db", zap.Error(err))
+		return map[string]bool{}, err
+	}
+	return existing, nil
 }
 
-// DeleteExpense deletes a given expense from BoltDB given a list of IDs
-func (d BoltDriver) DeleteExpense(id string) error {
-	lookupID, err := d.getExpenseID(id)
-	if err != nil {
+// UpdateExpense updates an existing expense of a given user and updates the record in BoltDB
+func (d BoltDriver) UpdateExpense(req models.UpdateExpenseRequest) (models.Expense, error) {
+	var expense models.Expense
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		var err error
+		expense, err = d.updateExpense(tx, req)
 		return err
+	})
+	if err != nil {
+		return models.Expense{}, err
 	}
+	return expense, nil
+}
+
+// DeleteExpense deletes a given expense of a given user from BoltDB
+func (d BoltDriver) DeleteExpense(userID, id string) error {
 	return d.boltDB.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket(expensesBucket)
-		err := bucket.Delete(lookupID)
-		if err != nil {
-			logging.Logger.Error("could not delete expense from db", zap.Error(err))
-			return err
-		}
+		_, err := d.deleteExpense(tx, userID, id)
+		return err
+	})
+}
 
-		bucket = tx.Bucket(expensesIDsBucket)
-		err = bucket.Delete([]byte(id))
-		if err != nil {
-			logging.Logger.Error("could not delete uid:id pair from db", zap.Error(err))
-			return err
-		}
-		return nil
+// ApplyExpensesBatch applies the operations of a batch of a given user in a single BoltDB transaction
+func (d BoltDriver) ApplyExpensesBatch(userID string, ops []models.BatchOperation, bestEffort bool) ([]models.BatchResult, error) {
+	var results []models.BatchResult
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		var err error
+		results, err = applyBatch(ops, bestEffort, func(op models.BatchOperation) (models.Expense, error) {
+			switch op.Op {
+			case models.CreateBatchOperation:
+				return d.createExpense(tx, op.CreateRequest(userID))
+			case models.UpdateBatchOperation:
+				return d.updateExpense(tx, op.UpdateRequest(userID))
+			default:
+				return d.deleteExpense(tx, userID, op.ID)
+			}
+		})
+		return err
 	})
+	if err == errBatchRolledBack {
+		return results, nil
+	}
+	if err != nil {
+		logging.Logger.Error("could not apply expenses batch in db", zap.Error(err))
+		return []models.BatchResult{}, err
+	}
+	return results, nil
 }
 
-// Count fetches the total count from expenses bucket from BoltDB
-func (d BoltDriver) Count() (int, error) {
+// Count fetches the total count of expenses matched by a given request from BoltDB
+func (d BoltDriver) Count(req models.GetAllExpensesRequest) (int, error) {
 	var count int
 	err := d.boltDB.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket(expensesBucket)
-		count = bucket.Stats().KeyN
-		return nil
+		matched, err := d.findExpenses(tx, req)
+		count = len(matched)
+		return err
 	})
 	if err != nil {
 		logging.Logger.Error("could not count total count of expenses", zap.Error(err))
@@ -236,6 +428,88 @@ func (d BoltDriver) Count() (int, error) {
 	return count, nil
 }
 
+// GetTags fetches the tags of a given user along with their usage count from BoltDB
+func (d BoltDriver) GetTags(userID string) ([]models.TagUsage, error) {
+	counts := map[string]int{}
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(expensesTagsBucket).Cursor()
+		prefix := []byte(userID + ":")
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			_, tag, _ := splitExpenseTagKey(k)
+			counts[tag]++
+		}
+		return nil
+	})
+	if err != nil {
+		logging.Logger.Error("could not fetch tags from db", zap.Error(err))
+		return []models.TagUsage{}, err
+	}
+
+	tags := make([]models.TagUsage, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, models.TagUsage{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags, nil
+}
+
+// summaryBucketID represents what tells summary buckets apart
+type summaryBucketID struct {
+	key      string
+	currency string
+	date     string
+}
+
+// Summarize aggregates the expenses matched by a given request into buckets of one currency,
+// scanning every expense within a single read only transaction of BoltDB
+func (d BoltDriver) Summarize(req models.SummaryRequest) ([]models.SummaryBucket, error) {
+	buckets := map[summaryBucketID]*models.SummaryBucket{}
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(expensesBucket).ForEach(func(k, v []byte) error {
+			expense, err := d.unmarshalExpense(v)
+			if err != nil {
+				return err
+			}
+			if req.UserID != "" && expense.OwnerID != req.UserID {
+				return nil
+			}
+			if !req.From.IsZero() && expense.CreatedAt.Before(req.From) {
+				return nil
+			}
+			if !req.To.IsZero() && expense.CreatedAt.After(req.To) {
+				return nil
+			}
+
+			id := summaryBucketID{
+				key:      models.SummaryKey(req.GroupBy, expense),
+				currency: expense.Price.Currency,
+			}
+			if req.ConvertTo != "" {
+				id.date = expense.CreatedAt.UTC().Format(models.DateLayout)
+			}
+			bucket, ok := buckets[id]
+			if !ok {
+				bucket = &models.SummaryBucket{Key: id.key, Currency: id.currency, Date: id.date}
+				buckets[id] = bucket
+			}
+			bucket.Add(expense.Price)
+			return nil
+		})
+	})
+	if err != nil {
+		logging.Logger.Error("could not summarize expenses", zap.Error(err))
+		return []models.SummaryBucket{}, err
+	}
+
+	res := make([]models.SummaryBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		res = append(res, *bucket)
+	}
+	return res, nil
+}
+
 // Close closes the BoltDB database
 func (d BoltDriver) Close() error {
 	logging.Logger.Info("stopping boltdb file database server")
@@ -248,48 +522,476 @@ func (d BoltDriver) Close() error {
 	return nil
 }
 
-func (d BoltDriver) setExpenseID(id []byte, uid []byte) error {
-	return d.boltDB.Update(func(tx *bolt.Tx) error {
-		bucket, err := tx.CreateBucketIfNotExists(expensesIDsBucket)
-		if err != nil {
-			logging.Logger.Error("could not create or open expenses ids bucket", zap.Error(err))
-			return err
+// findExpenseID finds the sequence id of an expense of a given user within a given transaction
+func findExpenseID(tx *bolt.Tx, userID, id string) ([]byte, error) {
+	intID := tx.Bucket(expensesIDsBucket).Get(expenseIDKey(userID, id))
+	if len(intID) == 0 {
+		return nil, models.ResourceNotFoundError{
+			Message: fmt.Sprintf("could not find expense with id: %s", id),
 		}
-		err = bucket.Put(uid, id)
-		if err != nil {
-			logging.Logger.Error("could not save uid:id record in boltdb", zap.Error(err))
-			return err
+	}
+	return intID, nil
+}
+
+// updateExpense updates an existing expense of a given user within a given transaction
+func (d BoltDriver) updateExpense(tx *bolt.Tx, req models.UpdateExpenseRequest) (models.Expense, error) {
+	lookupID, err := findExpenseID(tx, req.UserID, req.ID)
+	if err != nil {
+		return models.Expense{}, err
+	}
+	var modified bool
+	bucket := tx.Bucket(expensesBucket)
+	expense, err := d.unmarshalExpense(bucket.Get(lookupID))
+	if err != nil {
+		return models.Expense{}, err
+	}
+	if req.Title != "" && req.Title != expense.Title {
+		expense.Title = req.Title
+		modified = true
+	}
+	if req.Price != nil && *req.Price != expense.Price {
+		expense.Price = *req.Price
+		modified = true
+	}
+	if req.CategoryID != nil && *req.CategoryID != expense.CategoryID {
+		expense.CategoryID = *req.CategoryID
+		modified = true
+	}
+	if req.Tags != nil && !equalTags(req.Tags, expense.Tags) {
+		if err = deleteExpenseTags(tx, expense); err != nil {
+			return models.Expense{}, err
 		}
+		expense.Tags = req.Tags
+		if err = putExpenseTags(tx, expense, lookupID); err != nil {
+			return models.Expense{}, err
+		}
+		modified = true
+	}
+	if modified {
+		expense.ModifiedAt = expenseTime(time.Now())
+	}
+
+	bs, err := json.Marshal(expense)
+	if err != nil {
+		logging.Logger.Error("could not marshal expense for update in db", zap.Error(err))
+		return models.Expense{}, err
+	}
+	if err = bucket.Put(lookupID, bs); err != nil {
+		logging.Logger.Error("could not update expense in db", zap.Error(err))
+		return models.Expense{}, err
+	}
+	return expense, nil
+}
+
+// deleteExpense deletes an expense of a given user within a given transaction and returns the deleted expense
+func (d BoltDriver) deleteExpense(tx *bolt.Tx, userID, id string) (models.Expense, error) {
+	lookupID, err := findExpenseID(tx, userID, id)
+	if err != nil {
+		return models.Expense{}, err
+	}
+	bucket := tx.Bucket(expensesBucket)
+	expense, err := d.unmarshalExpense(bucket.Get(lookupID))
+	if err != nil {
+		return models.Expense{}, err
+	}
+	if err = deleteExpenseTags(tx, expense); err != nil {
+		logging.Logger.Error("could not delete expense tags from db", zap.Error(err))
+		return models.Expense{}, err
+	}
+	if err = bucket.Delete(lookupID); err != nil {
+		logging.Logger.Error("could not delete expense from db", zap.Error(err))
+		return models.Expense{}, err
+	}
+	if err = tx.Bucket(expensesIDsBucket).Delete(expenseIDKey(userID, id)); err != nil {
+		logging.Logger.Error("could not delete uid:id pair from db", zap.Error(err))
+		return models.Expense{}, err
+	}
+	if err = deleteExpenseSource(tx, expense.ID); err != nil {
+		logging.Logger.Error("could not delete expense source from db", zap.Error(err))
+		return models.Expense{}, err
+	}
+	return expense, nil
+}
+
+// deleteExpenseSource frees the source ID of a deleted expense, if any
+func deleteExpenseSource(tx *bolt.Tx, id string) error {
+	index := tx.Bucket(expensesSourceIDsBucket)
+	sourceID := index.Get([]byte(id))
+	if sourceID == nil {
 		return nil
-	})
+	}
+	if err := tx.Bucket(expensesSourcesBucket).Delete(sourceID); err != nil {
+		return err
+	}
+	return index.Delete([]byte(id))
 }
 
-func (d BoltDriver) getExpenseID(id string) ([]byte, error) {
-	var lookupID []byte
-	err := d.boltDB.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket(expensesIDsBucket)
-		intID := bucket.Get([]byte(id))
-		if len(intID) == 0 {
-			return models.ResourceNotFoundError{
-				Message: fmt.Sprintf("could not find expense with id: %s", id),
-			}
+// createExpense saves a brand new expense of a given user within a given transaction
+func (d BoltDriver) createExpense(tx *bolt.Tx, req models.CreateExpenseRequest) (models.Expense, error) {
+	bucket := tx.Bucket(expensesBucket)
+	sources := tx.Bucket(expensesSourcesBucket)
+	if req.SourceID != "" && sources.Get([]byte(req.SourceID)) != nil {
+		return models.Expense{}, models.ConflictError{
+			Message: fmt.Sprintf("expense of source: %s already exists", req.SourceID),
+		}
+	}
+
+	next, err := bucket.NextSequence()
+	if err != nil {
+		logging.Logger.Error("could not get bucket next sequence", zap.Error(err))
+		return models.Expense{}, err
+	}
+	idData := []byte(strconv.Itoa(int(next)))
+	id := uuid.NewHash(md5.New(), uuid.NameSpaceURL, idData, 3)
+
+	createdAt := expenseTime(time.Now())
+	if !req.CreatedAt.IsZero() {
+		createdAt = expenseTime(req.CreatedAt)
+	}
+	expense := models.Expense{
+		ID:          id.String(),
+		OwnerID:     req.UserID,
+		Title:       req.Title,
+		Price:       req.Price,
+		CategoryID:  req.CategoryID,
+		Tags:        req.Tags,
+		Transaction: req.Transaction,
+		CreatedAt:   createdAt,
+		ModifiedAt:  expenseTime(time.Now()),
+	}
+	if req.SourceID != "" {
+		if err = sources.Put([]byte(req.SourceID), []byte(expense.ID)); err != nil {
+			return models.Expense{}, err
+		}
+		if err = tx.Bucket(expensesSourceIDsBucket).Put([]byte(expense.ID), []byte(req.SourceID)); err != nil {
+			return models.Expense{}, err
 		}
-		lookupID = intID
+	}
+
+	bs, err := json.Marshal(expense)
+	if err != nil {
+		logging.Logger.Error("could not marshal json when creating expense")
+		return models.Expense{}, err
+	}
+	if err = bucket.Put(idData, bs); err != nil {
+		logging.Logger.Error("could not save expense in db")
+		return models.Expense{}, err
+	}
+	if err = putExpenseTags(tx, expense, idData); err != nil {
+		logging.Logger.Error("could not save expense tags in db", zap.Error(err))
+		return models.Expense{}, err
+	}
+	if err = tx.Bucket(expensesIDsBucket).Put(expenseIDKey(req.UserID, expense.ID), idData); err != nil {
+		logging.Logger.Error("could not save uid:id record in boltdb", zap.Error(err))
+		return models.Expense{}, err
+	}
+	return expense, nil
+}
+
+// findExpenses scans the expenses bucket for the expenses matched by a given request, ordered by the request sort fields
+func (d BoltDriver) findExpenses(tx *bolt.Tx, req models.GetAllExpensesRequest) ([]models.Expense, error) {
+	matched := make([]models.Expense, 0)
+	err := d.forEachMatchingExpense(tx, req, func(_ []byte, expense models.Expense) error {
+		matched = append(matched, expense)
 		return nil
 	})
 	if err != nil {
-		logging.Logger.Debug(fmt.Sprintf("could not fetch uid:id for id: %s", id))
-		return []byte{}, err
+		return nil, err
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return lessExpense(matched[i], matched[j], req.Sort)
+	})
+	return matched, nil
+}
+
+// forEachMatchingExpense calls a given function with the key and the value of every expense that matches a request,
+// in the order of the keys
+func (d BoltDriver) forEachMatchingExpense(
+	tx *bolt.Tx,
+	req models.GetAllExpensesRequest,
+	fn func(k []byte, expense models.Expense) error,
+) error {
+	var tagged map[string]bool
+	if len(req.Tags) > 0 {
+		tagged = taggedExpenses(tx, req.UserID, req.Tags, req.TagMatch == models.AllTagMatch)
+	}
+
+	return tx.Bucket(expensesBucket).ForEach(func(k, v []byte) error {
+		if tagged != nil && !tagged[string(k)] {
+			return nil
+		}
+		expense, err := d.unmarshalExpense(v)
+		if err != nil {
+			return err
+		}
+		if req.UserID != "" && expense.OwnerID != req.UserID || !matchesFilters(expense, req) {
+			return nil
+		}
+		return fn(k, expense)
+	})
+}
+
+// expenseRef represents the key of a stored expense along with the fields expenses get sorted by
+type expenseRef struct {
+	key     []byte
+	sortKey models.Expense
+}
+
+func newExpenseRef(k []byte, e models.Expense) expenseRef {
+	return expenseRef{
+		key: k,
+		sortKey: models.Expense{
+			ID:         e.ID,
+			Title:      e.Title,
+			Price:      e.Price,
+			CreatedAt:  e.CreatedAt,
+			ModifiedAt: e.ModifiedAt,
+		},
+	}
+}
+
+// cursorPage picks the page of sorted expenses that follows, or precedes, a given cursor
+func cursorPage(sorted []models.Expense, c models.Cursor, size int) []models.Expense {
+	if c.Before {
+		end := sort.Search(len(sorted), func(i int) bool {
+			return !lessExpense(sorted[i], c.Key, c.Sort)
+		})
+		start := end - size
+		if start < 0 {
+			start = 0
+		}
+		return sorted[start:end]
+	}
+
+	start := sort.Search(len(sorted), func(i int) bool {
+		return lessExpense(c.Key, sorted[i], c.Sort)
+	})
+	end := start + size
+	if end > len(sorted) {
+		end = len(sorted)
+	}
+	return sorted[start:end]
+}
+
+// lessExpense orders expenses by a given list of sort fields, breaking ties by ID, the same way MariaDB does
+func lessExpense(a, b models.Expense, fields []models.SortField) bool {
+	for _, field := range fields {
+		cmp := compareExpenses(a, b, field.Field)
+		if cmp == 0 {
+			continue
+		}
+		if field.Desc {
+			return cmp > 0
+		}
+		return cmp < 0
+	}
+	return a.ID < b.ID
+}
+
+// compareExpenses compares a given field of two expenses, titles are compared ignoring case byte by byte
+func compareExpenses(a, b models.Expense, field string) int {
+	switch field {
+	case models.PriceSortField:
+		switch {
+		case a.Price.Amount < b.Price.Amount:
+			return -1
+		case a.Price.Amount > b.Price.Amount:
+			return 1
+		}
+		return 0
+	case models.TitleSortField:
+		return strings.Compare(sortableTitle(a.Title), sortableTitle(b.Title))
+	case models.CreatedAtSortField:
+		return compareTimes(a.CreatedAt, b.CreatedAt)
+	case models.ModifiedAtSortField:
+		return compareTimes(a.ModifiedAt, b.ModifiedAt)
+	}
+	return 0
+}
+
+// compareTimes compares times with second precision, expenses stored with a finer precision compare like on MariaDB
+func compareTimes(a, b time.Time) int {
+	a, b = expenseTime(a), expenseTime(b)
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+// matchesFilters checks whether an expense satisfies the date, price, currency and title filters of a given request
+func matchesFilters(expense models.Expense, req models.GetAllExpensesRequest) bool {
+	if !req.CreatedFrom.IsZero() && expense.CreatedAt.Before(req.CreatedFrom) {
+		return false
+	}
+	if !req.CreatedTo.IsZero() && expense.CreatedAt.After(req.CreatedTo) {
+		return false
+	}
+	if req.MinPrice > 0 && expense.Price.Amount < req.MinPrice {
+		return false
+	}
+	if req.MaxPrice > 0 && expense.Price.Amount > req.MaxPrice {
+		return false
+	}
+	if len(req.Currencies) > 0 {
+		var found bool
+		for _, currency := range req.Currencies {
+			found = found || currency == expense.Price.Currency
+		}
+		if !found {
+			return false
+		}
+	}
+	return req.Query == "" || strings.Contains(strings.ToLower(expense.Title), strings.ToLower(req.Query))
+}
+
+// taggedExpenses looks up the tags inverted index for the sequence keys of the expenses that have any or all given tags
+func taggedExpenses(tx *bolt.Tx, userID string, tags []string, matchAll bool) map[string]bool {
+	wanted := map[string]bool{}
+	for _, tag := range tags {
+		wanted[tag] = true
+	}
+
+	hits := map[string]int{}
+	c := tx.Bucket(expensesTagsBucket).Cursor()
+	prefix := []byte(userID + ":")
+	if userID == "" {
+		prefix = nil
+	}
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		if _, tag, _ := splitExpenseTagKey(k); wanted[tag] {
+			hits[string(v)]++
+		}
+	}
+
+	matched := map[string]bool{}
+	for seq, count := range hits {
+		if !matchAll || count == len(wanted) {
+			matched[seq] = true
+		}
 	}
-	return lookupID, nil
+	return matched
+}
+
+// putExpenseTags adds the owner:tag:uid -> seq pairs of an expense to the tags inverted index
+func putExpenseTags(tx *bolt.Tx, expense models.Expense, seq []byte) error {
+	bucket := tx.Bucket(expensesTagsBucket)
+	for _, tag := range expense.Tags {
+		if err := bucket.Put(expenseTagKey(expense.OwnerID, tag, expense.ID), seq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteExpenseTags removes the owner:tag:uid -> seq pairs of an expense from the tags inverted index
+func deleteExpenseTags(tx *bolt.Tx, expense models.Expense) error {
+	bucket := tx.Bucket(expensesTagsBucket)
+	for _, tag := range expense.Tags {
+		if err := bucket.Delete(expenseTagKey(expense.OwnerID, tag, expense.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func expenseTagKey(userID, tag, id string) []byte {
+	return []byte(userID + ":" + tag + ":" + id)
+}
+
+func splitExpenseTagKey(key []byte) (userID, tag, id string) {
+	parts := strings.SplitN(string(key), ":", 3)
+	if len(parts) != 3 {
+		return "", "", ""
+	}
+	return parts[0], parts[1], parts[2]
+}
+
+func equalTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// expenseIDKey builds the owner scoped key of the uid:id pairs from the expenses ids bucket
+func expenseIDKey(userID, id string) []byte {
+	return []byte(userID + ":" + id)
+}
+
+// lookupExpenseID finds the sequence id of an expense, looking through the expenses of all users for an empty user ID
+func lookupExpenseID(bucket *bolt.Bucket, userID, id string) []byte {
+	if userID != "" {
+		return bucket.Get(expenseIDKey(userID, id))
+	}
+	suffix := []byte(":" + id)
+	c := bucket.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if bytes.HasSuffix(k, suffix) {
+			return v
+		}
+	}
+	return nil
+}
+
+// legacyExpensePrice represents the price encoding of expenses stored before prices became fixed-point money,
+// float prices may hold more decimal digits than the minor units of their currency
+type legacyExpensePrice struct {
+	Price    json.Number `json:"price"`
+	Currency string      `json:"currency"`
 }
 
 func (d BoltDriver) unmarshalExpense(data []byte) (models.Expense, error) {
 	var expense models.Expense
 	err := json.Unmarshal(data, &expense)
 	if err != nil {
-		logging.Logger.Error("could not unmarshal expense", zap.Error(err))
+		var legacy legacyExpensePrice
+		if json.Unmarshal(data, &legacy) != nil {
+			logging.Logger.Error("could not unmarshal expense", zap.Error(err))
+			return models.Expense{}, err
+		}
+		return d.unmarshalLegacyExpense(data, legacy)
+	}
+	return expense, nil
+}
+
+// unmarshalLegacyExpense decodes an expense whose float price has more decimal digits than PriceScale,
+// rounding the price to the minor units of its currency
+func (d BoltDriver) unmarshalLegacyExpense(data []byte, legacy legacyExpensePrice) (models.Expense, error) {
+	f, err := legacy.Price.Float64()
+	digits, ok := models.MinorUnits(strings.ToUpper(legacy.Currency))
+	if !ok {
+		digits = models.PriceScale
+	}
+	var price models.Money
+	if err == nil {
+		price, err = models.ParseStoredMoney(strconv.FormatFloat(f, 'f', digits, 64), legacy.Currency)
+	}
+	if err != nil {
+		logging.Logger.Error("could not convert legacy expense price", zap.Error(err))
+		return models.Expense{}, err
+	}
+
+	var expense models.Expense
+	fields := map[string]json.RawMessage{}
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return models.Expense{}, err
+	}
+	delete(fields, "price")
+	delete(fields, "currency")
+	bs, _ := json.Marshal(fields)
+	if err = json.Unmarshal(bs, &expense); err != nil {
+		logging.Logger.Error("could not unmarshal legacy expense", zap.Error(err))
 		return models.Expense{}, err
 	}
+	expense.Price = price
 	return expense, nil
 }
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/boltdb/bolt"
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// CreateAlert saves a brand new budget alert into BoltDB, unless the same alert was already fired
+func (d BoltDriver) CreateAlert(alert models.BudgetAlert) error {
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(budgetAlertsBucket)
+		key := budgetAlertKey(alert)
+		if bucket.Get(key) != nil {
+			return models.ConflictError{
+				Message: fmt.Sprintf("alert of %d%% was already fired for budget with id: %s", alert.Threshold, alert.BudgetID),
+			}
+		}
+		return putBudgetAlert(bucket, key, alert)
+	})
+	if err != nil {
+		logging.Logger.Debug("could not create budget alert in db", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UpdateAlert updates the delivery status of a budget alert in BoltDB
+func (d BoltDriver) UpdateAlert(alert models.BudgetAlert) error {
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		return putBudgetAlert(tx.Bucket(budgetAlertsBucket), budgetAlertKey(alert), alert)
+	})
+	if err != nil {
+		logging.Logger.Error("could not update budget alert in db", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetAlerts fetches the alerts of a budget of a given user from BoltDB ordered by period start and threshold,
+// which is the order of the alert keys
+func (d BoltDriver) GetAlerts(userID, budgetID string) ([]models.BudgetAlert, error) {
+	alerts := make([]models.BudgetAlert, 0)
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(budgetAlertsBucket).Cursor()
+		prefix := []byte(budgetID + ":")
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var alert models.BudgetAlert
+			if err := json.Unmarshal(v, &alert); err != nil {
+				return err
+			}
+			if alert.OwnerID == userID {
+				alerts = append(alerts, alert)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logging.Logger.Error("could not fetch budget alerts from db", zap.Error(err))
+		return []models.BudgetAlert{}, err
+	}
+	return alerts, nil
+}
+
+// deleteBudgetAlerts deletes every alert of a given budget
+func deleteBudgetAlerts(tx *bolt.Tx, budgetID string) error {
+	bucket := tx.Bucket(budgetAlertsBucket)
+	prefix := []byte(budgetID + ":")
+	var keys [][]byte
+	c := bucket.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		keys = append(keys, k)
+	}
+	for _, k := range keys {
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func putBudgetAlert(bucket *bolt.Bucket, key []byte, alert models.BudgetAlert) error {
+	bs, err := json.Marshal(alert)
+	if err != nil {
+		logging.Logger.Error("could not marshal budget alert", zap.Error(err))
+		return err
+	}
+	return bucket.Put(key, bs)
+}
+
+// budgetAlertKey builds the key of a budget alert, such as: budget_id:2006-01-02:080
+func budgetAlertKey(alert models.BudgetAlert) []byte {
+	period := alert.PeriodStart.UTC().Format(models.DateLayout)
+	return []byte(fmt.Sprintf("%s:%s:%03d", alert.BudgetID, period, alert.Threshold))
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/boltdb/bolt"
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// GetAttachments fetches the attachments of an expense of a given user from BoltDB, oldest first
+func (d BoltDriver) GetAttachments(userID, expenseID string) ([]models.Attachment, error) {
+	attachments := make([]models.Attachment, 0)
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(attachmentsBucket).Cursor()
+		prefix := []byte(expenseID + ":")
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var attachment models.Attachment
+			if err := json.Unmarshal(v, &attachment); err != nil {
+				return err
+			}
+			if attachment.OwnerID == userID {
+				attachments = append(attachments, attachment)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logging.Logger.Error("could not fetch attachments from db", zap.Error(err))
+		return []models.Attachment{}, err
+	}
+	sort.Slice(attachments, func(i, j int) bool {
+		return attachments[i].CreatedAt.Before(attachments[j].CreatedAt)
+	})
+	return attachments, nil
+}
+
+// GetAttachment fetches an attachment of an expense of a given user by a given ID from BoltDB
+func (d BoltDriver) GetAttachment(userID, expenseID, id string) (models.Attachment, error) {
+	var attachment models.Attachment
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		a, err := findAttachment(tx, userID, expenseID, id)
+		attachment = a
+		return err
+	})
+	if err != nil {
+		return models.Attachment{}, err
+	}
+	return attachment, nil
+}
+
+// CreateAttachment saves the metadata of a brand new attachment into BoltDB
+func (d BoltDriver) CreateAttachment(attachment models.Attachment) error {
+	bs, err := json.Marshal(attachment)
+	if err != nil {
+		logging.Logger.Error("could not marshal attachment", zap.Error(err))
+		return err
+	}
+	err = d.boltDB.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(attachmentsBucket).Put(attachmentKey(attachment.ExpenseID, attachment.ID), bs)
+	})
+	if err != nil {
+		logging.Logger.Error("could not create attachment in db", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// DeleteAttachment deletes the metadata of an attachment of a given user from BoltDB
+func (d BoltDriver) DeleteAttachment(userID, expenseID, id string) error {
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		if _, err := findAttachment(tx, userID, expenseID, id); err != nil {
+			return err
+		}
+		return tx.Bucket(attachmentsBucket).Delete(attachmentKey(expenseID, id))
+	})
+	if err != nil {
+		logging.Logger.Debug("could not delete attachment from db", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func findAttachment(tx *bolt.Tx, userID, expenseID, id string) (models.Attachment, error) {
+	notFoundErr := models.ResourceNotFoundError{
+		Message: fmt.Sprintf("could not find attachment with id: %s", id),
+	}
+	bs := tx.Bucket(attachmentsBucket).Get(attachmentKey(expenseID, id))
+	if len(bs) == 0 {
+		return models.Attachment{}, notFoundErr
+	}
+	var attachment models.Attachment
+	if err := json.Unmarshal(bs, &attachment); err != nil {
+		logging.Logger.Error("could not unmarshal attachment", zap.Error(err))
+		return models.Attachment{}, err
+	}
+	if attachment.OwnerID != userID {
+		return models.Attachment{}, notFoundErr
+	}
+	return attachment, nil
+}
+
+// attachmentKey builds the key of an attachment, such as: expense_id:attachment_id
+func attachmentKey(expenseID, id string) []byte {
+	return []byte(expenseID + ":" + id)
+}
@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// GetBudgets fetches all the budgets of a given user from BoltDB
+func (d BoltDriver) GetBudgets(userID string) ([]models.Budget, error) {
+	budgets := make([]models.Budget, 0)
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(budgetsBucket).ForEach(func(k, v []byte) error {
+			var budget models.Budget
+			if err := json.Unmarshal(v, &budget); err != nil {
+				return err
+			}
+			if budget.OwnerID == userID {
+				budgets = append(budgets, budget)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		logging.Logger.Error("could not fetch budgets from db", zap.Error(err))
+		return []models.Budget{}, err
+	}
+	sort.Slice(budgets, func(i, j int) bool {
+		return budgets[i].CreatedAt.Before(budgets[j].CreatedAt)
+	})
+	return budgets, nil
+}
+
+// GetBudget fetches a budget of a given user by a given ID from BoltDB
+func (d BoltDriver) GetBudget(userID, id string) (models.Budget, error) {
+	var budget models.Budget
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		b, err := d.findBudget(tx, userID, id)
+		budget = b
+		return err
+	})
+	if err != nil {
+		return models.Budget{}, err
+	}
+	return budget, nil
+}
+
+// CreateBudget creates a brand new budget for a given user and saves it into BoltDB
+func (d BoltDriver) CreateBudget(req models.CreateBudgetRequest) (models.Budget, error) {
+	budget := models.Budget{
+		ID:         uuid.New().String(),
+		OwnerID:    req.UserID,
+		Amount:     req.Amount,
+		Period:     req.Period,
+		CategoryID: req.CategoryID,
+		CreatedAt:  time.Now().UTC(),
+		ModifiedAt: time.Now().UTC(),
+	}
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		return d.putBudget(tx, budget)
+	})
+	if err != nil {
+		logging.Logger.Error("could not create budget in db", zap.Error(err))
+		return models.Budget{}, err
+	}
+	return budget, nil
+}
+
+// UpdateBudget updates an existing budget of a given user in BoltDB
+func (d BoltDriver) UpdateBudget(req models.UpdateBudgetRequest) (models.Budget, error) {
+	var budget models.Budget
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		b, err := d.findBudget(tx, req.UserID, req.ID)
+		if err != nil {
+			return err
+		}
+		budget = b
+		if !applyBudgetUpdate(&budget, req) {
+			return nil
+		}
+		return d.putBudget(tx, budget)
+	})
+	if err != nil {
+		logging.Logger.Error("could not update budget in db", zap.Error(err))
+		return models.Budget{}, err
+	}
+	return budget, nil
+}
+
+// DeleteBudget deletes a budget of a given user along with its alerts from BoltDB
+func (d BoltDriver) DeleteBudget(userID, id string) error {
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		if _, err := d.findBudget(tx, userID, id); err != nil {
+			return err
+		}
+		if err := deleteBudgetAlerts(tx, id); err != nil {
+			return err
+		}
+		return tx.Bucket(budgetsBucket).Delete([]byte(id))
+	})
+	if err != nil {
+		logging.Logger.Debug("could not delete budget from db", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (d BoltDriver) findBudget(tx *bolt.Tx, userID, id string) (models.Budget, error) {
+	notFoundErr := models.ResourceNotFoundError{
+		Message: fmt.Sprintf("could not find budget with id: %s", id),
+	}
+	bs := tx.Bucket(budgetsBucket).Get([]byte(id))
+	if len(bs) == 0 {
+		return models.Budget{}, notFoundErr
+	}
+	var budget models.Budget
+	if err := json.Unmarshal(bs, &budget); err != nil {
+		logging.Logger.Error("could not unmarshal budget", zap.Error(err))
+		return models.Budget{}, err
+	}
+	if budget.OwnerID != userID {
+		return models.Budget{}, notFoundErr
+	}
+	return budget, nil
+}
+
+func (d BoltDriver) putBudget(tx *bolt.Tx, budget models.Budget) error {
+	bs, err := json.Marshal(budget)
+	if err != nil {
+		logging.Logger.Error("could not marshal budget", zap.Error(err))
+		return err
+	}
+	return tx.Bucket(budgetsBucket).Put([]byte(budget.ID), bs)
+}
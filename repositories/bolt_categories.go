@@ -0,0 +1,201 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// GetCategories fetches all the categories of a given user from BoltDB, ordered by name ignoring case like on MariaDB
+func (d BoltDriver) GetCategories(userID string) ([]models.Category, error) {
+	categories := make([]models.Category, 0)
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(categoriesBucket).ForEach(func(k, v []byte) error {
+			var category models.Category
+			if err := json.Unmarshal(v, &category); err != nil {
+				return err
+			}
+			if category.OwnerID == userID {
+				categories = append(categories, category)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		logging.Logger.Error("could not fetch categories from db", zap.Error(err))
+		return []models.Category{}, err
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		a, b := strings.ToLower(categories[i].Name), strings.ToLower(categories[j].Name)
+		if a != b {
+			return a < b
+		}
+		return categories[i].ID < categories[j].ID
+	})
+	return categories, nil
+}
+
+// GetCategory fetches a category of a given user by a given ID from BoltDB
+func (d BoltDriver) GetCategory(userID, id string) (models.Category, error) {
+	var category models.Category
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		c, err := d.findCategory(tx, userID, id)
+		category = c
+		return err
+	})
+	if err != nil {
+		return models.Category{}, err
+	}
+	return category, nil
+}
+
+// CreateCategory creates a brand new category for a given user and saves it into BoltDB
+func (d BoltDriver) CreateCategory(userID, name string) (models.Category, error) {
+	category := models.Category{
+		ID:         uuid.New().String(),
+		OwnerID:    userID,
+		Name:       name,
+		CreatedAt:  time.Now().UTC(),
+		ModifiedAt: time.Now().UTC(),
+	}
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		if err := d.checkCategoryName(tx, category); err != nil {
+			return err
+		}
+		return d.putCategory(tx, category)
+	})
+	if err != nil {
+		logging.Logger.Error("could not create category in db", zap.Error(err))
+		return models.Category{}, err
+	}
+	return category, nil
+}
+
+// UpdateCategory renames a category of a given user in BoltDB
+func (d BoltDriver) UpdateCategory(userID, id, name string) (models.Category, error) {
+	var category models.Category
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		c, err := d.findCategory(tx, userID, id)
+		if err != nil {
+			return err
+		}
+		if c.Name == name {
+			category = c
+			return nil
+		}
+		c.Name = name
+		c.ModifiedAt = time.Now().UTC()
+		if err = d.checkCategoryName(tx, c); err != nil {
+			return err
+		}
+		category = c
+		return d.putCategory(tx, c)
+	})
+	if err != nil {
+		logging.Logger.Error("could not update category in db", zap.Error(err))
+		return models.Category{}, err
+	}
+	return category, nil
+}
+
+// DeleteCategory deletes a category of a given user from BoltDB,
+// expenses of the category either reject the deletion or get moved to uncategorized
+func (d BoltDriver) DeleteCategory(req models.DeleteCategoryRequest) error {
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		if _, err := d.findCategory(tx, req.UserID, req.ID); err != nil {
+			return err
+		}
+
+		bucket := tx.Bucket(expensesBucket)
+		updates := map[string]models.Expense{}
+		err := bucket.ForEach(func(k, v []byte) error {
+			expense, err := d.unmarshalExpense(v)
+			if err != nil {
+				return err
+			}
+			if expense.OwnerID == req.UserID && expense.CategoryID == req.ID {
+				updates[string(k)] = expense
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(updates) > 0 && !req.Reassign {
+			return models.ConflictError{
+				Message: fmt.Sprintf("category is still used by %d expenses", len(updates)),
+			}
+		}
+
+		for k, expense := range updates {
+			expense.CategoryID = ""
+			expense.ModifiedAt = expenseTime(time.Now())
+			bs, err := json.Marshal(expense)
+			if err != nil {
+				return err
+			}
+			if err = bucket.Put([]byte(k), bs); err != nil {
+				return err
+			}
+		}
+		return tx.Bucket(categoriesBucket).Delete([]byte(req.ID))
+	})
+	if err != nil {
+		logging.Logger.Debug("could not delete category from db", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (d BoltDriver) findCategory(tx *bolt.Tx, userID, id string) (models.Category, error) {
+	notFoundErr := models.ResourceNotFoundError{
+		Message: fmt.Sprintf("could not find category with id: %s", id),
+	}
+	bs := tx.Bucket(categoriesBucket).Get([]byte(id))
+	if len(bs) == 0 {
+		return models.Category{}, notFoundErr
+	}
+	var category models.Category
+	if err := json.Unmarshal(bs, &category); err != nil {
+		logging.Logger.Error("could not unmarshal category", zap.Error(err))
+		return models.Category{}, err
+	}
+	if category.OwnerID != userID {
+		return models.Category{}, notFoundErr
+	}
+	return category, nil
+}
+
+// checkCategoryName makes sure that category names are unique per user
+func (d BoltDriver) checkCategoryName(tx *bolt.Tx, category models.Category) error {
+	return tx.Bucket(categoriesBucket).ForEach(func(k, v []byte) error {
+		var c models.Category
+		if err := json.Unmarshal(v, &c); err != nil {
+			return err
+		}
+		if c.ID != category.ID && c.OwnerID == category.OwnerID && strings.EqualFold(c.Name, category.Name) {
+			return models.ConflictError{
+				Message: fmt.Sprintf("category with name: %s already exists", category.Name),
+			}
+		}
+		return nil
+	})
+}
+
+func (d BoltDriver) putCategory(tx *bolt.Tx, category models.Category) error {
+	bs, err := json.Marshal(category)
+	if err != nil {
+		logging.Logger.Error("could not marshal category", zap.Error(err))
+		return err
+	}
+	return tx.Bucket(categoriesBucket).Put([]byte(category.ID), bs)
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+func mustCreateCategory(t *testing.T, d *BoltDriver, userID, name string) models.Category {
+	t.Helper()
+	category, err := d.CreateCategory(userID, name)
+	if err != nil {
+		t.Fatalf("could not create category: %v", err)
+	}
+	return category
+}
+
+func TestBoltGetCategoriesOrdersByName(t *testing.T) {
+	d, _ := newTestBoltDriver(t)
+	for _, name := range []string{"travel", "Food", "bills", "Zoo"} {
+		mustCreateCategory(t, d, "u1", name)
+	}
+	mustCreateCategory(t, d, "u2", "Apples")
+
+	categories, err := d.GetCategories("u1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	names := make([]string, 0, len(categories))
+	for _, category := range categories {
+		names = append(names, category.Name)
+	}
+	if want := []string{"bills", "Food", "travel", "Zoo"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("expected: %v, got: %v", want, names)
+	}
+}
+
+func TestBoltDeleteCategory(t *testing.T) {
+	t.Run("rejects categories in use", func(t *testing.T) {
+		d, _ := newTestBoltDriver(t)
+		category := mustCreateCategory(t, d, "u1", "food")
+		mustCreateExpense(t, d, models.CreateExpenseRequest{UserID: "u1", CategoryID: category.ID})
+
+		err := d.DeleteCategory(models.DeleteCategoryRequest{ID: category.ID, UserID: "u1"})
+		if _, ok := err.(models.ConflictError); !ok {
+			t.Fatalf("expected a conflict error, got: %v", err)
+		}
+		if _, err = d.GetCategory("u1", category.ID); err != nil {
+			t.Fatalf("expected the category to be kept, got: %v", err)
+		}
+	})
+
+	t.Run("reassigns expenses to uncategorized", func(t *testing.T) {
+		d, _ := newTestBoltDriver(t)
+		category := mustCreateCategory(t, d, "u1", "food")
+		expense := mustCreateExpense(t, d, models.CreateExpenseRequest{UserID: "u1", CategoryID: category.ID})
+
+		err := d.DeleteCategory(models.DeleteCategoryRequest{ID: category.ID, UserID: "u1", Reassign: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err = d.GetCategory("u1", category.ID); err == nil {
+			t.Fatal("expected the category to be deleted")
+		}
+		expenses, err := d.GetExpensesByIDs("u1", []string{expense.ID})
+		if err != nil || len(expenses) != 1 {
+			t.Fatalf("could not fetch expense: %v", err)
+		}
+		if expenses[0].CategoryID != "" {
+			t.Fatalf("expected the expense to be uncategorized, got: %s", expenses[0].CategoryID)
+		}
+		if modifiedAt := expenses[0].ModifiedAt; !modifiedAt.Equal(expenseTime(modifiedAt)) {
+			t.Fatalf("expected the modification time in seconds, got: %v", modifiedAt)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+func TestBoltExpenseFilters(t *testing.T) {
+	d, e := newFilterTestDriver(t)
+	tests := []struct {
+		name string
+		req  models.GetAllExpensesRequest
+		want []string
+	}{
+		{
+			name: "inclusive date range",
+			req:  models.GetAllExpensesRequest{CreatedFrom: filterTestDay(2), CreatedTo: filterTestDay(3)},
+			want: []string{e.groceries, e.taxi},
+		},
+		{
+			name: "inclusive price range",
+			req:  models.GetAllExpensesRequest{MinPrice: 125000, MaxPrice: 250000},
+			want: []string{e.lunch, e.taxi},
+		},
+		{
+			name: "currencies",
+			req:  models.GetAllExpensesRequest{Currencies: []string{"EUR", "MDL"}},
+			want: []string{e.groceries, e.cinema},
+		},
+		{
+			name: "title query ignoring case",
+			req:  models.GetAllExpensesRequest{Query: "WORK"},
+			want: []string{e.lunch, e.taxi},
+		},
+		{
+			name: "title query without a match",
+			req:  models.GetAllExpensesRequest{Query: "rent"},
+			want: []string{},
+		},
+		{
+			name: "every filter at once",
+			req: models.GetAllExpensesRequest{
+				Tags:        []string{"work"},
+				CreatedFrom: filterTestDay(2),
+				MinPrice:    100000,
+				Currencies:  []string{"USD"},
+				Query:       "taxi",
+			},
+			want: []string{e.taxi},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assertFilteredExpenses(t, d, test.req, test.want)
+		})
+	}
+}
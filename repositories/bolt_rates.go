@@ -0,0 +1,120 @@
+package repositories
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/boltdb/bolt"
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// PutRates saves the exchange rates of a day into BoltDB, replacing the ones already stored for that day
+func (d BoltDriver) PutRates(rates models.DailyRates) error {
+	_, err := d.ImportRates([]models.DailyRates{rates}, true)
+	return err
+}
+
+// ImportRates saves the exchange rates of a list of days into BoltDB in a single transaction,
+// days already stored get replaced when replace is set and are kept otherwise
+func (d BoltDriver) ImportRates(days []models.DailyRates, replace bool) (int, error) {
+	if !replace {
+		missing, err := d.missingRates(days)
+		if err != nil {
+			logging.Logger.Error("could not fetch rates from db", zap.Error(err))
+			return 0, err
+		}
+		days = missing
+	}
+	if len(days) == 0 {
+		return 0, nil
+	}
+
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(ratesBucket)
+		for _, rates := range days {
+			bs, err := json.Marshal(rates)
+			if err != nil {
+				return err
+			}
+			if err = bucket.Put([]byte(rates.Date), bs); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logging.Logger.Error("could not save rates into db", zap.Error(err))
+		return 0, err
+	}
+	return len(days), nil
+}
+
+// missingRates returns the days of a list of days that have no rates stored yet, so that importing nothing new
+// does not even open a write transaction
+func (d BoltDriver) missingRates(days []models.DailyRates) ([]models.DailyRates, error) {
+	missing := make([]models.DailyRates, 0)
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(ratesBucket)
+		for _, rates := range days {
+			if bucket.Get([]byte(rates.Date)) == nil {
+				missing = append(missing, rates)
+			}
+		}
+		return nil
+	})
+	return missing, err
+}
+
+// GetRates fetches the exchange rates of the days within an inclusive date range from BoltDB,
+// empty bounds are ignored
+func (d BoltDriver) GetRates(from, to string) ([]models.DailyRates, error) {
+	rates := make([]models.DailyRates, 0)
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(ratesBucket).Cursor()
+		for k, v := c.Seek([]byte(from)); k != nil; k, v = c.Next() {
+			if to != "" && bytes.Compare(k, []byte(to)) > 0 {
+				break
+			}
+			var r models.DailyRates
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			rates = append(rates, r)
+		}
+		return nil
+	})
+	if err != nil {
+		logging.Logger.Error("could not fetch rates from db", zap.Error(err))
+		return []models.DailyRates{}, err
+	}
+	return rates, nil
+}
+
+// GetRatesOn fetches the latest exchange rates stored on or before a given date from BoltDB
+func (d BoltDriver) GetRatesOn(date string) (models.DailyRates, error) {
+	var rates models.DailyRates
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(ratesBucket).Cursor()
+		k, v := c.Seek([]byte(date))
+		switch {
+		case k == nil:
+			k, v = c.Last()
+		case string(k) != date:
+			k, v = c.Prev()
+		}
+		if k == nil {
+			return models.ResourceNotFoundError{
+				Message: fmt.Sprintf("could not find exchange rates on or before: %s", date),
+			}
+		}
+		return json.Unmarshal(v, &rates)
+	})
+	if err != nil {
+		return models.DailyRates{}, err
+	}
+	return rates, nil
+}
@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/boltdb/bolt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// GetRecurringExpenses fetches all the recurring expenses of a given user from BoltDB
+func (d BoltDriver) GetRecurringExpenses(userID string) ([]models.RecurringExpense, error) {
+	return d.filterRecurringExpenses(func(r models.RecurringExpense) bool {
+		return r.OwnerID == userID
+	})
+}
+
+// GetAllRecurringExpenses fetches the recurring expenses of every user from BoltDB
+func (d BoltDriver) GetAllRecurringExpenses() ([]models.RecurringExpense, error) {
+	return d.filterRecurringExpenses(func(models.RecurringExpense) bool {
+		return true
+	})
+}
+
+// GetRecurringExpense fetches a recurring expense of a given user by a given ID from BoltDB
+func (d BoltDriver) GetRecurringExpense(userID, id string) (models.RecurringExpense, error) {
+	var recurring models.RecurringExpense
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		r, err := d.findRecurringExpense(tx, userID, id)
+		recurring = r
+		return err
+	})
+	if err != nil {
+		return models.RecurringExpense{}, err
+	}
+	return recurring, nil
+}
+
+// CreateRecurringExpense creates a brand new recurring expense for a given user and saves it into BoltDB
+func (d BoltDriver) CreateRecurringExpense(req models.CreateRecurringExpenseRequest) (models.RecurringExpense, error) {
+	recurring := newRecurringExpense(req, uuid.New().String())
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		return d.putRecurringExpense(tx, recurring)
+	})
+	if err != nil {
+		logging.Logger.Error("could not create recurring expense in db", zap.Error(err))
+		return models.RecurringExpense{}, err
+	}
+	return recurring, nil
+}
+
+// UpdateRecurringExpense updates an existing recurring expense of a given user in BoltDB
+func (d BoltDriver) UpdateRecurringExpense(req models.UpdateRecurringExpenseRequest) (models.RecurringExpense, error) {
+	var recurring models.RecurringExpense
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		r, err := d.findRecurringExpense(tx, req.UserID, req.ID)
+		if err != nil {
+			return err
+		}
+		recurring = r
+		if err := applyRecurringUpdate(&recurring, req); err != nil {
+			return err
+		}
+		return d.putRecurringExpense(tx, recurring)
+	})
+	if err != nil {
+		logging.Logger.Error("could not update recurring expense in db", zap.Error(err))
+		return models.RecurringExpense{}, err
+	}
+	return recurring, nil
+}
+
+// DeleteRecurringExpense deletes a recurring expense of a given user from BoltDB,
+// the expenses it already created are kept
+func (d BoltDriver) DeleteRecurringExpense(userID, id string) error {
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		if _, err := d.findRecurringExpense(tx, userID, id); err != nil {
+			return err
+		}
+		return tx.Bucket(recurringBucket).Delete([]byte(id))
+	})
+	if err != nil {
+		logging.Logger.Debug("could not delete recurring expense from db", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// SetRecurringLastRun saves the date of the latest materialized occurrence of a recurring expense into BoltDB
+func (d BoltDriver) SetRecurringLastRun(id, date string) error {
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		bs := tx.Bucket(recurringBucket).Get([]byte(id))
+		if len(bs) == 0 {
+			return models.ResourceNotFoundError{
+				Message: fmt.Sprintf("could not find recurring expense with id: %s", id),
+			}
+		}
+		var recurring models.RecurringExpense
+		if err := json.Unmarshal(bs, &recurring); err != nil {
+			return err
+		}
+		recurring.LastRun = date
+		return d.putRecurringExpense(tx, recurring)
+	})
+	if err != nil {
+		logging.Logger.Error("could not save recurring expense last run in db", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (d BoltDriver) filterRecurringExpenses(keep func(models.RecurringExpense) bool) ([]models.RecurringExpense, error) {
+	recurring := make([]models.RecurringExpense, 0)
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(recurringBucket).ForEach(func(k, v []byte) error {
+			var r models.RecurringExpense
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			if keep(r) {
+				recurring = append(recurring, r)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		logging.Logger.Error("could not fetch recurring expenses from db", zap.Error(err))
+		return []models.RecurringExpense{}, err
+	}
+	sort.Slice(recurring, func(i, j int) bool {
+		return recurring[i].CreatedAt.Before(recurring[j].CreatedAt)
+	})
+	return recurring, nil
+}
+
+func (d BoltDriver) findRecurringExpense(tx *bolt.Tx, userID, id string) (models.RecurringExpense, error) {
+	notFoundErr := models.ResourceNotFoundError{
+		Message: fmt.Sprintf("could not find recurring expense with id: %s", id),
+	}
+	bs := tx.Bucket(recurringBucket).Get([]byte(id))
+	if len(bs) == 0 {
+		return models.RecurringExpense{}, notFoundErr
+	}
+	var recurring models.RecurringExpense
+	if err := json.Unmarshal(bs, &recurring); err != nil {
+		logging.Logger.Error("could not unmarshal recurring expense", zap.Error(err))
+		return models.RecurringExpense{}, err
+	}
+	if recurring.OwnerID != userID {
+		return models.RecurringExpense{}, notFoundErr
+	}
+	return recurring, nil
+}
+
+func (d BoltDriver) putRecurringExpense(tx *bolt.Tx, recurring models.RecurringExpense) error {
+	bs, err := json.Marshal(recurring)
+	if err != nil {
+		logging.Logger.Error("could not marshal recurring expense", zap.Error(err))
+		return err
+	}
+	return tx.Bucket(recurringBucket).Put([]byte(recurring.ID), bs)
+}
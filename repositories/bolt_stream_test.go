@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+func TestBoltStreamExpensesReadsInChunks(t *testing.T) {
+	defer func(size int) { streamChunkSize = size }(streamChunkSize)
+	streamChunkSize = 2
+	d, e := newFilterTestDriver(t)
+
+	got := make([]string, 0)
+	req := models.GetAllExpensesRequest{UserID: "u1", Sort: []models.SortField{{Field: models.TitleSortField}}}
+	err := d.StreamExpenses(req, func(expense models.Expense) error {
+		if len(got) == 0 {
+			// the taxi expense is part of the second chunk, which is read after the first one got streamed
+			if err := d.DeleteExpense("u1", e.taxi); err != nil {
+				t.Fatalf("could not delete expense while streaming: %v", err)
+			}
+		}
+		got = append(got, expense.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{e.cinema, e.groceries, e.lunch}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected: %v, got: %v", want, got)
+	}
+}
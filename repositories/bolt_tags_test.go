@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/upper/db/v4"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// filterTestExpenses represents the IDs of the expenses created by newFilterTestDriver
+type filterTestExpenses struct {
+	lunch, groceries, taxi, cinema string
+}
+
+// newFilterTestDriver creates a few expenses of a user on a temporary BoltDB file, along with an expense
+// of another user that matches every filter
+func newFilterTestDriver(t *testing.T) (*BoltDriver, filterTestExpenses) {
+	d, _ := newTestBoltDriver(t)
+	create := func(title, price, currency string, day int, tags ...string) string {
+		return mustCreateExpense(t, d, models.CreateExpenseRequest{
+			UserID:    "u1",
+			Title:     title,
+			Price:     mustParseMoney(t, price, currency),
+			Tags:      tags,
+			CreatedAt: filterTestDay(day),
+		}).ID
+	}
+	expenses := filterTestExpenses{
+		lunch:     create("Lunch at work", "12.50", "USD", 1, "food", "work"),
+		groceries: create("Groceries", "40", "EUR", 2, "food"),
+		taxi:      create("Taxi to work", "25", "USD", 3, "work", "travel"),
+		cinema:    create("Cinema", "9", "MDL", 4),
+	}
+	mustCreateExpense(t, d, models.CreateExpenseRequest{UserID: "u2", Title: "Lunch", Tags: []string{"food", "work"}})
+	return d, expenses
+}
+
+func filterTestDay(day int) time.Time {
+	return time.Date(2021, time.March, day, 10, 0, 0, 0, time.UTC)
+}
+
+// assertFilteredExpenses checks the total and every page of the expenses of u1 that match a given request
+func assertFilteredExpenses(t *testing.T, d *BoltDriver, req models.GetAllExpensesRequest, want []string) {
+	t.Helper()
+	req.UserID, req.Page, req.PageSize, req.Sort = "u1", 1, 2, models.DefaultSort
+	total, err := d.Count(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != len(want) {
+		t.Fatalf("expected total: %d, got: %d", len(want), total)
+	}
+
+	got := make([]string, 0)
+	for ; ; req.Page++ {
+		expenses, err := d.GetAllExpenses(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(expenses) == 0 {
+			break
+		}
+		got = append(got, expenseIDs(expenses)...)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected: %v, got: %v", want, got)
+	}
+}
+
+func TestBoltTagMatch(t *testing.T) {
+	d, e := newFilterTestDriver(t)
+	tests := []struct {
+		name string
+		req  models.GetAllExpensesRequest
+		want []string
+	}{
+		{
+			name: "any of the tags by default",
+			req:  models.GetAllExpensesRequest{Tags: []string{"food", "travel"}},
+			want: []string{e.lunch, e.groceries, e.taxi},
+		},
+		{
+			name: "any of the tags",
+			req:  models.GetAllExpensesRequest{Tags: []string{"food", "travel"}, TagMatch: models.AnyTagMatch},
+			want: []string{e.lunch, e.groceries, e.taxi},
+		},
+		{
+			name: "all of the tags",
+			req:  models.GetAllExpensesRequest{Tags: []string{"food", "work"}, TagMatch: models.AllTagMatch},
+			want: []string{e.lunch},
+		},
+		{
+			name: "all of the tags without a match",
+			req:  models.GetAllExpensesRequest{Tags: []string{"food", "travel"}, TagMatch: models.AllTagMatch},
+			want: []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assertFilteredExpenses(t, d, test.req, test.want)
+		})
+	}
+}
+
+func TestExpensesCondTagMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		tagMatch string
+		wantRaw  string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "any",
+			wantRaw:  "id IN (SELECT expense_id FROM expense_tags WHERE tag IN ?)",
+			wantArgs: []interface{}{[]string{"food", "work"}},
+		},
+		{
+			name:     "all",
+			tagMatch: models.AllTagMatch,
+			wantRaw: "id IN (SELECT expense_id FROM expense_tags WHERE tag IN ? " +
+				"GROUP BY expense_id HAVING COUNT(DISTINCT tag) = ?)",
+			wantArgs: []interface{}{[]string{"food", "work"}, 2},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cond := expensesCond(models.GetAllExpensesRequest{Tags: []string{"food", "work"}, TagMatch: test.tagMatch})
+			var raws []*db.RawExpr
+			for _, expr := range cond.Expressions() {
+				if raw, ok := expr.(*db.RawExpr); ok {
+					raws = append(raws, raw)
+				}
+			}
+			if len(raws) != 1 {
+				t.Fatalf("expected a single tags condition, got: %d", len(raws))
+			}
+			got := strings.Replace(raws[0].Raw(), expenseTagsTableName, "expense_tags", 1)
+			if got != test.wantRaw {
+				t.Fatalf("expected: %s, got: %s", test.wantRaw, got)
+			}
+			if !reflect.DeepEqual(raws[0].Arguments(), test.wantArgs) {
+				t.Fatalf("expected args: %v, got: %v", test.wantArgs, raws[0].Arguments())
+			}
+		})
+	}
+}
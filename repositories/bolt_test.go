@@ -0,0 +1,225 @@
+package repositories
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+func TestMain(m *testing.M) {
+	logging.Logger = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// newTestBoltDriver opens a BoltDB driver on a temporary file, which gets removed along with the test
+func newTestBoltDriver(t *testing.T) (*BoltDriver, string) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "expenses-bolt")
+	if err != nil {
+		t.Fatalf("could not create temporary dir: %v", err)
+	}
+	filename := filepath.Join(dir, "test.db")
+	d, err := NewBoltDriver(filename)
+	if err != nil {
+		t.Fatalf("could not open bolt driver: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = d.Close()
+		_ = os.RemoveAll(dir)
+	})
+	return d, filename
+}
+
+func mustCreateExpense(t *testing.T, d *BoltDriver, req models.CreateExpenseRequest) models.Expense {
+	t.Helper()
+	if req.Title == "" {
+		req.Title = "lunch"
+	}
+	if req.Price.Currency == "" {
+		req.Price = mustParseMoney(t, "12.50", "USD")
+	}
+	expense, err := d.CreateExpense(req)
+	if err != nil {
+		t.Fatalf("could not create expense: %v", err)
+	}
+	return expense
+}
+
+func TestBoltDeleteExpenseFreesItsSourceID(t *testing.T) {
+	d, _ := newTestBoltDriver(t)
+	expense := mustCreateExpense(t, d, models.CreateExpenseRequest{UserID: "u1", SourceID: "statement:1"})
+	if _, err := d.CreateExpense(models.CreateExpenseRequest{
+		UserID:   "u1",
+		Title:    "lunch",
+		Price:    expense.Price,
+		SourceID: "statement:1",
+	}); err == nil {
+		t.Fatal("expected a conflict error for an existing source")
+	}
+
+	if err := d.DeleteExpense("u1", expense.ID); err != nil {
+		t.Fatalf("could not delete expense: %v", err)
+	}
+	existing, err := d.GetExistingSourceIDs([]string{"statement:1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if existing["statement:1"] {
+		t.Fatal("expected the source of the deleted expense to be freed")
+	}
+	mustCreateExpense(t, d, models.CreateExpenseRequest{UserID: "u1", SourceID: "statement:1"})
+}
+
+func TestBoltIndexesTheSourcesOfExistingDatabases(t *testing.T) {
+	d, filename := newTestBoltDriver(t)
+	kept := mustCreateExpense(t, d, models.CreateExpenseRequest{UserID: "u1", SourceID: "statement:1"})
+	deleted := mustCreateExpense(t, d, models.CreateExpenseRequest{UserID: "u1", SourceID: "statement:2"})
+	// databases created before the source index kept the sources of deleted expenses
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(expensesSourceIDsBucket); err != nil {
+			return err
+		}
+		if err := tx.Bucket(expensesBucket).Delete([]byte("2")); err != nil {
+			return err
+		}
+		return tx.Bucket(expensesIDsBucket).Delete(expenseIDKey("u1", deleted.ID))
+	})
+	if err != nil {
+		t.Fatalf("could not simulate a legacy database: %v", err)
+	}
+	if err = d.Close(); err != nil {
+		t.Fatalf("could not close bolt driver: %v", err)
+	}
+
+	d, err = NewBoltDriver(filename)
+	if err != nil {
+		t.Fatalf("could not reopen bolt driver: %v", err)
+	}
+	defer d.Close()
+	existing, err := d.GetExistingSourceIDs([]string{"statement:1", "statement:2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !existing["statement:1"] || existing["statement:2"] {
+		t.Fatalf("expected only the source of the kept expense to exist, got: %v", existing)
+	}
+	if err = d.DeleteExpense("u1", kept.ID); err != nil {
+		t.Fatalf("could not delete expense: %v", err)
+	}
+	if existing, _ = d.GetExistingSourceIDs([]string{"statement:1"}); existing["statement:1"] {
+		t.Fatal("expected the indexed source to be freed on delete")
+	}
+}
+
+func TestBoltExpensesAreScopedToTheirOwner(t *testing.T) {
+	d, _ := newTestBoltDriver(t)
+	owned := mustCreateExpense(t, d, models.CreateExpenseRequest{UserID: "alice", Tags: []string{"food"}})
+	mustCreateExpense(t, d, models.CreateExpenseRequest{UserID: "bob", Title: "dinner"})
+
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		_, err := findExpenseID(tx, "bob", owned.ID)
+		return err
+	})
+	if _, ok := err.(models.ResourceNotFoundError); !ok {
+		t.Fatalf("expected a not found error for the key of another user, got: %v", err)
+	}
+	expenses, err := d.GetExpensesByIDs("bob", []string{owned.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(expenses) != 0 {
+		t.Fatalf("expected no expenses of another user, got: %+v", expenses)
+	}
+
+	title := "stolen"
+	_, err = d.UpdateExpense(models.UpdateExpenseRequest{ID: owned.ID, UserID: "bob", Title: title})
+	if _, ok := err.(models.ResourceNotFoundError); !ok {
+		t.Fatalf("expected a not found error on update by another user, got: %v", err)
+	}
+	err = d.DeleteExpense("bob", owned.ID)
+	if _, ok := err.(models.ResourceNotFoundError); !ok {
+		t.Fatalf("expected a not found error on delete by another user, got: %v", err)
+	}
+	ops := []models.BatchOperation{{Op: models.DeleteBatchOperation, ID: owned.ID}}
+	results, err := d.ApplyExpensesBatch("bob", ops, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := results[0].Err.(models.ResourceNotFoundError); !ok {
+		t.Fatalf("expected a not found error on a batch delete by another user, got: %v", results[0].Err)
+	}
+
+	expenses, err = d.GetExpensesByIDs("alice", []string{owned.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(expenses) != 1 || expenses[0].Title != owned.Title {
+		t.Fatalf("expected the expense of the owner to be left untouched, got: %+v", expenses)
+	}
+}
+
+func TestBoltFindExpensesFiltersByUser(t *testing.T) {
+	d, _ := newTestBoltDriver(t)
+	alice := mustCreateExpense(t, d, models.CreateExpenseRequest{UserID: "alice", Tags: []string{"food"}})
+	bob := mustCreateExpense(t, d, models.CreateExpenseRequest{UserID: "bob", Tags: []string{"food"}})
+
+	tests := []struct {
+		name string
+		req  models.GetAllExpensesRequest
+		want []string
+	}{
+		{
+			name: "own expenses",
+			req:  models.GetAllExpensesRequest{UserID: "alice"},
+			want: []string{alice.ID},
+		},
+		{
+			name: "own tagged expenses",
+			req:  models.GetAllExpensesRequest{UserID: "bob", Tags: []string{"food"}},
+			want: []string{bob.ID},
+		},
+		{
+			name: "every user",
+			req:  models.GetAllExpensesRequest{},
+			want: []string{alice.ID, bob.ID},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.req.Sort = models.DefaultSort
+			var matched []models.Expense
+			err := d.boltDB.View(func(tx *bolt.Tx) error {
+				var err error
+				matched, err = d.findExpenses(tx, test.req)
+				return err
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := expenseIDs(matched)
+			sort.Strings(got)
+			sort.Strings(test.want)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("expected: %v, got: %v", test.want, got)
+			}
+		})
+	}
+
+	req := models.GetAllExpensesRequest{UserID: "bob", Page: 1, PageSize: 10, Sort: models.DefaultSort}
+	expenses, err := d.GetAllExpenses(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(expenseIDs(expenses), []string{bob.ID}) {
+		t.Fatalf("expected only the expenses of the user, got: %v", expenseIDs(expenses))
+	}
+}
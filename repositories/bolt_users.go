@@ -0,0 +1,336 @@
+package repositories
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// boltUser represents the BoltDB user record, the password hash is hidden from the user JSON model
+type boltUser struct {
+	models.User
+	PasswordHash string `json:"password_hash"`
+}
+
+// boltAPIKey represents the BoltDB API key record, the key hash is hidden from the API key JSON model
+type boltAPIKey struct {
+	models.APIKey
+	Hash string `json:"hash"`
+}
+
+// CreateUser creates a brand new user and saves it into BoltDB
+func (d BoltDriver) CreateUser(email, passwordHash, role string) (models.User, error) {
+	user := models.User{
+		ID:           uuid.New().String(),
+		Email:        email,
+		PasswordHash: passwordHash,
+		Role:         role,
+		CreatedAt:    time.Now().UTC(),
+		ModifiedAt:   time.Now().UTC(),
+	}
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		emails := tx.Bucket(usersEmailsBucket)
+		if len(emails.Get([]byte(email))) != 0 {
+			return models.ConflictError{
+				Message: fmt.Sprintf("user with email: %s already exists", email),
+			}
+		}
+
+		bs, err := json.Marshal(boltUser{User: user, PasswordHash: passwordHash})
+		if err != nil {
+			logging.Logger.Error("could not marshal json when creating user")
+			return err
+		}
+		err = tx.Bucket(usersBucket).Put([]byte(user.ID), bs)
+		if err != nil {
+			logging.Logger.Error("could not save user in db")
+			return err
+		}
+		return emails.Put([]byte(email), []byte(user.ID))
+	})
+	if err != nil {
+		logging.Logger.Error("could not create user in db", zap.Error(err))
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// UpdateUserRole changes the role of a given user in BoltDB
+func (d BoltDriver) UpdateUserRole(id, role string) (models.User, error) {
+	var user models.User
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		u, err := d.findUser(tx, []byte(id))
+		if err != nil {
+			return err
+		}
+		u.Role = role
+		u.ModifiedAt = time.Now().UTC()
+		bs, err := json.Marshal(boltUser{User: u, PasswordHash: u.PasswordHash})
+		if err != nil {
+			logging.Logger.Error("could not marshal user for update in db", zap.Error(err))
+			return err
+		}
+		user = u
+		return tx.Bucket(usersBucket).Put([]byte(id), bs)
+	})
+	if err != nil {
+		logging.Logger.Debug("could not update user role in db", zap.Error(err))
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// GetUserByID fetches a user by a given ID from BoltDB
+func (d BoltDriver) GetUserByID(id string) (models.User, error) {
+	var user models.User
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		u, err := d.findUser(tx, []byte(id))
+		user = u
+		return err
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// GetUserByEmail fetches a user by a given email from BoltDB
+func (d BoltDriver) GetUserByEmail(email string) (models.User, error) {
+	var user models.User
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		id := tx.Bucket(usersEmailsBucket).Get([]byte(email))
+		if len(id) == 0 {
+			return models.ResourceNotFoundError{
+				Message: fmt.Sprintf("could not find user with email: %s", email),
+			}
+		}
+		u, err := d.findUser(tx, id)
+		user = u
+		return err
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// CreateSession saves a new user session into BoltDB
+func (d BoltDriver) CreateSession(id, userID string, expiresAt time.Time) error {
+	session := models.Session{
+		ID:        id,
+		UserID:    userID,
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: expiresAt,
+	}
+	bs, err := json.Marshal(session)
+	if err != nil {
+		logging.Logger.Error("could not marshal json when creating session")
+		return err
+	}
+	err = d.boltDB.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Put([]byte(id), bs)
+	})
+	if err != nil {
+		logging.Logger.Error("could not create session in db", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetSession fetches a user session by a given ID from BoltDB
+func (d BoltDriver) GetSession(id string) (models.Session, error) {
+	var session models.Session
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		bs := tx.Bucket(sessionsBucket).Get([]byte(id))
+		if len(bs) == 0 {
+			return models.ResourceNotFoundError{Message: "could not find session"}
+		}
+		return json.Unmarshal(bs, &session)
+	})
+	if err != nil {
+		logging.Logger.Debug("could not fetch session from db", zap.Error(err))
+		return models.Session{}, err
+	}
+	session.ID = id
+	return session, nil
+}
+
+// DeleteSession deletes a user session by a given ID from BoltDB
+func (d BoltDriver) DeleteSession(id string) error {
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(sessionsBucket)
+		if len(bucket.Get([]byte(id))) == 0 {
+			return models.ResourceNotFoundError{Message: "could not find session"}
+		}
+		return bucket.Delete([]byte(id))
+	})
+	if err != nil {
+		logging.Logger.Debug("could not delete session from db", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// CreateAPIKey saves a new API key into BoltDB
+func (d BoltDriver) CreateAPIKey(key models.APIKey) (models.APIKey, error) {
+	key.ID = uuid.New().String()
+	key.CreatedAt = time.Now().UTC()
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		bs, err := json.Marshal(boltAPIKey{APIKey: key, Hash: key.Hash})
+		if err != nil {
+			logging.Logger.Error("could not marshal json when creating api key")
+			return err
+		}
+		err = tx.Bucket(apiKeysBucket).Put([]byte(key.ID), bs)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(apiKeysHashBucket).Put([]byte(key.Hash), []byte(key.ID))
+	})
+	if err != nil {
+		logging.Logger.Error("could not create api key in db", zap.Error(err))
+		return models.APIKey{}, err
+	}
+	return key, nil
+}
+
+// GetAPIKeys fetches all the API keys of a given user from BoltDB, oldest first
+func (d BoltDriver) GetAPIKeys(userID string) ([]models.APIKey, error) {
+	keys := make([]models.APIKey, 0)
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(apiKeysBucket).ForEach(func(k, v []byte) error {
+			key, err := d.unmarshalAPIKey(v)
+			if err != nil {
+				return err
+			}
+			if key.UserID == userID {
+				keys = append(keys, key)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		logging.Logger.Error("could not fetch api keys from db", zap.Error(err))
+		return []models.APIKey{}, err
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys, nil
+}
+
+// GetAPIKeyByHash fetches an API key by a given key hash from BoltDB
+func (d BoltDriver) GetAPIKeyByHash(hash string) (models.APIKey, error) {
+	var apiKey models.APIKey
+	err := d.boltDB.View(func(tx *bolt.Tx) error {
+		id := tx.Bucket(apiKeysHashBucket).Get([]byte(hash))
+		bs := tx.Bucket(apiKeysBucket).Get(id)
+		if len(id) == 0 || len(bs) == 0 {
+			return models.ResourceNotFoundError{Message: "could not find api key"}
+		}
+		key, err := d.unmarshalAPIKey(bs)
+		apiKey = key
+		return err
+	})
+	if err != nil {
+		logging.Logger.Debug("could not fetch api key from db", zap.Error(err))
+		return models.APIKey{}, err
+	}
+	return apiKey, nil
+}
+
+// TouchAPIKey updates the last usage time of a given API key in BoltDB
+func (d BoltDriver) TouchAPIKey(id string, usedAt time.Time) error {
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(apiKeysBucket)
+		bs := bucket.Get([]byte(id))
+		if len(bs) == 0 {
+			return models.ResourceNotFoundError{Message: "could not find api key"}
+		}
+		key, err := d.unmarshalAPIKey(bs)
+		if err != nil {
+			return err
+		}
+		key.LastUsedAt = &usedAt
+		bs, err = json.Marshal(boltAPIKey{APIKey: key, Hash: key.Hash})
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte(id), bs)
+	})
+	if err != nil {
+		logging.Logger.Error("could not update api key last usage in db", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// DeleteAPIKey deletes an API key of a given user from BoltDB
+func (d BoltDriver) DeleteAPIKey(userID, id string) error {
+	err := d.boltDB.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(apiKeysBucket)
+		bs := bucket.Get([]byte(id))
+		notFoundErr := models.ResourceNotFoundError{
+			Message: fmt.Sprintf("could not find api key with id: %s", id),
+		}
+		if len(bs) == 0 {
+			return notFoundErr
+		}
+		key, err := d.unmarshalAPIKey(bs)
+		if err != nil {
+			return err
+		}
+		if key.UserID != userID {
+			return notFoundErr
+		}
+		err = tx.Bucket(apiKeysHashBucket).Delete([]byte(key.Hash))
+		if err != nil {
+			return err
+		}
+		return bucket.Delete([]byte(id))
+	})
+	if err != nil {
+		logging.Logger.Debug("could not delete api key from db", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (d BoltDriver) findUser(tx *bolt.Tx, id []byte) (models.User, error) {
+	bs := tx.Bucket(usersBucket).Get(id)
+	if len(bs) == 0 {
+		return models.User{}, models.ResourceNotFoundError{
+			Message: fmt.Sprintf("could not find user with id: %s", id),
+		}
+	}
+	var record boltUser
+	err := json.Unmarshal(bs, &record)
+	if err != nil {
+		logging.Logger.Error("could not unmarshal user", zap.Error(err))
+		return models.User{}, err
+	}
+	record.User.PasswordHash = record.PasswordHash
+	return record.User, nil
+}
+
+func (d BoltDriver) unmarshalAPIKey(data []byte) (models.APIKey, error) {
+	var record boltAPIKey
+	err := json.Unmarshal(data, &record)
+	if err != nil {
+		logging.Logger.Error("could not unmarshal api key", zap.Error(err))
+		return models.APIKey{}, err
+	}
+	record.APIKey.Hash = record.Hash
+	return record.APIKey, nil
+}
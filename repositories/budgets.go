@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// Budgets represents the Budgets repository interface, every method is scoped to the owner user ID
+type Budgets interface {
+	GetBudgets(userID string) ([]models.Budget, error)
+	GetBudget(userID, id string) (models.Budget, error)
+	CreateBudget(req models.CreateBudgetRequest) (models.Budget, error)
+	UpdateBudget(req models.UpdateBudgetRequest) (models.Budget, error)
+	DeleteBudget(userID, id string) error
+}
+
+// applyBudgetUpdate applies the given fields of an update budget request and reports whether anything changed
+func applyBudgetUpdate(budget *models.Budget, req models.UpdateBudgetRequest) bool {
+	var modified bool
+	if req.Amount != nil && budget.Amount != *req.Amount {
+		budget.Amount = *req.Amount
+		modified = true
+	}
+	if req.Period != "" && budget.Period != req.Period {
+		budget.Period = req.Period
+		modified = true
+	}
+	if req.CategoryID != nil && budget.CategoryID != *req.CategoryID {
+		budget.CategoryID = *req.CategoryID
+		modified = true
+	}
+	if modified {
+		budget.ModifiedAt = time.Now().UTC()
+	}
+	return modified
+}
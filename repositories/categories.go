@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// Categories represents the Categories repository interface, every method is scoped to the owner user ID
+type Categories interface {
+	GetCategories(userID string) ([]models.Category, error)
+	GetCategory(userID, id string) (models.Category, error)
+	CreateCategory(userID, name string) (models.Category, error)
+	UpdateCategory(userID, id, name string) (models.Category, error)
+	DeleteCategory(req models.DeleteCategoryRequest) error
+}
@@ -0,0 +1,13 @@
+package repositories
+
+// Driver represents a database driver that implements all the application repositories
+type Driver interface {
+	Expenses
+	Users
+	Categories
+	Rates
+	Budgets
+	Alerts
+	Recurring
+	Attachments
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+
+	"github.com/boltdb/bolt"
+	"github.com/go-sql-driver/mysql"
+	"github.com/upper/db/v4"
+)
+
+// MySQL server error codes, see: https://mariadb.com/kb/en/mariadb-error-codes
+const (
+	mysqlDuplicateEntry     = 1062
+	mysqlRowIsReferenced    = 1451
+	mysqlTooManyConnections = 1040
+	mysqlLockWaitTimeout    = 1205
+	mysqlDeadlock           = 1213
+)
+
+// IsUnavailable checks whether a storage error is transient, such as a lost connection or a lock timeout
+func IsUnavailable(err error) bool {
+	switch {
+	case errors.Is(err, bolt.ErrTimeout),
+		errors.Is(err, bolt.ErrDatabaseNotOpen),
+		errors.Is(err, db.ErrNotConnected),
+		errors.Is(err, db.ErrTooManyClients),
+		errors.Is(err, db.ErrGivingUpTryingToConnect),
+		errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, sql.ErrConnDone),
+		errors.Is(err, mysql.ErrInvalidConn),
+		errors.Is(err, context.DeadlineExceeded):
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case mysqlTooManyConnections, mysqlLockWaitTimeout, mysqlDeadlock:
+			return true
+		}
+	}
+	return false
+}
+
+// IsConflict checks whether a storage error is a constraint violation, such as a duplicate unique key
+func IsConflict(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlDuplicateEntry || mysqlErr.Number == mysqlRowIsReferenced
+	}
+	return false
+}
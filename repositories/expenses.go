@@ -1,21 +1,84 @@
 package repositories
 
 import (
+	"errors"
+	"strings"
+	"time"
+
 	"github.com/steevehook/expenses-rest-api/models"
 )
 
+// errBatchRolledBack rolls back the transaction of a batch whose operation failed, it never leaves the package
+var errBatchRolledBack = errors.New("batch was rolled back")
+
 // Closer represents application db closer
 type Closer interface {
 	Close() error
 }
 
-// Expenses represents the Expenses repository interface
+// Expenses represents the Expenses repository interface, every method is scoped to the owner user ID.
+// An empty user ID on GetAllExpenses, StreamExpenses, GetExpensesByIDs, Count and Summarize matches the expenses of every user
 type Expenses interface {
-	GetAllExpenses(page, size int) ([]models.Expense, error)
-	GetExpensesByIDs(ids []string) ([]models.Expense, error)
-	CreateExpense(title, currency string, price float64) error
-	UpdateExpense(id, title, currency string, price float64) error
-	DeleteExpense(id string) error
-	Count() (int, error)
+	GetAllExpenses(req models.GetAllExpensesRequest) ([]models.Expense, error)
+	// StreamExpenses calls a given function for every expense that matches the filters and the sort of a request,
+	// ignoring pagination. Streaming stops at the first error returned by the function
+	StreamExpenses(req models.GetAllExpensesRequest, fn func(models.Expense) error) error
+	GetExpensesByIDs(userID string, ids []string) ([]models.Expense, error)
+	CreateExpense(req models.CreateExpenseRequest) (models.Expense, error)
+	CreateExpenses(reqs []models.CreateExpenseRequest) ([]models.Expense, error)
+	// GetExistingSourceIDs finds which of the given source IDs belong to expenses that were already created
+	GetExistingSourceIDs(sourceIDs []string) (map[string]bool, error)
+	UpdateExpense(req models.UpdateExpenseRequest) (models.Expense, error)
+	DeleteExpense(userID, id string) error
+	// ApplyExpensesBatch applies the operations of a batch of a given user in a single transaction,
+	// see applyBatch for how failed operations are handled
+	ApplyExpensesBatch(userID string, ops []models.BatchOperation, bestEffort bool) ([]models.BatchResult, error)
+	Count(req models.GetAllExpensesRequest) (int, error)
+	GetTags(userID string) ([]models.TagUsage, error)
+	Summarize(req models.SummaryRequest) ([]models.SummaryBucket, error)
 	Closer
 }
+
+// expenseTime normalizes expense timestamps to UTC with second precision, which is what the DATETIME columns
+// of MariaDB hold, so that both drivers sort and page expenses the same way
+func expenseTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
+
+// sortableTitle represents the title that expenses get sorted by on both drivers, MariaDB compares the
+// very same lower cased title byte by byte instead of using the accent insensitive collation of the column
+func sortableTitle(title string) string {
+	return strings.ToLower(title)
+}
+
+// applyBatch applies the operations of a batch one by one with a given function, within the transaction of the batch.
+// Operations of missing or conflicting expenses fail on their own, best effort batches go on with the next operation,
+// while the rest stop with errBatchRolledBack so that the transaction gets rolled back. Any other error aborts the batch
+func applyBatch(
+	ops []models.BatchOperation,
+	bestEffort bool,
+	apply func(models.BatchOperation) (models.Expense, error),
+) ([]models.BatchResult, error) {
+	results := make([]models.BatchResult, len(ops))
+	for i, op := range ops {
+		results[i] = models.BatchResult{Op: op.Op, ID: op.ID}
+	}
+	for i, op := range ops {
+		expense, err := apply(op)
+		switch err.(type) {
+		case nil:
+			results[i].ID = expense.ID
+			if op.Op != models.DeleteBatchOperation {
+				results[i].Expense = &expense
+			}
+		case models.ResourceNotFoundError, models.ConflictError:
+			results[i].Err = err
+			if !bestEffort {
+				return results, errBatchRolledBack
+			}
+		default:
+			return nil, err
+		}
+	}
+	return results, nil
+}
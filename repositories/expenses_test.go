@@ -0,0 +1,260 @@
+package repositories
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/upper/db/v4"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+func TestLessExpenseSortsLikeMariaDB(t *testing.T) {
+	second := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		sort     []models.SortField
+		expenses []models.Expense
+		wantIDs  []string
+	}{
+		{
+			name: "titles ignore case and compare byte by byte",
+			sort: []models.SortField{{Field: models.TitleSortField}},
+			expenses: []models.Expense{
+				{ID: "a1", Title: "banana"},
+				{ID: "a4", Title: "Éclair"},
+				{ID: "a3", Title: "apple"},
+				{ID: "a5", Title: "zebra"},
+				{ID: "a2", Title: "Apple"},
+			},
+			wantIDs: []string{"a2", "a3", "a1", "a5", "a4"},
+		},
+		{
+			name: "descending titles still break ties by ascending id",
+			sort: []models.SortField{{Field: models.TitleSortField, Desc: true}},
+			expenses: []models.Expense{
+				{ID: "a3", Title: "apple"},
+				{ID: "a1", Title: "Banana"},
+				{ID: "a2", Title: "APPLE"},
+			},
+			wantIDs: []string{"a1", "a2", "a3"},
+		},
+		{
+			name: "times within the same second tie",
+			sort: []models.SortField{{Field: models.CreatedAtSortField}},
+			expenses: []models.Expense{
+				{ID: "b3", CreatedAt: second.Add(time.Second)},
+				{ID: "b2", CreatedAt: second.Add(100 * time.Millisecond)},
+				{ID: "b1", CreatedAt: second.Add(900 * time.Millisecond)},
+			},
+			wantIDs: []string{"b1", "b2", "b3"},
+		},
+		{
+			name: "prices compare ignoring the currency",
+			sort: []models.SortField{{Field: models.PriceSortField, Desc: true}},
+			expenses: []models.Expense{
+				{ID: "c1", Price: mustParseMoney(t, "10", "USD")},
+				{ID: "c2", Price: mustParseMoney(t, "10.01", "EUR")},
+				{ID: "c3", Price: mustParseMoney(t, "10.00", "EUR")},
+			},
+			wantIDs: []string{"c2", "c1", "c3"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sort.Slice(test.expenses, func(i, j int) bool {
+				return lessExpense(test.expenses[i], test.expenses[j], test.sort)
+			})
+			if ids := expenseIDs(test.expenses); !reflect.DeepEqual(ids, test.wantIDs) {
+				t.Fatalf("expected order: %v, got: %v", test.wantIDs, ids)
+			}
+		})
+	}
+}
+
+func TestExpensesOrder(t *testing.T) {
+	fields := []models.SortField{{Field: models.TitleSortField, Desc: true}, {Field: models.PriceSortField}}
+	tests := []struct {
+		name    string
+		reverse bool
+		want    []string
+	}{
+		{
+			name: "forward",
+			want: []string{"BINARY LOWER(`title`) DESC", "price", "id"},
+		},
+		{
+			name:    "reversed for backward cursors",
+			reverse: true,
+			want:    []string{"BINARY LOWER(`title`) ASC", "-price", "-id"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			order := expensesOrder(fields, test.reverse)
+			got := make([]string, 0, len(order))
+			for _, column := range order {
+				if raw, ok := column.(*db.RawExpr); ok {
+					got = append(got, raw.Raw())
+					continue
+				}
+				got = append(got, column.(string))
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("expected order: %v, got: %v", test.want, got)
+			}
+		})
+	}
+}
+
+func TestCursorPage(t *testing.T) {
+	sortFields := []models.SortField{{Field: models.PriceSortField}}
+	sorted := []models.Expense{
+		{ID: "e1", Price: mustParseMoney(t, "1", "USD")},
+		{ID: "e2", Price: mustParseMoney(t, "2", "USD")},
+		{ID: "e3", Price: mustParseMoney(t, "2", "USD")},
+		{ID: "e4", Price: mustParseMoney(t, "3", "USD")},
+		{ID: "e5", Price: mustParseMoney(t, "4", "USD")},
+	}
+	tests := []struct {
+		name    string
+		before  bool
+		key     models.Expense
+		wantIDs []string
+	}{
+		{
+			name:    "after a key breaks price ties by id",
+			key:     sorted[1],
+			wantIDs: []string{"e3", "e4"},
+		},
+		{
+			name:    "after a deleted key",
+			key:     models.Expense{ID: "e0", Price: mustParseMoney(t, "2.5", "USD")},
+			wantIDs: []string{"e4", "e5"},
+		},
+		{
+			name:    "before a key",
+			before:  true,
+			key:     sorted[3],
+			wantIDs: []string{"e2", "e3"},
+		},
+		{
+			name:    "before the second key",
+			before:  true,
+			key:     sorted[1],
+			wantIDs: []string{"e1"},
+		},
+		{
+			name:    "after the last key",
+			key:     sorted[4],
+			wantIDs: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := models.Cursor{Sort: sortFields, Before: test.before, Key: test.key}
+			page := cursorPage(sorted, c, 2)
+			if ids := expenseIDs(page); !reflect.DeepEqual(ids, test.wantIDs) {
+				t.Fatalf("expected page: %v, got: %v", test.wantIDs, ids)
+			}
+		})
+	}
+}
+
+func TestApplyBatch(t *testing.T) {
+	ops := []models.BatchOperation{
+		{Op: models.CreateBatchOperation},
+		{Op: models.UpdateBatchOperation, ID: "missing"},
+		{Op: models.DeleteBatchOperation, ID: "e3"},
+		{Op: models.UpdateBatchOperation, ID: "e4"},
+	}
+	apply := func(failures map[string]error) func(models.BatchOperation) (models.Expense, error) {
+		return func(op models.BatchOperation) (models.Expense, error) {
+			if err, ok := failures[op.ID]; ok {
+				return models.Expense{}, err
+			}
+			if op.ID == "" {
+				return models.Expense{ID: "created"}, nil
+			}
+			return models.Expense{ID: op.ID}, nil
+		}
+	}
+	notFound := models.ResourceNotFoundError{Message: "not found"}
+	conflict := models.ConflictError{Message: "conflict"}
+
+	t.Run("all or nothing stops at the first failure", func(t *testing.T) {
+		for _, failure := range []error{notFound, conflict} {
+			results, err := applyBatch(ops, false, apply(map[string]error{"missing": failure}))
+			if err != errBatchRolledBack {
+				t.Fatalf("expected the batch to be rolled back, got: %v", err)
+			}
+			if results[0].ID != "created" || results[0].Expense == nil {
+				t.Fatalf("expected the first operation to be applied, got: %+v", results[0])
+			}
+			if results[1].Err != failure {
+				t.Fatalf("expected the second operation to fail with: %v, got: %v", failure, results[1].Err)
+			}
+			for _, result := range results[2:] {
+				if result.Expense != nil || result.Err != nil {
+					t.Fatalf("expected the remaining operations to be untouched, got: %+v", result)
+				}
+			}
+		}
+	})
+
+	t.Run("best effort goes on after failures", func(t *testing.T) {
+		results, err := applyBatch(ops, true, apply(map[string]error{"missing": notFound, "e3": conflict}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if results[1].Err != notFound || results[2].Err != conflict {
+			t.Fatalf("expected the failed operations to keep their errors, got: %+v", results)
+		}
+		if results[3].Expense == nil || results[3].Expense.ID != "e4" {
+			t.Fatalf("expected the last operation to be applied, got: %+v", results[3])
+		}
+	})
+
+	t.Run("deletes have no expense", func(t *testing.T) {
+		results, err := applyBatch(ops[2:3], false, apply(nil))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if results[0].ID != "e3" || results[0].Expense != nil {
+			t.Fatalf("expected the deleted expense ID only, got: %+v", results[0])
+		}
+	})
+
+	t.Run("other errors abort", func(t *testing.T) {
+		failure := errors.New("disk failure")
+		for _, bestEffort := range []bool{false, true} {
+			results, err := applyBatch(ops, bestEffort, apply(map[string]error{"missing": failure}))
+			if err != failure || results != nil {
+				t.Fatalf("expected the batch to abort with: %v, got: %v, %+v", failure, err, results)
+			}
+		}
+	})
+}
+
+func mustParseMoney(t *testing.T, amount, currency string) models.Money {
+	t.Helper()
+	money, err := models.ParseMoney(amount, currency)
+	if err != nil {
+		t.Fatalf("could not parse money: %v", err)
+	}
+	return money
+}
+
+func expenseIDs(expenses []models.Expense) []string {
+	ids := make([]string, 0, len(expenses))
+	for _, expense := range expenses {
+		ids = append(ids, expense.ID)
+	}
+	return ids
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// FileSystemBlobs represents the local file system blob storage, every blob is a file inside of a directory
+type FileSystemBlobs struct {
+	dir string
+}
+
+// NewFileSystemBlobs creates a new instance of local file system blob storage, creating the directory when missing
+func NewFileSystemBlobs(dir string) (*FileSystemBlobs, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		logging.Logger.Error("could not create blobs directory", zap.Error(err))
+		return nil, err
+	}
+	return &FileSystemBlobs{dir: dir}, nil
+}
+
+// PutBlob saves the content of a blob into a file, the file only shows up once the whole content was written
+func (b FileSystemBlobs) PutBlob(id string, content io.Reader) error {
+	path, err := b.path(id)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(b.dir, ".upload-*")
+	if err != nil {
+		logging.Logger.Error("could not create blob file", zap.Error(err))
+		return err
+	}
+	_, err = io.Copy(tmp, content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// GetBlob opens the file of a blob for reading
+func (b FileSystemBlobs) GetBlob(id string) (io.ReadCloser, error) {
+	path, err := b.path(id)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, models.ResourceNotFoundError{
+			Message: fmt.Sprintf("could not find blob with id: %s", id),
+		}
+	}
+	if err != nil {
+		logging.Logger.Error("could not open blob file", zap.Error(err))
+		return nil, err
+	}
+	return f, nil
+}
+
+// DeleteBlob deletes the file of a blob, deleting a missing blob is not an error
+func (b FileSystemBlobs) DeleteBlob(id string) error {
+	path, err := b.path(id)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+		logging.Logger.Error("could not delete blob file", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (b FileSystemBlobs) path(id string) (string, error) {
+	if id == "" || filepath.Base(id) != id || id[0] == '.' {
+		return "", fmt.Errorf("invalid blob id: %s", id)
+	}
+	return filepath.Join(b.dir, id), nil
+}
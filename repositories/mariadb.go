@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -15,9 +16,121 @@ import (
 )
 
 const (
-	expensesTableName = "expenses"
+	expensesTableName    = "expenses"
+	usersTableName       = "users"
+	sessionsTableName    = "sessions"
+	apiKeysTableName     = "api_keys"
+	categoriesTableName  = "categories"
+	expenseTagsTableName = "expense_tags"
+
+	// sortableTitleColumn represents the lower cased title column compared byte by byte, see sortableTitle
+	sortableTitleColumn = "BINARY LOWER(`title`)"
+
+	// streamBatchSize represents the amount of streamed expenses whose tags get loaded at once
+	streamBatchSize = 500
 )
 
+// likeEscaper escapes the LIKE wildcards of user input, so that it gets matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// expenseRow represents a row of the expenses table, prices are stored as DECIMAL amounts of major units
+type expenseRow struct {
+	ID         string  `db:"id"`
+	OwnerID    string  `db:"owner_id"`
+	Price      string  `db:"price"`
+	Currency   string  `db:"currency"`
+	Title      string  `db:"title"`
+	CategoryID string  `db:"category_id"`
+	SourceID   *string `db:"source_id,omitempty"`
+	// TransactionID and PostedAt represent the bank transaction of expenses imported from bank statements
+	TransactionID *string    `db:"transaction_id,omitempty"`
+	PostedAt      *time.Time `db:"posted_at,omitempty"`
+	CreatedAt     time.Time  `db:"created_at"`
+	ModifiedAt    time.Time  `db:"modified_at"`
+}
+
+func newExpenseRow(expense models.Expense) expenseRow {
+	row := expenseRow{
+		ID:         expense.ID,
+		OwnerID:    expense.OwnerID,
+		Price:      expense.Price.String(),
+		Currency:   expense.Price.Currency,
+		Title:      expense.Title,
+		CategoryID: expense.CategoryID,
+		CreatedAt:  expense.CreatedAt,
+		ModifiedAt: expense.ModifiedAt,
+	}
+	if expense.Transaction != nil {
+		row.TransactionID = &expense.Transaction.ID
+		row.PostedAt = &expense.Transaction.PostedAt
+	}
+	return row
+}
+
+func (r expenseRow) expense() (models.Expense, error) {
+	price, err := models.ParseStoredMoney(r.Price, r.Currency)
+	if err != nil {
+		return models.Expense{}, err
+	}
+	expense := models.Expense{
+		ID:         r.ID,
+		OwnerID:    r.OwnerID,
+		Price:      price,
+		Title:      r.Title,
+		CategoryID: r.CategoryID,
+		CreatedAt:  r.CreatedAt,
+		ModifiedAt: r.ModifiedAt,
+	}
+	if r.TransactionID != nil && r.PostedAt != nil {
+		expense.Transaction = &models.BankTransaction{ID: *r.TransactionID, PostedAt: *r.PostedAt}
+	}
+	return expense, nil
+}
+
+// expenseTag represents a row of the expenses tags join table
+type expenseTag struct {
+	ExpenseID string `db:"expense_id"`
+	Tag       string `db:"tag"`
+}
+
+// summaryRow represents one GROUP BY row of the expenses summary, amounts are DECIMAL amounts of major units
+type summaryRow struct {
+	Bucket   string `db:"bucket"`
+	Currency string `db:"currency"`
+	Day      string `db:"day"`
+	Count    int    `db:"count"`
+	Total    string `db:"total"`
+	Min      string `db:"min_price"`
+	Max      string `db:"max_price"`
+}
+
+func (r summaryRow) bucket() (models.SummaryBucket, error) {
+	bucket := models.SummaryBucket{Key: r.Bucket, Currency: r.Currency, Count: r.Count, Date: r.Day}
+	var err error
+	if bucket.Total, err = models.ParseAggregateMoney(r.Total, r.Currency); err != nil {
+		if _, ok := err.(models.DataValidationError); ok {
+			err = models.DataValidationError{
+				Message: fmt.Sprintf("total of %s expenses is too large, narrow down the summary", r.Currency),
+			}
+		}
+		return models.SummaryBucket{}, err
+	}
+	if bucket.Min, err = models.ParseStoredMoney(r.Min, r.Currency); err != nil {
+		return models.SummaryBucket{}, err
+	}
+	bucket.Max, err = models.ParseStoredMoney(r.Max, r.Currency)
+	return bucket, err
+}
+
+// summaryKeyExprs represents the SQL expressions of the summary bucket keys, see models.SummaryKey
+var summaryKeyExprs = map[string]string{
+	models.MonthSummaryGroup:    "DATE_FORMAT(created_at, '%Y-%m')",
+	models.WeekSummaryGroup:     "DATE_FORMAT(created_at, '%x-W%v')",
+	models.DaySummaryGroup:      "DATE_FORMAT(created_at, '%Y-%m-%d')",
+	models.CategorySummaryGroup: "category_id",
+	models.CurrencySummaryGroup: "currency",
+}
+
 // MariaDBSettings represents the settings for MariaDB
 type MariaDBSettings struct {
 	URL                string
@@ -52,26 +165,91 @@ func NewMariaDBDriver(settings MariaDBSettings) (*MariaDBDriver, error) {
 	return driver, nil
 }
 
-// GetAllExpenses fetches all expenses with pagination possibilities from MariaDB
-func (d MariaDBDriver) GetAllExpenses(page, pageSize int) ([]models.Expense, error) {
-	var expenses []models.Expense
-	err := d.mariaDB.
+// GetAllExpenses fetches all expenses matched by a given request with pagination possibilities from MariaDB
+func (d MariaDBDriver) GetAllExpenses(req models.GetAllExpensesRequest) ([]models.Expense, error) {
+	var rows []expenseRow
+	res := d.mariaDB.
 		Collection(expensesTableName).
-		Find().
-		Page(uint(page)).
-		Paginate(uint(pageSize)).
-		OrderBy("modified_at").
-		All(&expenses)
+		Find(expensesCond(req))
+	if req.Cursor != nil {
+		res = res.
+			And(cursorCond(*req.Cursor)).
+			OrderBy(expensesOrder(req.Sort, req.Cursor.Before)...).
+			Limit(req.PageSize)
+	} else {
+		res = res.
+			Page(uint(req.Page)).
+			Paginate(uint(req.PageSize)).
+			OrderBy(expensesOrder(req.Sort, false)...)
+	}
+	err := res.All(&rows)
 	if err != nil {
 		logging.Logger.Error("could not execute find all on mariadb expenses records", zap.Error(err))
 		return []models.Expense{}, err
 	}
+	expenses, err := toExpenses(rows)
+	if err != nil {
+		return []models.Expense{}, err
+	}
+	if req.Cursor != nil && req.Cursor.Before {
+		for i, j := 0, len(expenses)-1; i < j; i, j = i+1, j-1 {
+			expenses[i], expenses[j] = expenses[j], expenses[i]
+		}
+	}
+	if err = loadExpenseTags(d.mariaDB, expenses); err != nil {
+		return []models.Expense{}, err
+	}
 	return expenses, nil
 }
 
-// GetExpensesByIDs fetches a list of expenses by a given list of IDs from MariaDB
-func (d MariaDBDriver) GetExpensesByIDs(ids []string) ([]models.Expense, error) {
-	var expenses []models.Expense
+// StreamExpenses calls a given function for every expense that matches a request in MariaDB,
+// rows are read one by one and their tags get loaded in batches
+func (d MariaDBDriver) StreamExpenses(req models.GetAllExpensesRequest, fn func(models.Expense) error) error {
+	res := d.mariaDB.
+		Collection(expensesTableName).
+		Find(expensesCond(req)).
+		OrderBy(expensesOrder(req.Sort, false)...)
+	defer res.Close()
+
+	batch := make([]expenseRow, 0, streamBatchSize)
+	emit := func() error {
+		expenses, err := toExpenses(batch)
+		if err != nil {
+			return err
+		}
+		batch = batch[:0]
+		if err = loadExpenseTags(d.mariaDB, expenses); err != nil {
+			return err
+		}
+		for _, expense := range expenses {
+			if err = fn(expense); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var row expenseRow
+	for res.Next(&row) {
+		batch = append(batch, row)
+		row = expenseRow{}
+		if len(batch) < streamBatchSize {
+			continue
+		}
+		if err := emit(); err != nil {
+			return err
+		}
+	}
+	if err := res.Err(); err != nil {
+		logging.Logger.Error("could not stream mariadb expenses records", zap.Error(err))
+		return err
+	}
+	return emit()
+}
+
+// GetExpensesByIDs fetches a list of expenses of a given user by a given list of IDs from MariaDB
+func (d MariaDBDriver) GetExpensesByIDs(userID string, ids []string) ([]models.Expense, error) {
+	var rows []expenseRow
 	idsPlaceholder := strings.Repeat("?,", len(ids)-1)
 	idsPlaceholder += "?"
 	var args []interface{}
@@ -83,85 +261,207 @@ func (d MariaDBDriver) GetExpensesByIDs(ids []string) ([]models.Expense, error)
 		SQL().
 		SelectFrom(expensesTableName).
 		Where(args...).
-		All(&expenses)
+		And(ownerCond(userID)).
+		All(&rows)
 	if err != nil {
 		logging.Logger.Error("could not select expense records from mariadb", zap.Error(err))
 		return []models.Expense{}, err
 	}
+	expenses, err := toExpenses(rows)
+	if err != nil {
+		return []models.Expense{}, err
+	}
+	if err = loadExpenseTags(d.mariaDB, expenses); err != nil {
+		return []models.Expense{}, err
+	}
 	return expenses, nil
 }
 
-// CreateExpense creates a brand new expense and saves it into MariaDB
-func (d MariaDBDriver) CreateExpense(title, currency string, price float64) error {
-	uid := uuid.New()
-	expense := models.Expense{
-		ID:         uid.String(),
-		Title:      title,
-		Currency:   currency,
-		Price:      price,
-		CreatedAt:  time.Now().UTC(),
-		ModifiedAt: time.Now().UTC(),
+// GetExistingSourceIDs finds which of the given source IDs belong to expenses that were already created
+func (d MariaDBDriver) GetExistingSourceIDs(sourceIDs []string) (map[string]bool, error) {
+	existing := map[string]bool{}
+	for start := 0; start < len(sourceIDs); start += streamBatchSize {
+		end := start + streamBatchSize
+		if end > len(sourceIDs) {
+			end = len(sourceIDs)
+		}
+		var rows []struct {
+			SourceID string `db:"source_id"`
+		}
+		err := d.mariaDB.
+			SQL().
+			Select("source_id").
+			From(expensesTableName).
+			Where(db.Cond{"source_id IN": sourceIDs[start:end]}).
+			All(&rows)
+		if err != nil {
+			logging.Logger.Error("could not select expense sources from mariadb", zap.Error(err))
+			return map[string]bool{}, err
+		}
+		for _, row := range rows {
+			existing[row.SourceID] = true
+		}
 	}
-	_, err := d.mariaDB.Collection(expensesTableName).Insert(expense)
+	return existing, nil
+}
+
+// CreateExpense creates a brand new expense for a given user and saves it into MariaDB
+func (d MariaDBDriver) CreateExpense(req models.CreateExpenseRequest) (models.Expense, error) {
+	var expense models.Expense
+	err := d.mariaDB.Tx(func(sess db.Session) error {
+		var err error
+		expense, err = createExpense(sess, req)
+		return err
+	})
 	if err != nil {
 		logging.Logger.Error("could not create expense record in mariadb", zap.Error(err))
-		return err
+		return models.Expense{}, err
 	}
-	return nil
+	return expense, nil
 }
 
-// UpdateExpense updates an existing expense and updates the record in MariaDB
-func (d MariaDBDriver) UpdateExpense(id, title, currency string, price float64) error {
-	var modified bool
-	expense, err := d.findExpense(id)
+// CreateExpenses creates a list of brand new expenses and saves them into MariaDB in a single transaction,
+// either all of them get created or none of them
+func (d MariaDBDriver) CreateExpenses(reqs []models.CreateExpenseRequest) ([]models.Expense, error) {
+	expenses := make([]models.Expense, 0, len(reqs))
+	err := d.mariaDB.Tx(func(sess db.Session) error {
+		for _, req := range reqs {
+			expense, err := createExpense(sess, req)
+			if err != nil {
+				return err
+			}
+			expenses = append(expenses, expense)
+		}
+		return nil
+	})
 	if err != nil {
-		return err
-	}
-	if title != "" && expense.Title != title {
-		expense.Title = title
-		modified = true
+		logging.Logger.Error("could not create expense records in mariadb", zap.Error(err))
+		return []models.Expense{}, err
 	}
-	if currency != "" && expense.Currency != currency {
-		expense.Currency = currency
-		modified = true
+	return expenses, nil
+}
+
+// UpdateExpense updates an existing expense of a given user and updates the record in MariaDB
+func (d MariaDBDriver) UpdateExpense(req models.UpdateExpenseRequest) (models.Expense, error) {
+	var expense models.Expense
+	err := d.mariaDB.Tx(func(sess db.Session) error {
+		var err error
+		expense, err = updateExpense(sess, req)
+		return err
+	})
+	if _, ok := err.(models.ResourceNotFoundError); err != nil && !ok {
+		logging.Logger.Error("could not update expense in mariadb", zap.Error(err))
 	}
-	if price > 0 && expense.Price != price {
-		expense.Price = price
-		modified = true
+	if err != nil {
+		return models.Expense{}, err
 	}
-	if modified {
-		expense.ModifiedAt = time.Now().UTC()
+	return expense, nil
+}
+
+// DeleteExpense deletes a given expense of a given user from MariaDB
+func (d MariaDBDriver) DeleteExpense(userID, id string) error {
+	_, err := deleteExpense(d.mariaDB, userID, id)
+	return err
+}
+
+// ApplyExpensesBatch applies the operations of a batch of a given user in a single MariaDB transaction
+func (d MariaDBDriver) ApplyExpensesBatch(userID string, ops []models.BatchOperation, bestEffort bool) ([]models.BatchResult, error) {
+	var results []models.BatchResult
+	err := d.mariaDB.Tx(func(sess db.Session) error {
+		var err error
+		results, err = applyBatch(ops, bestEffort, func(op models.BatchOperation) (models.Expense, error) {
+			switch op.Op {
+			case models.CreateBatchOperation:
+				return createExpense(sess, op.CreateRequest(userID))
+			case models.UpdateBatchOperation:
+				return updateExpense(sess, op.UpdateRequest(userID))
+			default:
+				return deleteExpense(sess, userID, op.ID)
+			}
+		})
+		return err
+	})
+	if err == errBatchRolledBack {
+		return results, nil
 	}
-	err = d.mariaDB.Collection(expensesTableName).UpdateReturning(&expense)
 	if err != nil {
-		logging.Logger.Error("could not update expense in mariadb", zap.Error(err))
-		return err
+		logging.Logger.Error("could not apply expenses batch in mariadb", zap.Error(err))
+		return []models.BatchResult{}, err
 	}
-	return nil
+	return results, nil
+}
+
+// Count fetches the total count of expenses matched by a given request from MariaDB
+func (d MariaDBDriver) Count(req models.GetAllExpensesRequest) (int, error) {
+	count, err := d.mariaDB.Collection(expensesTableName).Find(expensesCond(req)).Count()
+	return int(count), err
 }
 
-// DeleteExpense deletes a given expense from MariaDB given a list of IDs
-func (d MariaDBDriver) DeleteExpense(id string) error {
-	_, err := d.findExpense(id)
+// GetTags fetches the tags of a given user along with their usage count from MariaDB
+func (d MariaDBDriver) GetTags(userID string) ([]models.TagUsage, error) {
+	tags := make([]models.TagUsage, 0)
+	err := d.mariaDB.
+		SQL().
+		Select("t.tag", db.Raw("COUNT(*) AS count")).
+		From(expenseTagsTableName + " AS t").
+		Join(expensesTableName + " AS e").On("e.id = t.expense_id").
+		Where(db.Cond{"e.owner_id": userID}).
+		GroupBy("t.tag").
+		OrderBy("t.tag").
+		All(&tags)
 	if err != nil {
-		return err
+		logging.Logger.Error("could not select tags from mariadb", zap.Error(err))
+		return []models.TagUsage{}, err
+	}
+	return tags, nil
+}
+
+// Summarize aggregates the expenses matched by a given request into buckets of one currency using GROUP BY in MariaDB
+func (d MariaDBDriver) Summarize(req models.SummaryRequest) ([]models.SummaryBucket, error) {
+	columns := []interface{}{
+		db.Raw(summaryKeyExprs[req.GroupBy] + " AS bucket"),
+		"currency",
+		db.Raw("COUNT(*) AS count"),
+		db.Raw("SUM(price) AS total"),
+		db.Raw("MIN(price) AS min_price"),
+		db.Raw("MAX(price) AS max_price"),
+	}
+	groups := []interface{}{"bucket", "currency"}
+	if req.ConvertTo != "" {
+		columns = append(columns, db.Raw("DATE_FORMAT(created_at, '%Y-%m-%d') AS day"))
+		groups = append(groups, "day")
 	}
-	_, err = d.mariaDB.
+
+	conds := []db.LogicalExpr{ownerCond(req.UserID)}
+	if !req.From.IsZero() {
+		conds = append(conds, db.Cond{"created_at >=": req.From})
+	}
+	if !req.To.IsZero() {
+		conds = append(conds, db.Cond{"created_at <=": req.To})
+	}
+
+	var rows []summaryRow
+	err := d.mariaDB.
 		SQL().
-		DeleteFrom(expensesTableName).
-		Where(db.Cond{"id": id}).
-		Exec()
+		Select(columns...).
+		From(expensesTableName).
+		Where(db.And(conds...)).
+		GroupBy(groups...).
+		All(&rows)
 	if err != nil {
-		logging.Logger.Error("could not delete expense from mariadb", zap.Error(err))
-		return err
+		logging.Logger.Error("could not summarize expenses in mariadb", zap.Error(err))
+		return []models.SummaryBucket{}, err
 	}
-	return nil
-}
 
-// Count fetches the total count from expenses table from MariaDB
-func (d MariaDBDriver) Count() (int, error) {
-	count, err := d.mariaDB.Collection(expensesTableName).Count()
-	return int(count), err
+	buckets := make([]models.SummaryBucket, 0, len(rows))
+	for _, row := range rows {
+		bucket, err := row.bucket()
+		if err != nil {
+			return []models.SummaryBucket{}, err
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets, nil
 }
 
 // Close closes the MariaDB database
@@ -176,17 +476,295 @@ func (d MariaDBDriver) Close() error {
 	return nil
 }
 
-func (d MariaDBDriver) findExpense(id string) (models.Expense, error) {
-	var expense models.Expense
-	err := d.mariaDB.Collection(expensesTableName).
-		Find(db.Cond{"id": id}).
-		One(&expense)
-	if err != nil {
+func findExpense(sess db.Session, userID, id string) (models.Expense, error) {
+	var row expenseRow
+	err := sess.Collection(expensesTableName).
+		Find(db.Cond{"id": id, "owner_id": userID}).
+		One(&row)
+	if errors.Is(err, db.ErrNoMoreRows) {
 		logging.Logger.Debug("could not find expense in mariadb", zap.String("id", id))
 		e := models.ResourceNotFoundError{
 			Message: fmt.Sprintf("could not find expense with id: %s", id),
 		}
 		return models.Expense{}, e
 	}
+	if err != nil {
+		logging.Logger.Error("could not select expense record from mariadb", zap.Error(err))
+		return models.Expense{}, err
+	}
+	return row.expense()
+}
+
+func toExpenses(rows []expenseRow) ([]models.Expense, error) {
+	expenses := make([]models.Expense, 0, len(rows))
+	for _, row := range rows {
+		expense, err := row.expense()
+		if err != nil {
+			logging.Logger.Error("could not convert mariadb expense record", zap.Error(err))
+			return nil, err
+		}
+		expenses = append(expenses, expense)
+	}
+	return expenses, nil
+}
+
+// ownerCond builds the condition that scopes expenses to a given user, an empty user ID matches every user
+func ownerCond(userID string) db.Cond {
+	if userID == "" {
+		return db.Cond{}
+	}
+	return db.Cond{"owner_id": userID}
+}
+
+// expensesCond builds the condition that matches the expenses of a fetch all expenses request
+func expensesCond(req models.GetAllExpensesRequest) db.LogicalExpr {
+	var conds []db.LogicalExpr
+	if req.UserID != "" {
+		conds = append(conds, ownerCond(req.UserID))
+	}
+	if len(req.Tags) > 0 {
+		query := "id IN (SELECT expense_id FROM " + expenseTagsTableName + " WHERE tag IN ?"
+		args := []interface{}{req.Tags}
+		if req.TagMatch == models.AllTagMatch {
+			query += " GROUP BY expense_id HAVING COUNT(DISTINCT tag) = ?"
+			args = append(args, len(req.Tags))
+		}
+		conds = append(conds, db.Raw(query+")", args...))
+	}
+	if !req.CreatedFrom.IsZero() {
+		conds = append(conds, db.Cond{"created_at >=": req.CreatedFrom})
+	}
+	if !req.CreatedTo.IsZero() {
+		conds = append(conds, db.Cond{"created_at <=": req.CreatedTo})
+	}
+	if req.MinPrice > 0 {
+		conds = append(conds, db.Cond{"price >=": models.FormatDecimal(req.MinPrice, models.PriceScale)})
+	}
+	if req.MaxPrice > 0 {
+		conds = append(conds, db.Cond{"price <=": models.FormatDecimal(req.MaxPrice, models.PriceScale)})
+	}
+	if len(req.Currencies) > 0 {
+		conds = append(conds, db.Cond{"currency IN": req.Currencies})
+	}
+	if req.Query != "" {
+		conds = append(conds, db.Cond{"title LIKE": "%" + likeEscaper.Replace(req.Query) + "%"})
+	}
+	return db.And(conds...)
+}
+
+// expensesOrder builds the ORDER BY columns of a given list of sort fields, breaking ties by ID.
+// The order is reversed for cursors that point backwards, so that the closest expenses come first
+func expensesOrder(fields []models.SortField, reverse bool) []interface{} {
+	order := make([]interface{}, 0, len(fields)+1)
+	for _, field := range withIDTiebreak(fields) {
+		field.Desc = field.Desc != reverse
+		if field.Field != models.TitleSortField {
+			order = append(order, field.String())
+			continue
+		}
+		direction := "ASC"
+		if field.Desc {
+			direction = "DESC"
+		}
+		order = append(order, db.Raw(sortableTitleColumn+" "+direction))
+	}
+	return order
+}
+
+// cursorCond builds the keyset condition that matches the expenses placed after, or before, a given cursor
+func cursorCond(c models.Cursor) db.LogicalExpr {
+	fields := withIDTiebreak(c.Sort)
+	conds := make([]db.LogicalExpr, 0, len(fields))
+	for i, field := range fields {
+		cond := make([]db.LogicalExpr, 0, i+1)
+		for _, prev := range fields[:i] {
+			cond = append(cond, cursorFieldCond(c.Key, prev.Field, "="))
+		}
+		op := ">"
+		if field.Desc != c.Before {
+			op = "<"
+		}
+		conds = append(conds, db.And(append(cond, cursorFieldCond(c.Key, field.Field, op))...))
+	}
+	return db.Or(conds...)
+}
+
+// cursorFieldCond compares a given sort field of expenses with the one of a cursor key, titles get compared
+// the same way they are sorted
+func cursorFieldCond(key models.Expense, field, op string) db.LogicalExpr {
+	if field == models.TitleSortField {
+		return db.Raw(sortableTitleColumn+" "+op+" ?", sortableTitle(key.Title))
+	}
+	if op == "=" {
+		return db.Cond{field: cursorValue(key, field)}
+	}
+	return db.Cond{field + " " + op: cursorValue(key, field)}
+}
+
+func withIDTiebreak(fields []models.SortField) []models.SortField {
+	res := make([]models.SortField, 0, len(fields)+1)
+	return append(append(res, fields...), models.SortField{Field: "id"})
+}
+
+func cursorValue(key models.Expense, field string) interface{} {
+	switch field {
+	case models.PriceSortField:
+		return key.Price.String()
+	case models.TitleSortField:
+		return key.Title
+	case models.CreatedAtSortField:
+		return expenseTime(key.CreatedAt)
+	case models.ModifiedAtSortField:
+		return expenseTime(key.ModifiedAt)
+	}
+	return key.ID
+}
+
+// loadExpenseTags fetches the tags of a given list of expenses and sets them on each expense
+func loadExpenseTags(sess db.Session, expenses []models.Expense) error {
+	if len(expenses) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(expenses))
+	for _, expense := range expenses {
+		ids = append(ids, expense.ID)
+	}
+	var rows []expenseTag
+	err := sess.
+		SQL().
+		SelectFrom(expenseTagsTableName).
+		Where(db.Cond{"expense_id IN": ids}).
+		OrderBy("tag").
+		All(&rows)
+	if err != nil {
+		logging.Logger.Error("could not select expense tags from mariadb", zap.Error(err))
+		return err
+	}
+
+	tags := map[string][]string{}
+	for _, row := range rows {
+		tags[row.ExpenseID] = append(tags[row.ExpenseID], row.Tag)
+	}
+	for i := range expenses {
+		expenses[i].Tags = tags[expenses[i].ID]
+	}
+	return nil
+}
+
+// createExpense inserts a brand new expense of a given user within a given session
+func createExpense(sess db.Session, req models.CreateExpenseRequest) (models.Expense, error) {
+	createdAt := expenseTime(time.Now())
+	if !req.CreatedAt.IsZero() {
+		createdAt = expenseTime(req.CreatedAt)
+	}
+	expense := models.Expense{
+		ID:          uuid.New().String(),
+		OwnerID:     req.UserID,
+		Title:       req.Title,
+		Price:       req.Price,
+		CategoryID:  req.CategoryID,
+		Transaction: req.Transaction,
+		CreatedAt:   createdAt,
+		ModifiedAt:  expenseTime(time.Now()),
+	}
+	row := newExpenseRow(expense)
+	if req.SourceID != "" {
+		row.SourceID = &req.SourceID
+	}
+	_, err := sess.Collection(expensesTableName).Insert(row)
+	if req.SourceID != "" && IsConflict(err) {
+		return models.Expense{}, models.ConflictError{
+			Message: fmt.Sprintf("expense of source: %s already exists", req.SourceID),
+		}
+	}
+	if err != nil {
+		return models.Expense{}, err
+	}
+	if err = insertExpenseTags(sess, expense.ID, req.Tags); err != nil {
+		return models.Expense{}, err
+	}
+	expense.Tags = req.Tags
 	return expense, nil
 }
+
+// updateExpense updates an existing expense of a given user within a given session
+func updateExpense(sess db.Session, req models.UpdateExpenseRequest) (models.Expense, error) {
+	var modified bool
+	expense, err := findExpense(sess, req.UserID, req.ID)
+	if err != nil {
+		return models.Expense{}, err
+	}
+	if req.Title != "" && expense.Title != req.Title {
+		expense.Title = req.Title
+		modified = true
+	}
+	if req.Price != nil && expense.Price != *req.Price {
+		expense.Price = *req.Price
+		modified = true
+	}
+	if req.CategoryID != nil && expense.CategoryID != *req.CategoryID {
+		expense.CategoryID = *req.CategoryID
+		modified = true
+	}
+	current := []models.Expense{expense}
+	if err = loadExpenseTags(sess, current); err != nil {
+		return models.Expense{}, err
+	}
+	tags := current[0].Tags
+	replaceTags := req.Tags != nil && !equalTags(req.Tags, tags)
+	if replaceTags {
+		tags = req.Tags
+	}
+	if modified || replaceTags {
+		expense.ModifiedAt = expenseTime(time.Now())
+	}
+
+	err = sess.Collection(expensesTableName).
+		Find(db.Cond{"id": expense.ID}).
+		Update(newExpenseRow(expense))
+	if err != nil {
+		return models.Expense{}, err
+	}
+	if replaceTags {
+		_, err = sess.SQL().
+			DeleteFrom(expenseTagsTableName).
+			Where(db.Cond{"expense_id": expense.ID}).
+			Exec()
+		if err != nil {
+			return models.Expense{}, err
+		}
+		if err = insertExpenseTags(sess, expense.ID, req.Tags); err != nil {
+			return models.Expense{}, err
+		}
+	}
+	expense.Tags = tags
+	return expense, nil
+}
+
+// deleteExpense deletes an expense of a given user within a given session and returns the deleted expense
+func deleteExpense(sess db.Session, userID, id string) (models.Expense, error) {
+	expense, err := findExpense(sess, userID, id)
+	if err != nil {
+		return models.Expense{}, err
+	}
+	_, err = sess.
+		SQL().
+		DeleteFrom(expensesTableName).
+		Where(db.Cond{"id": id, "owner_id": userID}).
+		Exec()
+	if err != nil {
+		logging.Logger.Error("could not delete expense from mariadb", zap.Error(err))
+		return models.Expense{}, err
+	}
+	return expense, nil
+}
+
+func insertExpenseTags(sess db.Session, expenseID string, tags []string) error {
+	for _, tag := range tags {
+		_, err := sess.Collection(expenseTagsTableName).Insert(expenseTag{ExpenseID: expenseID, Tag: tag})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
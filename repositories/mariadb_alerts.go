@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/upper/db/v4"
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+const budgetAlertsTableName = "budget_alerts"
+
+// alertRow represents a row of the budget alerts table, amounts are DECIMAL amounts of the budget currency
+type alertRow struct {
+	ID           string    `db:"id"`
+	BudgetID     string    `db:"budget_id"`
+	OwnerID      string    `db:"owner_id"`
+	Threshold    int       `db:"threshold"`
+	PeriodStart  time.Time `db:"period_start"`
+	Spent        string    `db:"spent"`
+	BudgetAmount string    `db:"budget_amount"`
+	Currency     string    `db:"currency"`
+	PercentUsed  float64   `db:"percent_used"`
+	Status       string    `db:"status"`
+	Attempts     int       `db:"attempts"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+func newAlertRow(alert models.BudgetAlert) alertRow {
+	return alertRow{
+		ID:           alert.ID,
+		BudgetID:     alert.BudgetID,
+		OwnerID:      alert.OwnerID,
+		Threshold:    alert.Threshold,
+		PeriodStart:  alert.PeriodStart,
+		Spent:        alert.Spent.String(),
+		BudgetAmount: alert.BudgetAmount.String(),
+		Currency:     alert.BudgetAmount.Currency,
+		PercentUsed:  alert.PercentUsed,
+		Status:       alert.Status,
+		Attempts:     alert.Attempts,
+		CreatedAt:    alert.CreatedAt,
+	}
+}
+
+func (r alertRow) alert() (models.BudgetAlert, error) {
+	spent, err := models.ParseStoredMoney(r.Spent, r.Currency)
+	if err != nil {
+		return models.BudgetAlert{}, err
+	}
+	amount, err := models.ParseStoredMoney(r.BudgetAmount, r.Currency)
+	if err != nil {
+		return models.BudgetAlert{}, err
+	}
+	alert := models.BudgetAlert{
+		ID:           r.ID,
+		BudgetID:     r.BudgetID,
+		OwnerID:      r.OwnerID,
+		Threshold:    r.Threshold,
+		PeriodStart:  r.PeriodStart,
+		Spent:        spent,
+		BudgetAmount: amount,
+		PercentUsed:  r.PercentUsed,
+		Status:       r.Status,
+		Attempts:     r.Attempts,
+		CreatedAt:    r.CreatedAt,
+	}
+	return alert, nil
+}
+
+// CreateAlert saves a brand new budget alert into MariaDB, unless the same alert was already fired
+func (d MariaDBDriver) CreateAlert(alert models.BudgetAlert) error {
+	_, err := d.mariaDB.Collection(budgetAlertsTableName).Insert(newAlertRow(alert))
+	if IsConflict(err) {
+		return models.ConflictError{
+			Message: fmt.Sprintf("alert of %d%% was already fired for budget with id: %s", alert.Threshold, alert.BudgetID),
+		}
+	}
+	if err != nil {
+		logging.Logger.Error("could not create budget alert record in mariadb", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// UpdateAlert updates the delivery status of a budget alert in MariaDB
+func (d MariaDBDriver) UpdateAlert(alert models.BudgetAlert) error {
+	err := d.mariaDB.Collection(budgetAlertsTableName).
+		Find(db.Cond{"id": alert.ID}).
+		Update(map[string]interface{}{
+			"status":   alert.Status,
+			"attempts": alert.Attempts,
+		})
+	if err != nil {
+		logging.Logger.Error("could not update budget alert in mariadb", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetAlerts fetches the alerts of a budget of a given user from MariaDB ordered by period start and threshold
+func (d MariaDBDriver) GetAlerts(userID, budgetID string) ([]models.BudgetAlert, error) {
+	var rows []alertRow
+	err := d.mariaDB.Collection(budgetAlertsTableName).
+		Find(db.Cond{"budget_id": budgetID, "owner_id": userID}).
+		OrderBy("period_start", "threshold").
+		All(&rows)
+	if err != nil {
+		logging.Logger.Error("could not select budget alert records from mariadb", zap.Error(err))
+		return []models.BudgetAlert{}, err
+	}
+	alerts := make([]models.BudgetAlert, 0, len(rows))
+	for _, row := range rows {
+		alert, err := row.alert()
+		if err != nil {
+			return []models.BudgetAlert{}, err
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/upper/db/v4"
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+const attachmentsTableName = "expense_attachments"
+
+// GetAttachments fetches the attachments of an expense of a given user from MariaDB, oldest first
+func (d MariaDBDriver) GetAttachments(userID, expenseID string) ([]models.Attachment, error) {
+	attachments := make([]models.Attachment, 0)
+	err := d.mariaDB.Collection(attachmentsTableName).
+		Find(db.Cond{"expense_id": expenseID, "owner_id": userID}).
+		OrderBy("created_at").
+		All(&attachments)
+	if err != nil {
+		logging.Logger.Error("could not select attachment records from mariadb", zap.Error(err))
+		return []models.Attachment{}, err
+	}
+	return attachments, nil
+}
+
+// GetAttachment fetches an attachment of an expense of a given user by a given ID from MariaDB
+func (d MariaDBDriver) GetAttachment(userID, expenseID, id string) (models.Attachment, error) {
+	var attachment models.Attachment
+	err := d.mariaDB.Collection(attachmentsTableName).
+		Find(db.Cond{"id": id, "expense_id": expenseID, "owner_id": userID}).
+		One(&attachment)
+	if errors.Is(err, db.ErrNoMoreRows) {
+		logging.Logger.Debug("could not find attachment in mariadb", zap.String("id", id))
+		e := models.ResourceNotFoundError{
+			Message: fmt.Sprintf("could not find attachment with id: %s", id),
+		}
+		return models.Attachment{}, e
+	}
+	if err != nil {
+		logging.Logger.Error("could not select attachment record from mariadb", zap.Error(err))
+		return models.Attachment{}, err
+	}
+	return attachment, nil
+}
+
+// CreateAttachment saves the metadata of a brand new attachment into MariaDB
+func (d MariaDBDriver) CreateAttachment(attachment models.Attachment) error {
+	_, err := d.mariaDB.Collection(attachmentsTableName).Insert(attachment)
+	if err != nil {
+		logging.Logger.Error("could not create attachment record in mariadb", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// DeleteAttachment deletes the metadata of an attachment of a given user from MariaDB
+func (d MariaDBDriver) DeleteAttachment(userID, expenseID, id string) error {
+	if _, err := d.GetAttachment(userID, expenseID, id); err != nil {
+		return err
+	}
+	_, err := d.mariaDB.SQL().
+		DeleteFrom(attachmentsTableName).
+		Where(db.Cond{"id": id, "expense_id": expenseID, "owner_id": userID}).
+		Exec()
+	if err != nil {
+		logging.Logger.Error("could not delete attachment from mariadb", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upper/db/v4"
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+const budgetsTableName = "budgets"
+
+// budgetRow represents a row of the budgets table, amounts are stored as DECIMAL amounts of major units
+type budgetRow struct {
+	ID         string    `db:"id"`
+	OwnerID    string    `db:"owner_id"`
+	Amount     string    `db:"amount"`
+	Currency   string    `db:"currency"`
+	Period     string    `db:"period"`
+	CategoryID string    `db:"category_id"`
+	CreatedAt  time.Time `db:"created_at"`
+	ModifiedAt time.Time `db:"modified_at"`
+}
+
+func newBudgetRow(budget models.Budget) budgetRow {
+	return budgetRow{
+		ID:         budget.ID,
+		OwnerID:    budget.OwnerID,
+		Amount:     budget.Amount.String(),
+		Currency:   budget.Amount.Currency,
+		Period:     budget.Period,
+		CategoryID: budget.CategoryID,
+		CreatedAt:  budget.CreatedAt,
+		ModifiedAt: budget.ModifiedAt,
+	}
+}
+
+func (r budgetRow) budget() (models.Budget, error) {
+	amount, err := models.ParseStoredMoney(r.Amount, r.Currency)
+	if err != nil {
+		return models.Budget{}, err
+	}
+	budget := models.Budget{
+		ID:         r.ID,
+		OwnerID:    r.OwnerID,
+		Amount:     amount,
+		Period:     r.Period,
+		CategoryID: r.CategoryID,
+		CreatedAt:  r.CreatedAt,
+		ModifiedAt: r.ModifiedAt,
+	}
+	return budget, nil
+}
+
+// GetBudgets fetches all the budgets of a given user from MariaDB
+func (d MariaDBDriver) GetBudgets(userID string) ([]models.Budget, error) {
+	var rows []budgetRow
+	err := d.mariaDB.Collection(budgetsTableName).
+		Find(db.Cond{"owner_id": userID}).
+		OrderBy("created_at").
+		All(&rows)
+	if err != nil {
+		logging.Logger.Error("could not select budget records from mariadb", zap.Error(err))
+		return []models.Budget{}, err
+	}
+	budgets := make([]models.Budget, 0, len(rows))
+	for _, row := range rows {
+		budget, err := row.budget()
+		if err != nil {
+			return []models.Budget{}, err
+		}
+		budgets = append(budgets, budget)
+	}
+	return budgets, nil
+}
+
+// GetBudget fetches a budget of a given user by a given ID from MariaDB
+func (d MariaDBDriver) GetBudget(userID, id string) (models.Budget, error) {
+	return d.findBudget(userID, id)
+}
+
+// CreateBudget creates a brand new budget for a given user and saves it into MariaDB
+func (d MariaDBDriver) CreateBudget(req models.CreateBudgetRequest) (models.Budget, error) {
+	budget := models.Budget{
+		ID:         uuid.New().String(),
+		OwnerID:    req.UserID,
+		Amount:     req.Amount,
+		Period:     req.Period,
+		CategoryID: req.CategoryID,
+		CreatedAt:  time.Now().UTC(),
+		ModifiedAt: time.Now().UTC(),
+	}
+	_, err := d.mariaDB.Collection(budgetsTableName).Insert(newBudgetRow(budget))
+	if err != nil {
+		logging.Logger.Error("could not create budget record in mariadb", zap.Error(err))
+		return models.Budget{}, err
+	}
+	return budget, nil
+}
+
+// UpdateBudget updates an existing budget of a given user in MariaDB
+func (d MariaDBDriver) UpdateBudget(req models.UpdateBudgetRequest) (models.Budget, error) {
+	budget, err := d.findBudget(req.UserID, req.ID)
+	if err != nil {
+		return models.Budget{}, err
+	}
+	if !applyBudgetUpdate(&budget, req) {
+		return budget, nil
+	}
+	err = d.mariaDB.Collection(budgetsTableName).
+		Find(db.Cond{"id": budget.ID}).
+		Update(newBudgetRow(budget))
+	if err != nil {
+		logging.Logger.Error("could not update budget in mariadb", zap.Error(err))
+		return models.Budget{}, err
+	}
+	return budget, nil
+}
+
+// DeleteBudget deletes a budget of a given user from MariaDB
+func (d MariaDBDriver) DeleteBudget(userID, id string) error {
+	if _, err := d.findBudget(userID, id); err != nil {
+		return err
+	}
+	_, err := d.mariaDB.SQL().
+		DeleteFrom(budgetsTableName).
+		Where(db.Cond{"id": id, "owner_id": userID}).
+		Exec()
+	if err != nil {
+		logging.Logger.Error("could not delete budget from mariadb", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (d MariaDBDriver) findBudget(userID, id string) (models.Budget, error) {
+	var row budgetRow
+	err := d.mariaDB.Collection(budgetsTableName).
+		Find(db.Cond{"id": id, "owner_id": userID}).
+		One(&row)
+	if errors.Is(err, db.ErrNoMoreRows) {
+		logging.Logger.Debug("could not find budget in mariadb", zap.String("id", id))
+		e := models.ResourceNotFoundError{
+			Message: fmt.Sprintf("could not find budget with id: %s", id),
+		}
+		return models.Budget{}, e
+	}
+	if err != nil {
+		logging.Logger.Error("could not select budget record from mariadb", zap.Error(err))
+		return models.Budget{}, err
+	}
+	return row.budget()
+}
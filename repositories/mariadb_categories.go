@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upper/db/v4"
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// GetCategories fetches all the categories of a given user from MariaDB
+func (d MariaDBDriver) GetCategories(userID string) ([]models.Category, error) {
+	categories := make([]models.Category, 0)
+	err := d.mariaDB.Collection(categoriesTableName).
+		Find(db.Cond{"owner_id": userID}).
+		OrderBy("name").
+		All(&categories)
+	if err != nil {
+		logging.Logger.Error("could not select category records from mariadb", zap.Error(err))
+		return []models.Category{}, err
+	}
+	return categories, nil
+}
+
+// GetCategory fetches a category of a given user by a given ID from MariaDB
+func (d MariaDBDriver) GetCategory(userID, id string) (models.Category, error) {
+	return d.findCategory(d.mariaDB, userID, id)
+}
+
+// CreateCategory creates a brand new category for a given user and saves it into MariaDB
+func (d MariaDBDriver) CreateCategory(userID, name string) (models.Category, error) {
+	category := models.Category{
+		ID:         uuid.New().String(),
+		OwnerID:    userID,
+		Name:       name,
+		CreatedAt:  time.Now().UTC(),
+		ModifiedAt: time.Now().UTC(),
+	}
+	_, err := d.mariaDB.Collection(categoriesTableName).Insert(category)
+	if IsConflict(err) {
+		return models.Category{}, categoryNameConflict(category)
+	}
+	if err != nil {
+		logging.Logger.Error("could not create category record in mariadb", zap.Error(err))
+		return models.Category{}, err
+	}
+	return category, nil
+}
+
+// UpdateCategory renames a category of a given user in MariaDB
+func (d MariaDBDriver) UpdateCategory(userID, id, name string) (models.Category, error) {
+	category, err := d.findCategory(d.mariaDB, userID, id)
+	if err != nil {
+		return models.Category{}, err
+	}
+	if category.Name == name {
+		return category, nil
+	}
+	category.Name = name
+	category.ModifiedAt = time.Now().UTC()
+	err = d.mariaDB.Collection(categoriesTableName).UpdateReturning(&category)
+	if IsConflict(err) {
+		return models.Category{}, categoryNameConflict(category)
+	}
+	if err != nil {
+		logging.Logger.Error("could not update category in mariadb", zap.Error(err))
+		return models.Category{}, err
+	}
+	return category, nil
+}
+
+// DeleteCategory deletes a category of a given user from MariaDB,
+// expenses of the category either reject the deletion or get moved to uncategorized
+func (d MariaDBDriver) DeleteCategory(req models.DeleteCategoryRequest) error {
+	return d.mariaDB.Tx(func(sess db.Session) error {
+		if _, err := d.findCategory(sess, req.UserID, req.ID); err != nil {
+			return err
+		}
+
+		expenses := sess.Collection(expensesTableName).Find(db.Cond{
+			"owner_id":    req.UserID,
+			"category_id": req.ID,
+		})
+		count, err := expenses.Count()
+		if err != nil {
+			logging.Logger.Error("could not count category expenses in mariadb", zap.Error(err))
+			return err
+		}
+		if count > 0 && !req.Reassign {
+			return models.ConflictError{
+				Message: fmt.Sprintf("category is still used by %d expenses", count),
+			}
+		}
+		if count > 0 {
+			err = expenses.Update(map[string]interface{}{
+				"category_id": "",
+				"modified_at": time.Now().UTC(),
+			})
+			if err != nil {
+				logging.Logger.Error("could not reassign category expenses in mariadb", zap.Error(err))
+				return err
+			}
+		}
+
+		_, err = sess.SQL().
+			DeleteFrom(categoriesTableName).
+			Where(db.Cond{"id": req.ID}).
+			Exec()
+		if err != nil {
+			logging.Logger.Error("could not delete category from mariadb", zap.Error(err))
+			return err
+		}
+		return nil
+	})
+}
+
+func (d MariaDBDriver) findCategory(sess db.Session, userID, id string) (models.Category, error) {
+	var category models.Category
+	err := sess.Collection(categoriesTableName).
+		Find(db.Cond{"id": id, "owner_id": userID}).
+		One(&category)
+	if errors.Is(err, db.ErrNoMoreRows) {
+		logging.Logger.Debug("could not find category in mariadb", zap.String("id", id))
+		e := models.ResourceNotFoundError{
+			Message: fmt.Sprintf("could not find category with id: %s", id),
+		}
+		return models.Category{}, e
+	}
+	if err != nil {
+		logging.Logger.Error("could not select category record from mariadb", zap.Error(err))
+		return models.Category{}, err
+	}
+	return category, nil
+}
+
+// categoryNameConflict represents the error of a category name that violates the unique (owner_id, name) index
+func categoryNameConflict(category models.Category) error {
+	return models.ConflictError{
+		Message: fmt.Sprintf("category with name: %s already exists", category.Name),
+	}
+}
@@ -0,0 +1,174 @@
+package repositories
+
+import (
+	"strings"
+	"time"
+
+	"github.com/upper/db/v4"
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+const ratesTableName = "exchange_rates"
+
+// rateRow represents a row of the exchange rates table, one row per day and currency
+type rateRow struct {
+	Date     time.Time `db:"date"`
+	Base     string    `db:"base"`
+	Currency string    `db:"currency"`
+	Rate     string    `db:"rate"`
+}
+
+// PutRates saves the exchange rates of a day into MariaDB, replacing the ones already stored for that day
+func (d MariaDBDriver) PutRates(rates models.DailyRates) error {
+	_, err := d.ImportRates([]models.DailyRates{rates}, true)
+	return err
+}
+
+// ImportRates saves the exchange rates of a list of days into MariaDB in a single transaction,
+// days already stored get replaced when replace is set and are kept otherwise
+func (d MariaDBDriver) ImportRates(days []models.DailyRates, replace bool) (int, error) {
+	saved := 0
+	err := d.mariaDB.Tx(func(sess db.Session) error {
+		saved = 0
+		stored, err := storedRateDates(sess, days)
+		if err != nil {
+			return err
+		}
+		for _, rates := range days {
+			if stored[rates.Date] && !replace {
+				continue
+			}
+			if err = putRates(sess, rates, stored[rates.Date]); err != nil {
+				return err
+			}
+			stored[rates.Date] = true
+			saved++
+		}
+		return nil
+	})
+	if err != nil {
+		logging.Logger.Error("could not save rates into mariadb", zap.Error(err))
+		return 0, err
+	}
+	return saved, nil
+}
+
+// storedRateDates returns the dates of a list of days that already have rates stored
+func storedRateDates(sess db.Session, days []models.DailyRates) (map[string]bool, error) {
+	stored := map[string]bool{}
+	if len(days) == 0 {
+		return stored, nil
+	}
+	from, to := days[0].Date, days[0].Date
+	for _, rates := range days {
+		if rates.Date < from {
+			from = rates.Date
+		}
+		if rates.Date > to {
+			to = rates.Date
+		}
+	}
+	var rows []rateRow
+	err := sess.SQL().
+		Select("date").
+		Distinct().
+		From(ratesTableName).
+		Where(db.Cond{"date >=": from, "date <=": to}).
+		All(&rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		stored[row.Date.Format(models.DateLayout)] = true
+	}
+	return stored, nil
+}
+
+func putRates(sess db.Session, rates models.DailyRates, stored bool) error {
+	date, err := time.Parse(models.DateLayout, rates.Date)
+	if err != nil {
+		return err
+	}
+	if stored {
+		_, err = sess.SQL().
+			DeleteFrom(ratesTableName).
+			Where(db.Cond{"date": date}).
+			Exec()
+		if err != nil {
+			return err
+		}
+	}
+	for currency, rate := range rates.Rates {
+		row := rateRow{Date: date, Base: rates.Base, Currency: currency, Rate: rate}
+		if _, err = sess.Collection(ratesTableName).Insert(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetRates fetches the exchange rates of the days within an inclusive date range from MariaDB,
+// empty bounds are ignored
+func (d MariaDBDriver) GetRates(from, to string) ([]models.DailyRates, error) {
+	cond := db.Cond{}
+	if from != "" {
+		cond["date >="] = from
+	}
+	if to != "" {
+		cond["date <="] = to
+	}
+	var rows []rateRow
+	err := d.mariaDB.Collection(ratesTableName).
+		Find(cond).
+		OrderBy("date", "currency").
+		All(&rows)
+	if err != nil {
+		logging.Logger.Error("could not select rates from mariadb", zap.Error(err))
+		return []models.DailyRates{}, err
+	}
+	return toDailyRates(rows), nil
+}
+
+// GetRatesOn fetches the latest exchange rates stored on or before a given date from MariaDB
+func (d MariaDBDriver) GetRatesOn(date string) (models.DailyRates, error) {
+	var rows []rateRow
+	err := d.mariaDB.SQL().
+		SelectFrom(ratesTableName).
+		Where("date = (SELECT MAX(date) FROM "+ratesTableName+" WHERE date <= ?)", date).
+		All(&rows)
+	if err != nil {
+		logging.Logger.Error("could not select rates from mariadb", zap.Error(err))
+		return models.DailyRates{}, err
+	}
+	rates := toDailyRates(rows)
+	if len(rates) == 0 {
+		return models.DailyRates{}, models.ResourceNotFoundError{
+			Message: "could not find exchange rates on or before: " + date,
+		}
+	}
+	return rates[0], nil
+}
+
+// toDailyRates groups exchange rate rows ordered by date into the rates of every day
+func toDailyRates(rows []rateRow) []models.DailyRates {
+	rates := make([]models.DailyRates, 0)
+	for _, row := range rows {
+		date := row.Date.Format(models.DateLayout)
+		if len(rates) == 0 || rates[len(rates)-1].Date != date {
+			rates = append(rates, models.DailyRates{
+				Date:  date,
+				Base:  row.Base,
+				Rates: map[string]string{},
+			})
+		}
+		rate := row.Rate
+		if strings.Contains(rate, ".") {
+			rate = strings.TrimSuffix(strings.TrimRight(rate, "0"), ".")
+		}
+		rates[len(rates)-1].Rates[row.Currency] = rate
+	}
+	return rates
+}
@@ -0,0 +1,238 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upper/db/v4"
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+const recurringTableName = "recurring_expenses"
+
+// recurringRow represents a row of the recurring expenses table, the template tags are stored comma separated
+type recurringRow struct {
+	ID         string     `db:"id"`
+	OwnerID    string     `db:"owner_id"`
+	Title      string     `db:"title"`
+	Amount     string     `db:"amount"`
+	Currency   string     `db:"currency"`
+	CategoryID string     `db:"category_id"`
+	Tags       string     `db:"tags"`
+	Frequency  string     `db:"frequency"`
+	Interval   int        `db:"schedule_interval"`
+	MonthDay   int        `db:"month_day"`
+	StartDate  time.Time  `db:"start_date"`
+	EndDate    *time.Time `db:"end_date"`
+	LastRun    *time.Time `db:"last_run"`
+	CreatedAt  time.Time  `db:"created_at"`
+	ModifiedAt time.Time  `db:"modified_at"`
+}
+
+func newRecurringRow(recurring models.RecurringExpense) (recurringRow, error) {
+	start, err := time.Parse(models.DateLayout, recurring.StartDate)
+	if err != nil {
+		return recurringRow{}, err
+	}
+	row := recurringRow{
+		ID:         recurring.ID,
+		OwnerID:    recurring.OwnerID,
+		Title:      recurring.Template.Title,
+		Amount:     recurring.Template.Price.String(),
+		Currency:   recurring.Template.Price.Currency,
+		CategoryID: recurring.Template.CategoryID,
+		Tags:       strings.Join(recurring.Template.Tags, ","),
+		Frequency:  recurring.Schedule.Frequency,
+		Interval:   recurring.Schedule.Interval,
+		MonthDay:   recurring.Schedule.MonthDay,
+		StartDate:  start,
+		CreatedAt:  recurring.CreatedAt,
+		ModifiedAt: recurring.ModifiedAt,
+	}
+	if row.EndDate, err = parseOptionalDate(recurring.EndDate); err != nil {
+		return recurringRow{}, err
+	}
+	if row.LastRun, err = parseOptionalDate(recurring.LastRun); err != nil {
+		return recurringRow{}, err
+	}
+	return row, nil
+}
+
+func (r recurringRow) recurringExpense() (models.RecurringExpense, error) {
+	price, err := models.ParseStoredMoney(r.Amount, r.Currency)
+	if err != nil {
+		return models.RecurringExpense{}, err
+	}
+	var tags []string
+	if r.Tags != "" {
+		tags = strings.Split(r.Tags, ",")
+	}
+	recurring := models.RecurringExpense{
+		ID:      r.ID,
+		OwnerID: r.OwnerID,
+		Template: models.ExpenseTemplate{
+			Title:      r.Title,
+			Price:      price,
+			CategoryID: r.CategoryID,
+			Tags:       tags,
+		},
+		Schedule: models.Schedule{
+			Frequency: r.Frequency,
+			Interval:  r.Interval,
+			MonthDay:  r.MonthDay,
+		},
+		StartDate:  r.StartDate.Format(models.DateLayout),
+		EndDate:    formatOptionalDate(r.EndDate),
+		LastRun:    formatOptionalDate(r.LastRun),
+		CreatedAt:  r.CreatedAt,
+		ModifiedAt: r.ModifiedAt,
+	}
+	return recurring, nil
+}
+
+// GetRecurringExpenses fetches all the recurring expenses of a given user from MariaDB
+func (d MariaDBDriver) GetRecurringExpenses(userID string) ([]models.RecurringExpense, error) {
+	return d.findRecurringExpenses(db.Cond{"owner_id": userID})
+}
+
+// GetAllRecurringExpenses fetches the recurring expenses of every user from MariaDB
+func (d MariaDBDriver) GetAllRecurringExpenses() ([]models.RecurringExpense, error) {
+	return d.findRecurringExpenses(db.Cond{})
+}
+
+// GetRecurringExpense fetches a recurring expense of a given user by a given ID from MariaDB
+func (d MariaDBDriver) GetRecurringExpense(userID, id string) (models.RecurringExpense, error) {
+	return d.findRecurringExpense(userID, id)
+}
+
+// CreateRecurringExpense creates a brand new recurring expense for a given user and saves it into MariaDB
+func (d MariaDBDriver) CreateRecurringExpense(req models.CreateRecurringExpenseRequest) (models.RecurringExpense, error) {
+	recurring := newRecurringExpense(req, uuid.New().String())
+	row, err := newRecurringRow(recurring)
+	if err != nil {
+		return models.RecurringExpense{}, err
+	}
+	if _, err = d.mariaDB.Collection(recurringTableName).Insert(row); err != nil {
+		logging.Logger.Error("could not create recurring expense record in mariadb", zap.Error(err))
+		return models.RecurringExpense{}, err
+	}
+	return recurring, nil
+}
+
+// UpdateRecurringExpense updates an existing recurring expense of a given user in MariaDB
+func (d MariaDBDriver) UpdateRecurringExpense(req models.UpdateRecurringExpenseRequest) (models.RecurringExpense, error) {
+	recurring, err := d.findRecurringExpense(req.UserID, req.ID)
+	if err != nil {
+		return models.RecurringExpense{}, err
+	}
+	if err = applyRecurringUpdate(&recurring, req); err != nil {
+		return models.RecurringExpense{}, err
+	}
+	row, err := newRecurringRow(recurring)
+	if err != nil {
+		return models.RecurringExpense{}, err
+	}
+	err = d.mariaDB.Collection(recurringTableName).
+		Find(db.Cond{"id": recurring.ID}).
+		Update(row)
+	if err != nil {
+		logging.Logger.Error("could not update recurring expense in mariadb", zap.Error(err))
+		return models.RecurringExpense{}, err
+	}
+	return recurring, nil
+}
+
+// DeleteRecurringExpense deletes a recurring expense of a given user from MariaDB,
+// the expenses it already created are kept
+func (d MariaDBDriver) DeleteRecurringExpense(userID, id string) error {
+	if _, err := d.findRecurringExpense(userID, id); err != nil {
+		return err
+	}
+	_, err := d.mariaDB.SQL().
+		DeleteFrom(recurringTableName).
+		Where(db.Cond{"id": id, "owner_id": userID}).
+		Exec()
+	if err != nil {
+		logging.Logger.Error("could not delete recurring expense from mariadb", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// SetRecurringLastRun saves the date of the latest materialized occurrence of a recurring expense into MariaDB
+func (d MariaDBDriver) SetRecurringLastRun(id, date string) error {
+	_, err := d.mariaDB.SQL().
+		Update(recurringTableName).
+		Set("last_run", date).
+		Where(db.Cond{"id": id}).
+		Exec()
+	if err != nil {
+		logging.Logger.Error("could not save recurring expense last run in mariadb", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (d MariaDBDriver) findRecurringExpenses(cond db.Cond) ([]models.RecurringExpense, error) {
+	var rows []recurringRow
+	err := d.mariaDB.Collection(recurringTableName).
+		Find(cond).
+		OrderBy("created_at").
+		All(&rows)
+	if err != nil {
+		logging.Logger.Error("could not select recurring expense records from mariadb", zap.Error(err))
+		return []models.RecurringExpense{}, err
+	}
+	recurring := make([]models.RecurringExpense, 0, len(rows))
+	for _, row := range rows {
+		r, err := row.recurringExpense()
+		if err != nil {
+			return []models.RecurringExpense{}, err
+		}
+		recurring = append(recurring, r)
+	}
+	return recurring, nil
+}
+
+func (d MariaDBDriver) findRecurringExpense(userID, id string) (models.RecurringExpense, error) {
+	var row recurringRow
+	err := d.mariaDB.Collection(recurringTableName).
+		Find(db.Cond{"id": id, "owner_id": userID}).
+		One(&row)
+	if errors.Is(err, db.ErrNoMoreRows) {
+		logging.Logger.Debug("could not find recurring expense in mariadb", zap.String("id", id))
+		e := models.ResourceNotFoundError{
+			Message: fmt.Sprintf("could not find recurring expense with id: %s", id),
+		}
+		return models.RecurringExpense{}, e
+	}
+	if err != nil {
+		logging.Logger.Error("could not select recurring expense record from mariadb", zap.Error(err))
+		return models.RecurringExpense{}, err
+	}
+	return row.recurringExpense()
+}
+
+func parseOptionalDate(date string) (*time.Time, error) {
+	if date == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(models.DateLayout, date)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func formatOptionalDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(models.DateLayout)
+}
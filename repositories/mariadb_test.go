@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+func TestSummaryRowBucket(t *testing.T) {
+	row := summaryRow{Bucket: "2021-03", Currency: "USD", Count: 2, Total: "123456789012345.5000", Min: "1", Max: "9"}
+	bucket, err := row.bucket()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bucket.Total.Amount != 1234567890123455000 {
+		t.Fatalf("expected a total beyond the whole digits of stored money, got: %d", bucket.Total.Amount)
+	}
+
+	row.Total = "922337203685477.5808"
+	if _, err = row.bucket(); err == nil {
+		t.Fatalf("expected an error for a total out of range")
+	}
+	if _, ok := err.(models.DataValidationError); !ok {
+		t.Fatalf("expected a data validation error, got: %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upper/db/v4"
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// CreateUser creates a brand new user and saves it into MariaDB, the unique email index rejects duplicate emails
+func (d MariaDBDriver) CreateUser(email, passwordHash, role string) (models.User, error) {
+	user := models.User{
+		ID:           uuid.New().String(),
+		Email:        email,
+		PasswordHash: passwordHash,
+		Role:         role,
+		CreatedAt:    time.Now().UTC(),
+		ModifiedAt:   time.Now().UTC(),
+	}
+	_, err := d.mariaDB.Collection(usersTableName).Insert(user)
+	if IsConflict(err) {
+		return models.User{}, models.ConflictError{
+			Message: fmt.Sprintf("user with email: %s already exists", email),
+		}
+	}
+	if err != nil {
+		logging.Logger.Error("could not create user record in mariadb", zap.Error(err))
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// UpdateUserRole changes the role of a given user in MariaDB
+func (d MariaDBDriver) UpdateUserRole(id, role string) (models.User, error) {
+	user, err := d.GetUserByID(id)
+	if err != nil {
+		return models.User{}, err
+	}
+	user.Role = role
+	user.ModifiedAt = time.Now().UTC()
+	err = d.mariaDB.Collection(usersTableName).UpdateReturning(&user)
+	if err != nil {
+		logging.Logger.Error("could not update user role in mariadb", zap.Error(err))
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// GetUserByID fetches a user by a given ID from MariaDB
+func (d MariaDBDriver) GetUserByID(id string) (models.User, error) {
+	return d.findUser(db.Cond{"id": id}, fmt.Sprintf("could not find user with id: %s", id))
+}
+
+// GetUserByEmail fetches a user by a given email from MariaDB
+func (d MariaDBDriver) GetUserByEmail(email string) (models.User, error) {
+	return d.findUser(db.Cond{"email": email}, fmt.Sprintf("could not find user with email: %s", email))
+}
+
+// CreateSession saves a new user session into MariaDB
+func (d MariaDBDriver) CreateSession(id, userID string, expiresAt time.Time) error {
+	session := models.Session{
+		ID:        id,
+		UserID:    userID,
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: expiresAt,
+	}
+	_, err := d.mariaDB.Collection(sessionsTableName).Insert(session)
+	if err != nil {
+		logging.Logger.Error("could not create session record in mariadb", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetSession fetches a user session by a given ID from MariaDB
+func (d MariaDBDriver) GetSession(id string) (models.Session, error) {
+	var session models.Session
+	err := d.mariaDB.Collection(sessionsTableName).
+		Find(db.Cond{"id": id}).
+		One(&session)
+	if errors.Is(err, db.ErrNoMoreRows) {
+		logging.Logger.Debug("could not find session in mariadb", zap.Error(err))
+		return models.Session{}, models.ResourceNotFoundError{Message: "could not find session"}
+	}
+	if err != nil {
+		logging.Logger.Error("could not select session record from mariadb", zap.Error(err))
+		return models.Session{}, err
+	}
+	return session, nil
+}
+
+// DeleteSession deletes a user session by a given ID from MariaDB
+func (d MariaDBDriver) DeleteSession(id string) error {
+	res, err := d.mariaDB.
+		SQL().
+		DeleteFrom(sessionsTableName).
+		Where(db.Cond{"id": id}).
+		Exec()
+	if err != nil {
+		logging.Logger.Error("could not delete session from mariadb", zap.Error(err))
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return models.ResourceNotFoundError{Message: "could not find session"}
+	}
+	return nil
+}
+
+// CreateAPIKey saves a new API key into MariaDB
+func (d MariaDBDriver) CreateAPIKey(key models.APIKey) (models.APIKey, error) {
+	key.ID = uuid.New().String()
+	key.CreatedAt = time.Now().UTC()
+	_, err := d.mariaDB.Collection(apiKeysTableName).Insert(key)
+	if err != nil {
+		logging.Logger.Error("could not create api key record in mariadb", zap.Error(err))
+		return models.APIKey{}, err
+	}
+	return key, nil
+}
+
+// GetAPIKeys fetches all the API keys of a given user from MariaDB, oldest first
+func (d MariaDBDriver) GetAPIKeys(userID string) ([]models.APIKey, error) {
+	keys := make([]models.APIKey, 0)
+	err := d.mariaDB.Collection(apiKeysTableName).
+		Find(db.Cond{"user_id": userID}).
+		OrderBy("created_at", "id").
+		All(&keys)
+	if err != nil {
+		logging.Logger.Error("could not select api key records from mariadb", zap.Error(err))
+		return []models.APIKey{}, err
+	}
+	return keys, nil
+}
+
+// GetAPIKeyByHash fetches an API key by a given key hash from MariaDB
+func (d MariaDBDriver) GetAPIKeyByHash(hash string) (models.APIKey, error) {
+	var apiKey models.APIKey
+	err := d.mariaDB.Collection(apiKeysTableName).
+		Find(db.Cond{"hash": hash}).
+		One(&apiKey)
+	if errors.Is(err, db.ErrNoMoreRows) {
+		logging.Logger.Debug("could not find api key in mariadb", zap.Error(err))
+		return models.APIKey{}, models.ResourceNotFoundError{Message: "could not find api key"}
+	}
+	if err != nil {
+		logging.Logger.Error("could not select api key record from mariadb", zap.Error(err))
+		return models.APIKey{}, err
+	}
+	return apiKey, nil
+}
+
+// TouchAPIKey updates the last usage time of a given API key in MariaDB
+func (d MariaDBDriver) TouchAPIKey(id string, usedAt time.Time) error {
+	_, err := d.mariaDB.
+		SQL().
+		Update(apiKeysTableName).
+		Set("last_used_at", usedAt).
+		Where(db.Cond{"id": id}).
+		Exec()
+	if err != nil {
+		logging.Logger.Error("could not update api key last usage in mariadb", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// DeleteAPIKey deletes an API key of a given user from MariaDB
+func (d MariaDBDriver) DeleteAPIKey(userID, id string) error {
+	res, err := d.mariaDB.
+		SQL().
+		DeleteFrom(apiKeysTableName).
+		Where(db.Cond{"id": id, "user_id": userID}).
+		Exec()
+	if err != nil {
+		logging.Logger.Error("could not delete api key from mariadb", zap.Error(err))
+		return err
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return models.ResourceNotFoundError{
+			Message: fmt.Sprintf("could not find api key with id: %s", id),
+		}
+	}
+	return nil
+}
+
+func (d MariaDBDriver) findUser(cond db.Cond, notFoundMsg string) (models.User, error) {
+	var user models.User
+	err := d.mariaDB.Collection(usersTableName).
+		Find(cond).
+		One(&user)
+	if errors.Is(err, db.ErrNoMoreRows) {
+		logging.Logger.Debug("could not find user in mariadb", zap.Error(err))
+		return models.User{}, models.ResourceNotFoundError{Message: notFoundMsg}
+	}
+	if err != nil {
+		logging.Logger.Error("could not select user record from mariadb", zap.Error(err))
+		return models.User{}, err
+	}
+	return user, nil
+}
@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// Rates represents the exchange Rates repository interface, rates are kept per day
+type Rates interface {
+	PutRates(rates models.DailyRates) error
+	// ImportRates saves the rates of a list of days in a single transaction and returns the amount of saved days,
+	// days already stored get replaced when replace is set and are kept otherwise
+	ImportRates(days []models.DailyRates, replace bool) (int, error)
+	GetRates(from, to string) ([]models.DailyRates, error)
+	GetRatesOn(date string) (models.DailyRates, error)
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// Recurring represents the Recurring expenses repository interface, every user facing method is scoped to the owner user ID
+type Recurring interface {
+	GetRecurringExpenses(userID string) ([]models.RecurringExpense, error)
+	// GetAllRecurringExpenses fetches the recurring expenses of every user, such as for the scheduler
+	GetAllRecurringExpenses() ([]models.RecurringExpense, error)
+	GetRecurringExpense(userID, id string) (models.RecurringExpense, error)
+	CreateRecurringExpense(req models.CreateRecurringExpenseRequest) (models.RecurringExpense, error)
+	UpdateRecurringExpense(req models.UpdateRecurringExpenseRequest) (models.RecurringExpense, error)
+	DeleteRecurringExpense(userID, id string) error
+	SetRecurringLastRun(id, date string) error
+}
+
+func newRecurringExpense(req models.CreateRecurringExpenseRequest, id string) models.RecurringExpense {
+	return models.RecurringExpense{
+		ID:         id,
+		OwnerID:    req.UserID,
+		Template:   req.Template,
+		Schedule:   req.Schedule,
+		StartDate:  req.StartDate,
+		EndDate:    req.EndDate,
+		CreatedAt:  time.Now().UTC(),
+		ModifiedAt: time.Now().UTC(),
+	}
+}
+
+// applyRecurringUpdate applies the given fields of an update recurring expense request,
+// it fails when the updated end date falls before the stored start date
+func applyRecurringUpdate(recurring *models.RecurringExpense, req models.UpdateRecurringExpenseRequest) error {
+	if req.Template != nil {
+		recurring.Template = *req.Template
+	}
+	if req.Schedule != nil {
+		recurring.Schedule = *req.Schedule
+	}
+	if req.EndDate != nil {
+		recurring.EndDate = *req.EndDate
+	}
+	if err := recurring.ValidateDateRange(); err != nil {
+		return err
+	}
+	recurring.ModifiedAt = time.Now().UTC()
+	return nil
+}
@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"testing"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+func TestApplyRecurringUpdateChecksTheStoredStartDate(t *testing.T) {
+	recurring := models.RecurringExpense{StartDate: "2021-04-01", EndDate: "2021-06-01"}
+	endDate := "2021-03-31"
+	err := applyRecurringUpdate(&recurring, models.UpdateRecurringExpenseRequest{EndDate: &endDate})
+	if _, ok := err.(models.DataValidationError); !ok {
+		t.Fatalf("expected a data validation error, got: %v", err)
+	}
+
+	endDate = ""
+	if err := applyRecurringUpdate(&recurring, models.UpdateRecurringExpenseRequest{EndDate: &endDate}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if recurring.EndDate != "" {
+		t.Fatalf("expected the end date to be cleared, got: %s", recurring.EndDate)
+	}
+}
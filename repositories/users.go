@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// Users represents the Users repository interface
+type Users interface {
+	CreateUser(email, passwordHash, role string) (models.User, error)
+	UpdateUserRole(id, role string) (models.User, error)
+	GetUserByID(id string) (models.User, error)
+	GetUserByEmail(email string) (models.User, error)
+	CreateSession(id, userID string, expiresAt time.Time) error
+	GetSession(id string) (models.Session, error)
+	DeleteSession(id string) error
+	CreateAPIKey(key models.APIKey) (models.APIKey, error)
+	GetAPIKeys(userID string) ([]models.APIKey, error)
+	GetAPIKeyByHash(hash string) (models.APIKey, error)
+	TouchAPIKey(id string, usedAt time.Time) error
+	DeleteAPIKey(userID, id string) error
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/repositories"
+)
+
+// Alerts represents the budget Alerts service. Budgets get evaluated and alerts get delivered in the background,
+// off the request path of the expenses that changed them
+type Alerts struct {
+	AlertsRepo repositories.Alerts
+	Budgets    Budgets
+	Webhooks   Webhooks
+
+	mu       sync.Mutex
+	pending  []models.Expense
+	wake     chan struct{}
+	stopping chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+	workers  sync.WaitGroup
+}
+
+// GetAlerts fetches the alert history of a budget of a given user
+func (s *Alerts) GetAlerts(userID, budgetID string) ([]models.BudgetAlert, error) {
+	if _, err := s.Budgets.GetBudget(userID, budgetID); err != nil {
+		return []models.BudgetAlert{}, err
+	}
+	alerts, err := s.AlertsRepo.GetAlerts(userID, budgetID)
+	if err != nil {
+		logging.Logger.Error("could not fetch budget alerts from db", zap.Error(err))
+		return []models.BudgetAlert{}, classifyError(err)
+	}
+	return alerts, nil
+}
+
+// Start starts evaluating the budgets of the expenses queued by EvaluateBudgets in the background
+func (s *Alerts) Start() {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.wake = make(chan struct{}, 1)
+	s.stopping = make(chan struct{})
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		for {
+			select {
+			case <-s.wake:
+				s.evaluatePending()
+			case <-s.stopping:
+				s.evaluatePending()
+				return
+			}
+		}
+	}()
+}
+
+// Stop evaluates the expenses still queued, waits for the alerts still being delivered and stops the service.
+// Once a given context is done, the queued expenses are dropped and the ongoing deliveries get cancelled
+func (s *Alerts) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	close(s.stopping)
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+
+	defer s.cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// EvaluateBudgets queues created or updated expenses, so that the budgets they count towards get re-evaluated
+// in the background, see Start
+func (s *Alerts) EvaluateBudgets(expenses ...models.Expense) {
+	s.mu.Lock()
+	s.pending = append(s.pending, expenses...)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// evaluatePending re-evaluates the budgets the queued expenses count towards, until the service gets cancelled.
+// Every budget is evaluated once per period, however many of the queued expenses fall into the period,
+// and fires an alert for every threshold crossed within the period for the first time
+func (s *Alerts) evaluatePending() {
+	s.mu.Lock()
+	expenses := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	budgets := map[string][]models.Budget{}
+	evaluated := map[string]bool{}
+	for _, expense := range expenses {
+		owned, ok := budgets[expense.OwnerID]
+		if !ok {
+			var err error
+			if owned, err = s.Budgets.GetBudgets(expense.OwnerID); err != nil {
+				continue
+			}
+			budgets[expense.OwnerID] = owned
+		}
+		for _, budget := range owned {
+			if budget.CategoryID != "" && budget.CategoryID != expense.CategoryID {
+				continue
+			}
+			periodStart, _ := models.BudgetPeriodRange(budget.Period, expense.CreatedAt)
+			key := budget.ID + ":" + periodStart.Format(models.DateLayout)
+			if evaluated[key] {
+				continue
+			}
+			if s.ctx.Err() != nil {
+				return
+			}
+			evaluated[key] = true
+			s.evaluate(budget, expense.CreatedAt)
+		}
+	}
+}
+
+// evaluate re-evaluates a budget in the period of a given time
+func (s *Alerts) evaluate(budget models.Budget, at time.Time) {
+	status, err := s.Budgets.GetBudgetStatus(models.BudgetStatusRequest{
+		ID:     budget.ID,
+		UserID: budget.OwnerID,
+		At:     at,
+	})
+	if err != nil {
+		logging.Logger.Error("could not evaluate budget", zap.String("id", budget.ID), zap.Error(err))
+		return
+	}
+	for _, threshold := range models.BudgetAlertThresholds {
+		if status.PercentUsed >= float64(threshold) {
+			s.fire(status, threshold)
+		}
+	}
+}
+
+// fire saves the alert of a crossed budget threshold and delivers it, unless it was already fired in the period
+func (s *Alerts) fire(status models.BudgetStatus, threshold int) {
+	alert := models.BudgetAlert{
+		ID:           uuid.New().String(),
+		BudgetID:     status.Budget.ID,
+		OwnerID:      status.Budget.OwnerID,
+		Threshold:    threshold,
+		PeriodStart:  status.PeriodStart,
+		Spent:        status.Spent,
+		BudgetAmount: status.Budget.Amount,
+		PercentUsed:  status.PercentUsed,
+		Status:       models.PendingAlertStatus,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if len(s.Webhooks.URLs) == 0 {
+		alert.Status = models.SkippedAlertStatus
+	}
+	err := s.AlertsRepo.CreateAlert(alert)
+	if _, ok := classifyError(err).(models.ConflictError); ok {
+		return
+	}
+	if err != nil {
+		logging.Logger.Error("could not save budget alert", zap.Error(err))
+		return
+	}
+	logging.Logger.Info("budget threshold crossed", zap.String("budget_id", alert.BudgetID), zap.Int("threshold", threshold))
+	if alert.Status == models.SkippedAlertStatus {
+		return
+	}
+
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		event := models.WebhookEvent{
+			Event:     models.BudgetThresholdEvent,
+			Data:      alert,
+			CreatedAt: time.Now().UTC(),
+		}
+		attempts, err := s.Webhooks.Deliver(s.ctx, event)
+		alert.Attempts = attempts
+		alert.Status = models.DeliveredAlertStatus
+		if err != nil {
+			alert.Status = models.FailedAlertStatus
+		}
+		if err = s.AlertsRepo.UpdateAlert(alert); err != nil {
+			logging.Logger.Error("could not update budget alert delivery", zap.Error(err))
+		}
+	}()
+}
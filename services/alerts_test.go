@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/repositories"
+)
+
+// fakeBudgetsRepo represents a budgets repository of a fixed list of budgets
+type fakeBudgetsRepo struct {
+	repositories.Budgets
+	budgets []models.Budget
+}
+
+func (r fakeBudgetsRepo) GetBudgets(string) ([]models.Budget, error) {
+	return r.budgets, nil
+}
+
+func (r fakeBudgetsRepo) GetBudget(_, id string) (models.Budget, error) {
+	for _, budget := range r.budgets {
+		if budget.ID == id {
+			return budget, nil
+		}
+	}
+	return models.Budget{}, models.ResourceNotFoundError{Message: "no budget"}
+}
+
+// fakeAlertsRepo represents an alerts repository that keeps alerts in memory, unique per budget, period and threshold
+type fakeAlertsRepo struct {
+	mu     sync.Mutex
+	alerts map[string]models.BudgetAlert
+}
+
+func (r *fakeAlertsRepo) CreateAlert(alert models.BudgetAlert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := fmt.Sprintf("%s:%s:%d", alert.BudgetID, alert.PeriodStart.Format(models.DateLayout), alert.Threshold)
+	if _, ok := r.alerts[key]; ok {
+		return models.ConflictError{Message: "alert already fired"}
+	}
+	r.alerts[key] = alert
+	return nil
+}
+
+func (r *fakeAlertsRepo) UpdateAlert(alert models.BudgetAlert) error {
+	return r.put(alert)
+}
+
+func (r *fakeAlertsRepo) GetAlerts(string, string) ([]models.BudgetAlert, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	alerts := make([]models.BudgetAlert, 0, len(r.alerts))
+	for _, alert := range r.alerts {
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
+
+func (r *fakeAlertsRepo) put(alert models.BudgetAlert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts[fmt.Sprintf("%s:%s:%d", alert.BudgetID, alert.PeriodStart.Format(models.DateLayout), alert.Threshold)] = alert
+	return nil
+}
+
+func newTestAlerts(webhookURLs ...string) (*Alerts, *fakeAlertsRepo, models.Expense) {
+	expense := summaryExpense("2021-03-01T10:00:00Z", 900, "USD")
+	expense.OwnerID = "u1"
+	budget := models.Budget{
+		ID:      "b1",
+		OwnerID: "u1",
+		Amount:  models.Money{Amount: 100000, Currency: "USD"},
+		Period:  models.MonthlyBudgetPeriod,
+	}
+	repo := &fakeAlertsRepo{alerts: map[string]models.BudgetAlert{}}
+	alerts := &Alerts{
+		AlertsRepo: repo,
+		Budgets: Budgets{
+			BudgetsRepo: fakeBudgetsRepo{budgets: []models.Budget{budget}},
+			Expenses:    Expenses{ExpensesRepo: fakeSummaryRepo{expenses: []models.Expense{expense}}},
+		},
+		Webhooks: Webhooks{URLs: webhookURLs, MaxAttempts: 1},
+	}
+	return alerts, repo, expense
+}
+
+func TestEvaluateBudgetsInTheBackground(t *testing.T) {
+	alerts, repo, expense := newTestAlerts()
+	alerts.EvaluateBudgets(expense)
+	alerts.EvaluateBudgets(expense)
+	if fired, _ := repo.GetAlerts("u1", "b1"); len(fired) != 0 {
+		t.Fatalf("expected budgets not to be evaluated before the service starts, got: %+v", fired)
+	}
+
+	alerts.Start()
+	if err := alerts.Stop(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fired, _ := repo.GetAlerts("u1", "b1")
+	if len(fired) != 1 || fired[0].Threshold != 80 || fired[0].Status != models.SkippedAlertStatus {
+		t.Fatalf("expected the queued expenses to fire the 80%% alert once on stop, got: %+v", fired)
+	}
+}
+
+// countingSummaryRepo represents a summary repository that counts the summaries it was asked for
+type countingSummaryRepo struct {
+	fakeSummaryRepo
+	mu    sync.Mutex
+	calls int
+}
+
+func (r *countingSummaryRepo) Summarize(req models.SummaryRequest) ([]models.SummaryBucket, error) {
+	r.mu.Lock()
+	r.calls++
+	r.mu.Unlock()
+	return r.fakeSummaryRepo.Summarize(req)
+}
+
+func TestEvaluateBudgetsOncePerPeriod(t *testing.T) {
+	alerts, _, _ := newTestAlerts()
+	expenses := make([]models.Expense, 0)
+	for day := 1; day <= 28; day++ {
+		for _, month := range []string{"03", "04"} {
+			expense := summaryExpense(fmt.Sprintf("2021-%s-%02dT10:00:00Z", month, day), 100, "USD")
+			expense.OwnerID, expense.CategoryID = "u1", fmt.Sprintf("c%d", day%3)
+			expenses = append(expenses, expense)
+		}
+	}
+	repo := &countingSummaryRepo{fakeSummaryRepo: fakeSummaryRepo{expenses: expenses}}
+	alerts.Budgets.Expenses = Expenses{ExpensesRepo: repo}
+
+	alerts.Start()
+	alerts.EvaluateBudgets(expenses...)
+	if err := alerts.Stop(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.calls != 2 {
+		t.Fatalf("expected the budget to be evaluated once per month, got: %d evaluations", repo.calls)
+	}
+}
+
+func TestStopCancelsDeliveries(t *testing.T) {
+	received, release := make(chan struct{}), make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(received)
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	alerts, repo, expense := newTestAlerts(server.URL)
+	alerts.Start()
+	alerts.EvaluateBudgets(expense)
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the alert to be delivered")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := alerts.Stop(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded error, got: %v", err)
+	}
+	fired, _ := repo.GetAlerts("u1", "b1")
+	if len(fired) != 1 || fired[0].Status != models.FailedAlertStatus {
+		t.Fatalf("expected the cancelled delivery to fail, got: %+v", fired)
+	}
+}
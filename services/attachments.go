@@ -0,0 +1,186 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/repositories"
+)
+
+const (
+	sniffLength               = 512
+	maxFilenameLength         = 255
+	defaultAttachmentFilename = "attachment"
+)
+
+// Attachments represents the expense Attachments service, metadata goes into the repositories
+// while the content goes into the blob storage
+type Attachments struct {
+	AttachmentsRepo repositories.Attachments
+	ExpensesRepo    repositories.Expenses
+	Blobs           repositories.Blobs
+	MaxSize         int64
+}
+
+// GetAttachments fetches the metadata of all the attachments of an expense of a given user
+func (s Attachments) GetAttachments(userID, expenseID string) ([]models.Attachment, error) {
+	if err := s.checkExpense(userID, expenseID); err != nil {
+		return []models.Attachment{}, err
+	}
+	attachments, err := s.AttachmentsRepo.GetAttachments(userID, expenseID)
+	if err != nil {
+		logging.Logger.Error("could not fetch attachments from db", zap.Error(err))
+		return []models.Attachment{}, classifyError(err)
+	}
+	return attachments, nil
+}
+
+// GetAttachment fetches the metadata of an attachment along with its content, which the caller must close
+func (s Attachments) GetAttachment(userID, expenseID, id string) (models.Attachment, io.ReadCloser, error) {
+	attachment, err := s.AttachmentsRepo.GetAttachment(userID, expenseID, id)
+	if err != nil {
+		return models.Attachment{}, nil, classifyError(err)
+	}
+	content, err := s.Blobs.GetBlob(attachment.ID)
+	if err != nil {
+		logging.Logger.Error("could not open attachment content", zap.String("id", id), zap.Error(err))
+		return models.Attachment{}, nil, classifyError(err)
+	}
+	return attachment, content, nil
+}
+
+// CreateAttachment attaches a file to an expense, the content type is detected from the content itself
+func (s Attachments) CreateAttachment(req models.CreateAttachmentRequest) (models.Attachment, error) {
+	if err := s.checkExpense(req.UserID, req.ExpenseID); err != nil {
+		return models.Attachment{}, err
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(req.Content, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return models.Attachment{}, err
+	}
+	if n == 0 {
+		return models.Attachment{}, models.DataValidationError{Message: "attachment should not be empty"}
+	}
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if !models.IsAttachmentContentType(contentType) {
+		return models.Attachment{}, models.DataValidationError{
+			Message: fmt.Sprintf(
+				"unsupported attachment content type: %s, expected one of: %s",
+				contentType, strings.Join(models.AttachmentContentTypes, ","),
+			),
+		}
+	}
+
+	attachment := models.Attachment{
+		ID:          uuid.New().String(),
+		ExpenseID:   req.ExpenseID,
+		OwnerID:     req.UserID,
+		Filename:    attachmentFilename(req.Filename),
+		ContentType: contentType,
+		CreatedAt:   time.Now().UTC(),
+	}
+	hash, size := sha256.New(), new(byteCounter)
+	content := io.TeeReader(
+		io.LimitReader(io.MultiReader(bytes.NewReader(head[:n]), req.Content), s.MaxSize+1),
+		io.MultiWriter(hash, size),
+	)
+	if err = s.Blobs.PutBlob(attachment.ID, content); err != nil {
+		logging.Logger.Error("could not save attachment content", zap.Error(err))
+		return models.Attachment{}, classifyError(err)
+	}
+	if int64(*size) > s.MaxSize {
+		s.deleteBlob(attachment.ID)
+		return models.Attachment{}, models.DataValidationError{
+			Message: fmt.Sprintf("attachment must not be larger than %d bytes", s.MaxSize),
+		}
+	}
+	attachment.Size = int64(*size)
+	attachment.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	if err = s.AttachmentsRepo.CreateAttachment(attachment); err != nil {
+		logging.Logger.Error("could not create attachment in db", zap.Error(err))
+		s.deleteBlob(attachment.ID)
+		return models.Attachment{}, classifyError(err)
+	}
+	return attachment, nil
+}
+
+// DeleteAttachment deletes an attachment of an expense of a given user along with its content
+func (s Attachments) DeleteAttachment(userID, expenseID, id string) error {
+	if err := s.AttachmentsRepo.DeleteAttachment(userID, expenseID, id); err != nil {
+		return classifyError(err)
+	}
+	s.deleteBlob(id)
+	return nil
+}
+
+// DeleteExpenseAttachments deletes all the attachments of a deleted expense of a given user
+func (s Attachments) DeleteExpenseAttachments(userID, expenseID string) error {
+	attachments, err := s.AttachmentsRepo.GetAttachments(userID, expenseID)
+	if err != nil {
+		return classifyError(err)
+	}
+	for _, attachment := range attachments {
+		if err = s.DeleteAttachment(userID, expenseID, attachment.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkExpense makes sure that an expense exists for a given user
+func (s Attachments) checkExpense(userID, expenseID string) error {
+	expenses, err := s.ExpensesRepo.GetExpensesByIDs(userID, []string{expenseID})
+	if err != nil {
+		logging.Logger.Error("could not fetch expense from db", zap.Error(err))
+		return classifyError(err)
+	}
+	if len(expenses) == 0 {
+		return models.ResourceNotFoundError{
+			Message: fmt.Sprintf("could not find expense with id: %s", expenseID),
+		}
+	}
+	return nil
+}
+
+// deleteBlob deletes the content of an attachment, failures only leave an orphan blob behind so they are logged
+func (s Attachments) deleteBlob(id string) {
+	if err := s.Blobs.DeleteBlob(id); err != nil {
+		logging.Logger.Error("could not delete attachment content", zap.String("id", id), zap.Error(err))
+	}
+}
+
+// attachmentFilename strips the directories of an uploaded file name
+func attachmentFilename(filename string) string {
+	name := strings.TrimSpace(filepath.Base(strings.ReplaceAll(filename, `\`, "/")))
+	if name == "" || name == "." || name == "/" {
+		return defaultAttachmentFilename
+	}
+	if len(name) > maxFilenameLength {
+		return strings.ToValidUTF8(name[len(name)-maxFilenameLength:], "")
+	}
+	return name
+}
+
+// byteCounter counts the bytes written into it
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
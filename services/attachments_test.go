@@ -0,0 +1,190 @@
+package services
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/repositories"
+)
+
+const testPNG = "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+
+// fakeAttachmentsStore represents in memory expenses, attachments and blobs, expenses are mapped to their owners
+type fakeAttachmentsStore struct {
+	repositories.Expenses
+	owners      map[string]string
+	attachments map[string]models.Attachment
+	blobs       map[string][]byte
+}
+
+func newFakeAttachmentsStore(owners map[string]string) *fakeAttachmentsStore {
+	return &fakeAttachmentsStore{
+		owners:      owners,
+		attachments: map[string]models.Attachment{},
+		blobs:       map[string][]byte{},
+	}
+}
+
+func (s *fakeAttachmentsStore) GetExpensesByIDs(userID string, ids []string) ([]models.Expense, error) {
+	expenses := make([]models.Expense, 0)
+	for _, id := range ids {
+		if owner, ok := s.owners[id]; ok && owner == userID {
+			expenses = append(expenses, models.Expense{ID: id, OwnerID: owner})
+		}
+	}
+	return expenses, nil
+}
+
+func (s *fakeAttachmentsStore) GetAttachments(userID, expenseID string) ([]models.Attachment, error) {
+	attachments := make([]models.Attachment, 0)
+	for _, a := range s.attachments {
+		if a.OwnerID == userID && a.ExpenseID == expenseID {
+			attachments = append(attachments, a)
+		}
+	}
+	return attachments, nil
+}
+
+func (s *fakeAttachmentsStore) GetAttachment(userID, expenseID, id string) (models.Attachment, error) {
+	a, ok := s.attachments[id]
+	if !ok || a.OwnerID != userID || a.ExpenseID != expenseID {
+		return models.Attachment{}, models.ResourceNotFoundError{Message: "could not find attachment with id: " + id}
+	}
+	return a, nil
+}
+
+func (s *fakeAttachmentsStore) CreateAttachment(attachment models.Attachment) error {
+	s.attachments[attachment.ID] = attachment
+	return nil
+}
+
+func (s *fakeAttachmentsStore) DeleteAttachment(userID, expenseID, id string) error {
+	if _, err := s.GetAttachment(userID, expenseID, id); err != nil {
+		return err
+	}
+	delete(s.attachments, id)
+	return nil
+}
+
+func (s *fakeAttachmentsStore) PutBlob(id string, content io.Reader) error {
+	data, err := ioutil.ReadAll(content)
+	s.blobs[id] = data
+	return err
+}
+
+func (s *fakeAttachmentsStore) GetBlob(id string) (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewReader(s.blobs[id])), nil
+}
+
+func (s *fakeAttachmentsStore) DeleteBlob(id string) error {
+	delete(s.blobs, id)
+	return nil
+}
+
+func newTestAttachments(store *fakeAttachmentsStore) Attachments {
+	return Attachments{AttachmentsRepo: store, ExpensesRepo: store, Blobs: store, MaxSize: 1 << 20}
+}
+
+func TestCreateAttachmentDetectsTheContentType(t *testing.T) {
+	store := newFakeAttachmentsStore(map[string]string{"e1": "u1"})
+	attachment, err := newTestAttachments(store).CreateAttachment(models.CreateAttachmentRequest{
+		UserID:    "u1",
+		ExpenseID: "e1",
+		Filename:  "../receipt.pdf",
+		Content:   strings.NewReader(testPNG),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attachment.ContentType != "image/png" {
+		t.Errorf("got content type %s, want the one of the content: image/png", attachment.ContentType)
+	}
+	if attachment.Filename != "receipt.pdf" || attachment.Size != int64(len(testPNG)) {
+		t.Errorf("got filename %q and size %d", attachment.Filename, attachment.Size)
+	}
+	if got := string(store.blobs[attachment.ID]); got != testPNG {
+		t.Errorf("got content %q, want %q", got, testPNG)
+	}
+}
+
+func TestCreateAttachmentRejectsUnsupportedContentTypes(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "html", content: "<html><script>alert(1)</script></html>"},
+		{name: "plain text", content: "just a receipt"},
+		{name: "empty", content: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeAttachmentsStore(map[string]string{"e1": "u1"})
+			_, err := newTestAttachments(store).CreateAttachment(models.CreateAttachmentRequest{
+				UserID:    "u1",
+				ExpenseID: "e1",
+				Filename:  "receipt.png",
+				Content:   strings.NewReader(tt.content),
+			})
+			if _, ok := err.(models.DataValidationError); !ok {
+				t.Fatalf("got error %v, want a data validation error", err)
+			}
+			if len(store.attachments) != 0 || len(store.blobs) != 0 {
+				t.Fatalf("expected nothing to be stored, got %d attachments and %d blobs", len(store.attachments), len(store.blobs))
+			}
+		})
+	}
+}
+
+func TestAttachmentsOfAnotherUserAreNotFound(t *testing.T) {
+	store := newFakeAttachmentsStore(map[string]string{"e1": "alice"})
+	service := newTestAttachments(store)
+	attachment, err := service.CreateAttachment(models.CreateAttachmentRequest{
+		UserID:    "alice",
+		ExpenseID: "e1",
+		Content:   strings.NewReader(testPNG),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{name: "list", call: func() error {
+			_, err := service.GetAttachments("bob", "e1")
+			return err
+		}},
+		{name: "get", call: func() error {
+			_, _, err := service.GetAttachment("bob", "e1", attachment.ID)
+			return err
+		}},
+		{name: "create", call: func() error {
+			_, err := service.CreateAttachment(models.CreateAttachmentRequest{
+				UserID:    "bob",
+				ExpenseID: "e1",
+				Content:   strings.NewReader(testPNG),
+			})
+			return err
+		}},
+		{name: "delete", call: func() error {
+			return service.DeleteAttachment("bob", "e1", attachment.ID)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := tt.call().(models.ResourceNotFoundError); !ok {
+				t.Fatalf("expected a resource not found error")
+			}
+		})
+	}
+	if len(store.attachments) != 1 || len(store.blobs) != 1 {
+		t.Fatalf("expected only the attachment of alice, got %d attachments and %d blobs",
+			len(store.attachments), len(store.blobs))
+	}
+}
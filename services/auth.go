@@ -1 +1,236 @@
 package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/repositories"
+)
+
+const (
+	sessionTokenSize = 32
+	apiKeySize       = 32
+	apiKeyPrefix     = "exp_"
+	apiKeyPrefixSize = 12
+
+	// apiKeyTouchInterval throttles last usage updates of API keys that are used very often
+	apiKeyTouchInterval = time.Minute
+)
+
+// Auth represents the Authentication service
+type Auth struct {
+	UsersRepo   repositories.Users
+	SessionTTL  time.Duration
+	AdminEmails []string
+}
+
+// Signup registers a brand new user with a salted password hash
+func (s Auth) Signup(req models.SignupRequest) (models.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		logging.Logger.Error("could not hash user password", zap.Error(err))
+		return models.User{}, err
+	}
+
+	email, role := normalizeEmail(req.Email), models.MemberRole
+	for _, adminEmail := range s.AdminEmails {
+		if normalizeEmail(adminEmail) == email {
+			role = models.AdminRole
+		}
+	}
+	user, err := s.UsersRepo.CreateUser(email, string(hash), role)
+	if err != nil {
+		logging.Logger.Error("could not create user in db", zap.Error(err))
+		return models.User{}, classifyError(err)
+	}
+	return user, nil
+}
+
+// UpdateUserRole changes the role of a user, callers are not allowed to change their own role
+func (s Auth) UpdateUserRole(req models.UpdateUserRoleRequest) (models.User, error) {
+	if req.ID == req.UserID {
+		return models.User{}, models.ForbiddenError{Message: "users can not change their own role"}
+	}
+	user, err := s.UsersRepo.UpdateUserRole(req.ID, req.Role)
+	if err != nil {
+		logging.Logger.Error("could not update user role in db", zap.Error(err))
+		return models.User{}, classifyError(err)
+	}
+	return user, nil
+}
+
+// Login checks the user credentials and creates a new session, returning its token and expiry
+func (s Auth) Login(req models.LoginRequest) (string, time.Time, error) {
+	invalidCredentialsErr := models.UnauthorizedError{
+		Message: "invalid email or password",
+	}
+	user, err := s.UsersRepo.GetUserByEmail(normalizeEmail(req.Email))
+	if err != nil {
+		if _, ok := err.(models.ResourceNotFoundError); ok {
+			return "", time.Time{}, invalidCredentialsErr
+		}
+		logging.Logger.Error("could not fetch user from db", zap.Error(err))
+		return "", time.Time{}, classifyError(err)
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
+	if err != nil {
+		logging.Logger.Debug("invalid login attempt", zap.String("user_id", user.ID))
+		return "", time.Time{}, invalidCredentialsErr
+	}
+
+	token, err := generateToken(sessionTokenSize)
+	if err != nil {
+		logging.Logger.Error("could not generate session token", zap.Error(err))
+		return "", time.Time{}, err
+	}
+	expiresAt := time.Now().UTC().Add(s.SessionTTL)
+	err = s.UsersRepo.CreateSession(hashToken(token), user.ID, expiresAt)
+	if err != nil {
+		logging.Logger.Error("could not create session in db", zap.Error(err))
+		return "", time.Time{}, classifyError(err)
+	}
+	return token, expiresAt, nil
+}
+
+// Logout invalidates the session of a given session token, unknown tokens are unauthorized
+func (s Auth) Logout(token string) error {
+	err := s.UsersRepo.DeleteSession(hashToken(token))
+	if _, ok := err.(models.ResourceNotFoundError); ok {
+		return models.UnauthorizedError{Message: "invalid session token"}
+	}
+	if err != nil {
+		logging.Logger.Error("could not delete session from db", zap.Error(err))
+		return classifyError(err)
+	}
+	return nil
+}
+
+// Authenticate resolves the user that owns a given, non expired, session token
+func (s Auth) Authenticate(token string) (models.User, error) {
+	session, err := s.UsersRepo.GetSession(hashToken(token))
+	if err != nil {
+		if _, ok := err.(models.ResourceNotFoundError); ok {
+			return models.User{}, models.UnauthorizedError{Message: "invalid session token"}
+		}
+		logging.Logger.Error("could not fetch session from db", zap.Error(err))
+		return models.User{}, classifyError(err)
+	}
+	if time.Now().UTC().After(session.ExpiresAt) {
+		if err = s.UsersRepo.DeleteSession(session.ID); err != nil {
+			logging.Logger.Error("could not delete expired session from db", zap.Error(err))
+		}
+		return models.User{}, models.UnauthorizedError{Message: "session token has expired"}
+	}
+	return s.findCaller(session.UserID)
+}
+
+// AuthenticateAPIKey resolves the user that owns a given, non expired, API key along with the key scope
+func (s Auth) AuthenticateAPIKey(key string) (models.User, string, error) {
+	apiKey, err := s.UsersRepo.GetAPIKeyByHash(hashToken(key))
+	if err != nil {
+		if _, ok := err.(models.ResourceNotFoundError); ok {
+			return models.User{}, "", models.UnauthorizedError{Message: "invalid api key"}
+		}
+		logging.Logger.Error("could not fetch api key from db", zap.Error(err))
+		return models.User{}, "", classifyError(err)
+	}
+	if apiKey.Expired() {
+		return models.User{}, "", models.UnauthorizedError{Message: "api key has expired"}
+	}
+
+	now := time.Now().UTC()
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > apiKeyTouchInterval {
+		if err = s.UsersRepo.TouchAPIKey(apiKey.ID, now); err != nil {
+			logging.Logger.Error("could not update api key last usage", zap.Error(err))
+		}
+	}
+	user, err := s.findCaller(apiKey.UserID)
+	if err != nil {
+		return models.User{}, "", err
+	}
+	return user, apiKey.Scope, nil
+}
+
+// CreateAPIKey creates a new personal API key and returns it along with the full key, which is never shown again
+func (s Auth) CreateAPIKey(req models.CreateAPIKeyRequest) (models.APIKey, string, error) {
+	token, err := generateToken(apiKeySize)
+	if err != nil {
+		logging.Logger.Error("could not generate api key", zap.Error(err))
+		return models.APIKey{}, "", err
+	}
+	key := apiKeyPrefix + token
+	scope := req.Scope
+	if scope == "" {
+		scope = models.ReadOnlyScope
+	}
+
+	apiKey, err := s.UsersRepo.CreateAPIKey(models.APIKey{
+		UserID:    req.UserID,
+		Name:      strings.TrimSpace(req.Name),
+		Prefix:    key[:apiKeyPrefixSize],
+		Hash:      hashToken(key),
+		Scope:     scope,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		logging.Logger.Error("could not create api key in db", zap.Error(err))
+		return models.APIKey{}, "", classifyError(err)
+	}
+	return apiKey, key, nil
+}
+
+// GetAPIKeys fetches all the API keys of a given user
+func (s Auth) GetAPIKeys(userID string) ([]models.APIKey, error) {
+	keys, err := s.UsersRepo.GetAPIKeys(userID)
+	if err != nil {
+		logging.Logger.Error("could not fetch api keys from db", zap.Error(err))
+		return []models.APIKey{}, classifyError(err)
+	}
+	return keys, nil
+}
+
+// DeleteAPIKey revokes an API key of a given user by a given ID
+func (s Auth) DeleteAPIKey(userID, id string) error {
+	return classifyError(s.UsersRepo.DeleteAPIKey(userID, id))
+}
+
+// findCaller fetches the user behind a set of valid credentials
+func (s Auth) findCaller(userID string) (models.User, error) {
+	user, err := s.UsersRepo.GetUserByID(userID)
+	if err != nil {
+		if _, ok := err.(models.ResourceNotFoundError); ok {
+			return models.User{}, models.UnauthorizedError{Message: "user no longer exists"}
+		}
+		logging.Logger.Error("could not fetch user from db", zap.Error(err))
+		return models.User{}, classifyError(err)
+	}
+	return user, nil
+}
+
+// generateToken generates a random URL safe token out of a given amount of random bytes
+func generateToken(size int) (string, error) {
+	bs := make([]byte, size)
+	if _, err := rand.Read(bs); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bs), nil
+}
+
+// hashToken hashes tokens before they get stored, so that a leaked db does not leak valid tokens
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
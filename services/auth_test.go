@@ -0,0 +1,155 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/repositories"
+	"github.com/steevehook/expenses-rest-api/transport"
+)
+
+// fakeUsersRepo represents a users repository that keeps users and sessions in memory, emails are unique
+type fakeUsersRepo struct {
+	repositories.Users
+	mu       sync.Mutex
+	users    map[string]models.User
+	sessions map[string]models.Session
+}
+
+func newFakeUsersRepo() *fakeUsersRepo {
+	return &fakeUsersRepo{users: map[string]models.User{}, sessions: map[string]models.Session{}}
+}
+
+func (r *fakeUsersRepo) CreateUser(email, passwordHash, role string) (models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.Email == email {
+			return models.User{}, models.ConflictError{Message: fmt.Sprintf("user with email: %s already exists", email)}
+		}
+	}
+	user := models.User{ID: uuid.New().String(), Email: email, PasswordHash: passwordHash, Role: role}
+	r.users[user.ID] = user
+	return user, nil
+}
+
+func (r *fakeUsersRepo) GetUserByID(id string) (models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return models.User{}, models.ResourceNotFoundError{Message: "no user"}
+	}
+	return user, nil
+}
+
+func (r *fakeUsersRepo) GetUserByEmail(email string) (models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return models.User{}, models.ResourceNotFoundError{Message: "no user"}
+}
+
+func (r *fakeUsersRepo) CreateSession(id, userID string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[id] = models.Session{ID: id, UserID: userID, ExpiresAt: expiresAt}
+	return nil
+}
+
+func (r *fakeUsersRepo) GetSession(id string) (models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok {
+		return models.Session{}, models.ResourceNotFoundError{Message: "no session"}
+	}
+	return session, nil
+}
+
+func (r *fakeUsersRepo) DeleteSession(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sessions[id]; !ok {
+		return models.ResourceNotFoundError{Message: "no session"}
+	}
+	delete(r.sessions, id)
+	return nil
+}
+
+func mustSignup(t *testing.T, s Auth, email string) models.User {
+	t.Helper()
+	user, err := s.Signup(models.SignupRequest{Email: email, Password: "secret123"})
+	if err != nil {
+		t.Fatalf("could not sign up: %v", err)
+	}
+	return user
+}
+
+func TestLoginWithWrongPassword(t *testing.T) {
+	s := Auth{UsersRepo: newFakeUsersRepo(), SessionTTL: time.Hour}
+	mustSignup(t, s, "jane@example.com")
+
+	_, _, err := s.Login(models.LoginRequest{Email: "jane@example.com", Password: "wrong-password"})
+	if _, ok := err.(models.UnauthorizedError); !ok {
+		t.Fatalf("expected an unauthorized error, got: %v", err)
+	}
+	_, _, err = s.Login(models.LoginRequest{Email: "john@example.com", Password: "secret123"})
+	if _, ok := err.(models.UnauthorizedError); !ok {
+		t.Fatalf("expected an unauthorized error for an unknown email, got: %v", err)
+	}
+}
+
+func TestSignupWithDuplicateEmail(t *testing.T) {
+	s := Auth{UsersRepo: newFakeUsersRepo(), SessionTTL: time.Hour}
+	mustSignup(t, s, "jane@example.com")
+
+	_, err := s.Signup(models.SignupRequest{Email: " Jane@Example.com ", Password: "secret123"})
+	if _, ok := err.(models.ConflictError); !ok {
+		t.Fatalf("expected a conflict error, got: %v", err)
+	}
+}
+
+func TestLogoutInvalidatesTheSession(t *testing.T) {
+	s := Auth{UsersRepo: newFakeUsersRepo(), SessionTTL: time.Hour}
+	user := mustSignup(t, s, "jane@example.com")
+	token, _, err := s.Login(models.LoginRequest{Email: "jane@example.com", Password: "secret123"})
+	if err != nil {
+		t.Fatalf("could not log in: %v", err)
+	}
+	caller, err := s.Authenticate(token)
+	if err != nil || caller.ID != user.ID {
+		t.Fatalf("expected the token to authenticate the user, got: %+v, %v", caller, err)
+	}
+
+	if err = s.Logout(token); err != nil {
+		t.Fatalf("could not log out: %v", err)
+	}
+	if _, err = s.Authenticate(token); transport.ToHTTPError(err).Code != http.StatusUnauthorized {
+		t.Fatalf("expected the token to stop authenticating after logout, got: %v", err)
+	}
+}
+
+func TestAuthenticateExpiredSession(t *testing.T) {
+	s := Auth{UsersRepo: newFakeUsersRepo(), SessionTTL: -time.Minute}
+	mustSignup(t, s, "jane@example.com")
+	token, _, err := s.Login(models.LoginRequest{Email: "jane@example.com", Password: "secret123"})
+	if err != nil {
+		t.Fatalf("could not log in: %v", err)
+	}
+
+	_, err = s.Authenticate(token)
+	if code := transport.ToHTTPError(err).Code; code != http.StatusUnauthorized {
+		t.Fatalf("expected status: %d for an expired session, got: %d (%v)", http.StatusUnauthorized, code, err)
+	}
+}
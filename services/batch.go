@@ -0,0 +1,100 @@
+package services
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+const defaultMaxBatchSize = 100
+
+// BatchExpenses applies the create, update and delete operations of a batch in a single transaction.
+// Every operation gets validated first, an all or nothing batch applies nothing when any of its operations fails,
+// while a best effort batch applies every operation that does not fail
+func (s Expenses) BatchExpenses(req models.BatchExpensesRequest) (models.BatchReport, error) {
+	maxSize := s.maxBatchSize()
+	if len(req.Operations) > maxSize {
+		return models.BatchReport{}, models.DataValidationError{
+			Message: fmt.Sprintf("a batch may have at most %d operations", maxSize),
+		}
+	}
+
+	report := models.BatchReport{
+		BestEffort: req.BestEffort,
+		Results:    make([]models.BatchResult, len(req.Operations)),
+	}
+	indexes := make([]int, 0, len(req.Operations))
+	ops := make([]models.BatchOperation, 0, len(req.Operations))
+	categories := map[string]error{}
+	for i, op := range req.Operations {
+		report.Results[i] = models.BatchResult{Op: op.Op, ID: op.ID}
+		err := op.Validate()
+		if err == nil && op.Expense != nil {
+			op.Expense.Tags = models.NormalizeTags(op.Expense.Tags)
+			var categoryID string
+			if op.Expense.CategoryID != nil {
+				categoryID = *op.Expense.CategoryID
+			}
+			if _, ok := categories[categoryID]; !ok {
+				categories[categoryID] = s.checkCategory(req.UserID, categoryID)
+			}
+			err = categories[categoryID]
+		}
+		if _, ok := err.(models.DataValidationError); err != nil && !ok {
+			return models.BatchReport{}, err
+		}
+		if err != nil {
+			report.Results[i].Err = err
+			continue
+		}
+		indexes = append(indexes, i)
+		ops = append(ops, op)
+	}
+	if !req.BestEffort && len(ops) < len(req.Operations) {
+		return report, nil
+	}
+
+	results, err := s.ExpensesRepo.ApplyExpensesBatch(req.UserID, ops, req.BestEffort)
+	if err != nil {
+		logging.Logger.Error("could not apply expenses batch in db", zap.Error(err))
+		return models.BatchReport{}, classifyError(err)
+	}
+	report.Committed = true
+	for _, result := range results {
+		if result.Err != nil && !req.BestEffort {
+			report.Committed = false
+		}
+	}
+	for j, result := range results {
+		if report.Committed {
+			report.Results[indexes[j]] = result
+		} else {
+			// expenses of rolled back batches do not exist, so only the reason of the failed operation is kept
+			report.Results[indexes[j]].Err = result.Err
+		}
+	}
+	if !report.Committed {
+		return report, nil
+	}
+
+	for _, result := range report.Results {
+		switch {
+		case result.Err != nil:
+		case result.Op == models.DeleteBatchOperation:
+			s.deleteAttachments(req.UserID, result.ID)
+		case result.Expense != nil:
+			s.evaluateBudgets(*result.Expense)
+		}
+	}
+	return report, nil
+}
+
+func (s Expenses) maxBatchSize() int {
+	if s.MaxBatchSize <= 0 {
+		return defaultMaxBatchSize
+	}
+	return s.MaxBatchSize
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/repositories"
+)
+
+// Budgets represents the Budgets service
+type Budgets struct {
+	BudgetsRepo repositories.Budgets
+	Expenses    Expenses
+}
+
+// GetBudgets fetches all the budgets of a given user
+func (s Budgets) GetBudgets(userID string) ([]models.Budget, error) {
+	budgets, err := s.BudgetsRepo.GetBudgets(userID)
+	if err != nil {
+		logging.Logger.Error("could not fetch budgets from db", zap.Error(err))
+		return []models.Budget{}, classifyError(err)
+	}
+	return budgets, nil
+}
+
+// GetBudget fetches a budget of a given user by a given ID
+func (s Budgets) GetBudget(userID, id string) (models.Budget, error) {
+	budget, err := s.BudgetsRepo.GetBudget(userID, id)
+	return budget, classifyError(err)
+}
+
+// CreateBudget creates a brand new budget
+func (s Budgets) CreateBudget(req models.CreateBudgetRequest) (models.Budget, error) {
+	if err := s.Expenses.checkCategory(req.UserID, req.CategoryID); err != nil {
+		return models.Budget{}, err
+	}
+	if req.Period == "" {
+		req.Period = models.MonthlyBudgetPeriod
+	}
+	budget, err := s.BudgetsRepo.CreateBudget(req)
+	if err != nil {
+		logging.Logger.Error("could not create budget in db", zap.Error(err))
+		return models.Budget{}, classifyError(err)
+	}
+	return budget, nil
+}
+
+// UpdateBudget updates an existing budget and returns its updated version
+func (s Budgets) UpdateBudget(req models.UpdateBudgetRequest) (models.Budget, error) {
+	if req.CategoryID != nil {
+		if err := s.Expenses.checkCategory(req.UserID, *req.CategoryID); err != nil {
+			return models.Budget{}, err
+		}
+	}
+	budget, err := s.BudgetsRepo.UpdateBudget(req)
+	if err != nil {
+		logging.Logger.Error("could not update budget in db", zap.Error(err))
+		return models.Budget{}, classifyError(err)
+	}
+	return budget, nil
+}
+
+// DeleteBudget deletes a budget of a given user by a given ID
+func (s Budgets) DeleteBudget(userID, id string) error {
+	return classifyError(s.BudgetsRepo.DeleteBudget(userID, id))
+}
+
+// GetBudgetStatus calculates how much of a budget was spent within the period of a given day,
+// expenses of other currencies get converted into the budget currency
+func (s Budgets) GetBudgetStatus(req models.BudgetStatusRequest) (models.BudgetStatus, error) {
+	budget, err := s.GetBudget(req.UserID, req.ID)
+	if err != nil {
+		return models.BudgetStatus{}, err
+	}
+	if req.At.IsZero() {
+		req.At = time.Now()
+	}
+	start, end := models.BudgetPeriodRange(budget.Period, req.At)
+
+	buckets, err := s.Expenses.Summarize(models.SummaryRequest{
+		UserID:    req.UserID,
+		GroupBy:   models.CategorySummaryGroup,
+		From:      start,
+		To:        end,
+		ConvertTo: budget.Amount.Currency,
+	})
+	if err != nil {
+		return models.BudgetStatus{}, err
+	}
+	spent := models.Money{Currency: budget.Amount.Currency}
+	for _, bucket := range buckets {
+		if budget.CategoryID == "" || bucket.Key == budget.CategoryID {
+			spent.Amount += bucket.Total.Amount
+		}
+	}
+	return models.NewBudgetStatus(budget, start, end, spent), nil
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/repositories"
+)
+
+// Categories represents the expense Categories service
+type Categories struct {
+	CategoriesRepo repositories.Categories
+}
+
+// GetCategories fetches all the categories of a given user
+func (s Categories) GetCategories(userID string) ([]models.Category, error) {
+	categories, err := s.CategoriesRepo.GetCategories(userID)
+	if err != nil {
+		logging.Logger.Error("could not fetch categories from db", zap.Error(err))
+		return []models.Category{}, classifyError(err)
+	}
+	return categories, nil
+}
+
+// GetCategory fetches a category of a given user by a given ID
+func (s Categories) GetCategory(userID, id string) (models.Category, error) {
+	category, err := s.CategoriesRepo.GetCategory(userID, id)
+	return category, classifyError(err)
+}
+
+// CreateCategory creates a brand new category
+func (s Categories) CreateCategory(req models.CategoryRequest) (models.Category, error) {
+	category, err := s.CategoriesRepo.CreateCategory(req.UserID, strings.TrimSpace(req.Name))
+	if err != nil {
+		logging.Logger.Error("could not create category in db", zap.Error(err))
+		return models.Category{}, classifyError(err)
+	}
+	return category, nil
+}
+
+// UpdateCategory renames an existing category
+func (s Categories) UpdateCategory(req models.CategoryRequest) (models.Category, error) {
+	category, err := s.CategoriesRepo.UpdateCategory(req.UserID, req.ID, strings.TrimSpace(req.Name))
+	if err != nil {
+		logging.Logger.Error("could not update category in db", zap.Error(err))
+		return models.Category{}, classifyError(err)
+	}
+	return category, nil
+}
+
+// DeleteCategory deletes a category, optionally moving its expenses to uncategorized
+func (s Categories) DeleteCategory(req models.DeleteCategoryRequest) error {
+	return classifyError(s.CategoriesRepo.DeleteCategory(req))
+}
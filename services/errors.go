@@ -0,0 +1,29 @@
+package services
+
+import (
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/repositories"
+)
+
+// classifyError turns storage errors into typed errors, so that each kind gets its own HTTP mapping.
+// Errors that are already typed pass through untouched, while unknown errors stay as they are
+func classifyError(err error) error {
+	switch err.(type) {
+	case nil,
+		models.ResourceNotFoundError,
+		models.DataValidationError,
+		models.ConflictError,
+		models.StorageUnavailableError,
+		models.UnauthorizedError,
+		models.ForbiddenError:
+		return err
+	}
+
+	switch {
+	case repositories.IsUnavailable(err):
+		return models.StorageUnavailableError{Message: "storage is temporarily unavailable, please try again later"}
+	case repositories.IsConflict(err):
+		return models.ConflictError{Message: "resource conflicts with the current state of the storage"}
+	}
+	return err
+}
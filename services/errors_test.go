@@ -0,0 +1,82 @@
+package services
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "nil",
+			err:  nil,
+			want: nil,
+		},
+		{
+			name: "not found passes through",
+			err:  models.ResourceNotFoundError{Message: "not found"},
+			want: models.ResourceNotFoundError{Message: "not found"},
+		},
+		{
+			name: "conflict passes through",
+			err:  models.ConflictError{Message: "already exists"},
+			want: models.ConflictError{Message: "already exists"},
+		},
+		{
+			name: "bad connection",
+			err:  fmt.Errorf("select: %w", driver.ErrBadConn),
+			want: models.StorageUnavailableError{},
+		},
+		{
+			name: "bolt timeout",
+			err:  bolt.ErrTimeout,
+			want: models.StorageUnavailableError{},
+		},
+		{
+			name: "mysql deadlock",
+			err:  &mysql.MySQLError{Number: 1213, Message: "deadlock found"},
+			want: models.StorageUnavailableError{},
+		},
+		{
+			name: "mysql duplicate entry",
+			err:  &mysql.MySQLError{Number: 1062, Message: "duplicate entry"},
+			want: models.ConflictError{},
+		},
+		{
+			name: "unknown",
+			err:  errors.New("disk is on fire"),
+			want: errors.New("disk is on fire"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := classifyError(test.err)
+			switch want := test.want.(type) {
+			case nil:
+				if got != nil {
+					t.Fatalf("expected nil error, got: %v", got)
+				}
+			case models.StorageUnavailableError, models.ConflictError:
+				if fmt.Sprintf("%T", got) != fmt.Sprintf("%T", want) {
+					t.Fatalf("expected error of type %T, got: %T", want, got)
+				}
+			default:
+				if got == nil || got.Error() != want.Error() {
+					t.Fatalf("expected error: %v, got: %v", want, got)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package services
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"go.uber.org/zap"
 
 	"github.com/steevehook/expenses-rest-api/logging"
@@ -10,53 +14,318 @@ import (
 
 // Expenses represents the Expenses service
 type Expenses struct {
-	ExpensesRepo repositories.Expenses
+	ExpensesRepo   repositories.Expenses
+	CategoriesRepo repositories.Categories
+	RatesRepo      repositories.Rates
+	// BudgetAlerts re-evaluates the budgets of created and updated expenses, when set
+	BudgetAlerts budgetsEvaluator
+	// Attachments deletes the attachments of deleted expenses, when set
+	Attachments attachmentsDeleter
+	// MaxBatchSize limits the amount of operations of a single batch and of expenses deleted at once,
+	// defaultMaxBatchSize is used when it is not set
+	MaxBatchSize int
+}
+
+type budgetsEvaluator interface {
+	EvaluateBudgets(expenses ...models.Expense)
+}
+
+type attachmentsDeleter interface {
+	DeleteExpenseAttachments(userID, expenseID string) error
 }
 
 // GetAllExpenses fetches all expenses with pagination possibilities
 func (s Expenses) GetAllExpenses(req models.GetAllExpensesRequest) ([]models.Expense, error) {
-	expenses, err := s.ExpensesRepo.GetAllExpenses(req.Page, req.PageSize)
+	expenses, err := s.ExpensesRepo.GetAllExpenses(repoExpensesRequest(req))
 	if err != nil {
 		logging.Logger.Error("could not fetch all expenses from db", zap.Error(err))
-		return []models.Expense{}, err
+		return []models.Expense{}, classifyError(err)
 	}
-	return expenses, nil
+	return s.convertExpenses(expenses, req.ConvertTo)
+}
+
+// ExportExpenses passes every expense matched by the filters of a request to a given function, ignoring pagination.
+// Expenses are streamed from the repository one by one, so that large exports are never held in memory
+func (s Expenses) ExportExpenses(req models.GetAllExpensesRequest, fn func(models.Expense) error) error {
+	ratesByDate := map[string]models.DailyRates{}
+	err := s.ExpensesRepo.StreamExpenses(repoExpensesRequest(req), func(expense models.Expense) error {
+		if req.ConvertTo != "" {
+			converted, err := s.convertPrice(ratesByDate, expense, req.ConvertTo)
+			if err != nil {
+				return err
+			}
+			expense.Converted = &converted
+		}
+		return fn(expense)
+	})
+	if err != nil {
+		logging.Logger.Error("could not export expenses", zap.Error(err))
+		return classifyError(err)
+	}
+	return nil
 }
 
 // GetExpensesByIDs fetches expenses by a list of given IDs
 func (s Expenses) GetExpensesByIDs(req models.GetExpensesByIDsRequest) ([]models.Expense, error) {
-	expenses, err := s.ExpensesRepo.GetExpensesByIDs(req.IDs)
+	expenses, err := s.ExpensesRepo.GetExpensesByIDs(ownerID(req.UserID, req.AllUsers), req.IDs)
 	if err != nil {
 		logging.Logger.Error("could not fetch expenses by ids from db", zap.Error(err))
-		return []models.Expense{}, err
+		return []models.Expense{}, classifyError(err)
 	}
-	return expenses, nil
+	return s.convertExpenses(expenses, req.ConvertTo)
 }
 
-// CreateExpense creates a brand new expense
-func (s Expenses) CreateExpense(req models.CreateExpenseRequest) error {
-	err := s.ExpensesRepo.CreateExpense(req.Title, req.Currency, req.Price)
+// CreateExpense creates a brand new expense and returns it
+func (s Expenses) CreateExpense(req models.CreateExpenseRequest) (models.Expense, error) {
+	if err := s.checkCategory(req.UserID, req.CategoryID); err != nil {
+		return models.Expense{}, err
+	}
+	req.Tags = models.NormalizeTags(req.Tags)
+	expense, err := s.ExpensesRepo.CreateExpense(req)
 	if err != nil {
 		logging.Logger.Error("could not create expense in db", zap.Error(err))
+		return models.Expense{}, classifyError(err)
 	}
-	return nil
+	s.evaluateBudgets(expense)
+	return expense, nil
 }
 
-// UpdateExpense updates an existing created expense
-func (s Expenses) UpdateExpense(req models.UpdateExpenseRequest) error {
-	err := s.ExpensesRepo.UpdateExpense(req.ID, req.Title, req.Currency, req.Price)
+// UpdateExpense updates an existing created expense and returns its updated version
+func (s Expenses) UpdateExpense(req models.UpdateExpenseRequest) (models.Expense, error) {
+	if req.CategoryID != nil {
+		if err := s.checkCategory(req.UserID, *req.CategoryID); err != nil {
+			return models.Expense{}, err
+		}
+	}
+	req.Tags = models.NormalizeTags(req.Tags)
+	expense, err := s.ExpensesRepo.UpdateExpense(req)
 	if err != nil {
 		logging.Logger.Error("could not update expense in db", zap.Error(err))
+		return models.Expense{}, classifyError(err)
 	}
+	s.evaluateBudgets(expense)
+	return expense, nil
+}
+
+// DeleteExpense deletes an expense of a given user by a given ID along with its attachments
+func (s Expenses) DeleteExpense(userID, id string) error {
+	if err := s.ExpensesRepo.DeleteExpense(userID, id); err != nil {
+		return classifyError(err)
+	}
+	s.deleteAttachments(userID, id)
 	return nil
 }
 
-// DeleteExpense deletes a list of expenses by a given list of IDs
-func (s Expenses) DeleteExpense(id string) error {
-	return s.ExpensesRepo.DeleteExpense(id)
+// DeleteExpenses deletes a list of expenses of a given user in a single transaction along with their attachments.
+// Expenses that do not exist are reported as not found instead of failing the deletion of the rest
+func (s Expenses) DeleteExpenses(userID string, ids []string) (models.DeleteExpensesReport, error) {
+	maxSize := s.maxBatchSize()
+	if len(ids) > maxSize {
+		return models.DeleteExpensesReport{}, models.DataValidationError{
+			Message: fmt.Sprintf("at most %d expenses may be deleted at once", maxSize),
+		}
+	}
+
+	ops := make([]models.BatchOperation, 0, len(ids))
+	for _, id := range ids {
+		ops = append(ops, models.BatchOperation{Op: models.DeleteBatchOperation, ID: id})
+	}
+	results, err := s.ExpensesRepo.ApplyExpensesBatch(userID, ops, true)
+	if err != nil {
+		logging.Logger.Error("could not delete expenses from db", zap.Error(err))
+		return models.DeleteExpensesReport{}, classifyError(err)
+	}
+
+	report := models.DeleteExpensesReport{Deleted: []string{}, NotFound: []string{}}
+	for _, result := range results {
+		if result.Err != nil {
+			report.NotFound = append(report.NotFound, result.ID)
+			continue
+		}
+		report.Deleted = append(report.Deleted, result.ID)
+		s.deleteAttachments(userID, result.ID)
+	}
+	return report, nil
+}
+
+// ExpensesCount fetches the total count of expenses matched by a fetch all expenses request
+func (s Expenses) ExpensesCount(req models.GetAllExpensesRequest) (int, error) {
+	count, err := s.ExpensesRepo.Count(repoExpensesRequest(req))
+	if err != nil {
+		logging.Logger.Error("could not count expenses in db", zap.Error(err))
+		return 0, classifyError(err)
+	}
+	return count, nil
+}
+
+// GetTags fetches the tags of a given user along with their usage count
+func (s Expenses) GetTags(userID string) ([]models.TagUsage, error) {
+	tags, err := s.ExpensesRepo.GetTags(userID)
+	if err != nil {
+		logging.Logger.Error("could not fetch tags from db", zap.Error(err))
+		return []models.TagUsage{}, classifyError(err)
+	}
+	return tags, nil
+}
+
+// Summarize aggregates the expenses created within a period into buckets, amounts of different currencies
+// are only merged into one bucket when a conversion target is given
+func (s Expenses) Summarize(req models.SummaryRequest) ([]models.SummaryBucket, error) {
+	req.UserID = ownerID(req.UserID, req.AllUsers)
+	buckets, err := s.ExpensesRepo.Summarize(req)
+	if err != nil {
+		logging.Logger.Error("could not summarize expenses in db", zap.Error(err))
+		return []models.SummaryBucket{}, classifyError(err)
+	}
+	if req.ConvertTo != "" {
+		if buckets, err = s.convertBuckets(buckets, req.ConvertTo); err != nil {
+			return []models.SummaryBucket{}, err
+		}
+	}
+
+	for i := range buckets {
+		buckets[i].SetAverage()
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].Key != buckets[j].Key {
+			return buckets[i].Key < buckets[j].Key
+		}
+		return buckets[i].Currency < buckets[j].Currency
+	})
+	return buckets, nil
+}
+
+// convertBuckets converts daily summary buckets with the exchange rates of their day and merges them by key
+func (s Expenses) convertBuckets(buckets []models.SummaryBucket, to string) ([]models.SummaryBucket, error) {
+	ratesByDate := map[string]models.DailyRates{}
+	merged := map[string]*models.SummaryBucket{}
+	keys := make([]string, 0)
+	for _, bucket := range buckets {
+		if bucket.Currency != to {
+			rates, err := s.ratesOn(ratesByDate, bucket.Date)
+			if err != nil {
+				return nil, err
+			}
+			for _, m := range []*models.Money{&bucket.Total, &bucket.Min, &bucket.Max} {
+				if *m, err = rates.Convert(*m, to); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if _, ok := merged[bucket.Key]; !ok {
+			merged[bucket.Key] = &models.SummaryBucket{Key: bucket.Key, Currency: to}
+			keys = append(keys, bucket.Key)
+		}
+		merged[bucket.Key].Merge(bucket)
+	}
+
+	res := make([]models.SummaryBucket, 0, len(keys))
+	for _, key := range keys {
+		res = append(res, *merged[key])
+	}
+	return res, nil
+}
+
+func (s Expenses) evaluateBudgets(expenses ...models.Expense) {
+	if s.BudgetAlerts != nil && len(expenses) > 0 {
+		s.BudgetAlerts.EvaluateBudgets(expenses...)
+	}
+}
+
+func (s Expenses) deleteAttachments(userID, expenseID string) {
+	if s.Attachments == nil {
+		return
+	}
+	if err := s.Attachments.DeleteExpenseAttachments(userID, expenseID); err != nil {
+		logging.Logger.Error("could not delete expense attachments", zap.String("id", expenseID), zap.Error(err))
+	}
+}
+
+// checkCategory makes sure that an optional expense category exists for a given user
+func (s Expenses) checkCategory(userID, categoryID string) error {
+	if categoryID == "" {
+		return nil
+	}
+	_, err := s.CategoriesRepo.GetCategory(userID, categoryID)
+	if _, ok := err.(models.ResourceNotFoundError); ok {
+		return models.DataValidationError{
+			Message: fmt.Sprintf("category with id: %s does not exist", categoryID),
+		}
+	}
+	return classifyError(err)
 }
 
-// ExpensesCount fetches the total count of created expenses
-func (s Expenses) ExpensesCount() (int, error) {
-	return s.ExpensesRepo.Count()
+// convertExpenses adds the price converted into a given currency to every expense,
+// using the latest exchange rates on or before the day the expense was created
+func (s Expenses) convertExpenses(expenses []models.Expense, to string) ([]models.Expense, error) {
+	if to == "" {
+		return expenses, nil
+	}
+	ratesByDate := map[string]models.DailyRates{}
+	for i, expense := range expenses {
+		converted, err := s.convertPrice(ratesByDate, expense, to)
+		if err != nil {
+			return []models.Expense{}, err
+		}
+		expenses[i].Converted = &converted
+	}
+	return expenses, nil
+}
+
+// convertPrice converts the price of an expense into a given currency, using the rates of the day it was created
+func (s Expenses) convertPrice(ratesByDate map[string]models.DailyRates, expense models.Expense, to string) (models.Money, error) {
+	if expense.Price.Currency == to {
+		return expense.Price, nil
+	}
+	rates, err := s.ratesOn(ratesByDate, expense.CreatedAt.UTC().Format(models.DateLayout))
+	if err != nil {
+		return models.Money{}, err
+	}
+	return rates.Convert(expense.Price, to)
+}
+
+// ratesOn fetches the latest exchange rates on or before a given date, caching them by date
+func (s Expenses) ratesOn(ratesByDate map[string]models.DailyRates, date string) (models.DailyRates, error) {
+	if rates, ok := ratesByDate[date]; ok {
+		return rates, nil
+	}
+	rates, err := s.RatesRepo.GetRatesOn(date)
+	if _, notFound := err.(models.ResourceNotFoundError); notFound {
+		return models.DailyRates{}, models.DataValidationError{
+			Message: fmt.Sprintf("no exchange rates on or before: %s", date),
+		}
+	}
+	if err != nil {
+		logging.Logger.Error("could not fetch rates from db", zap.Error(err))
+		return models.DailyRates{}, classifyError(err)
+	}
+	ratesByDate[date] = rates
+	return rates, nil
+}
+
+// ownerID returns the owner user ID the repository reads get scoped to, an empty ID matches every user
+func ownerID(userID string, allUsers bool) string {
+	if allUsers {
+		return ""
+	}
+	return userID
+}
+
+// repoExpensesRequest prepares a fetch all expenses request for the repository layer
+func repoExpensesRequest(req models.GetAllExpensesRequest) models.GetAllExpensesRequest {
+	req.UserID = ownerID(req.UserID, req.AllUsers)
+	req.Tags = models.NormalizeTags(req.Tags)
+	if req.TagMatch == "" {
+		req.TagMatch = models.AnyTagMatch
+	}
+	currencies := make([]string, 0, len(req.Currencies))
+	for _, currency := range req.Currencies {
+		currencies = append(currencies, strings.ToUpper(strings.TrimSpace(currency)))
+	}
+	req.Currencies = currencies
+	req.Query = strings.TrimSpace(req.Query)
+	if len(req.Sort) == 0 {
+		req.Sort = models.DefaultSort
+	}
+	return req
 }
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+const (
+	// maxImportRows limits the amount of rows of a single import, so that every import fits in one transaction
+	maxImportRows = 10000
+	utf8BOM       = "\uFEFF"
+)
+
+// importedRow represents a parsed row of an imported file, rows that could not be parsed carry the reason.
+// Rows that are left out without failing the import, such as already imported transactions, carry a skip reason
+type importedRow struct {
+	line int
+	req  models.CreateExpenseRequest
+	err  error
+	skip string
+}
+
+// ImportExpenses creates the expenses of the rows of a CSV file with a header, all of them in a single batch.
+// Rows get validated one by one, in reject mode nothing is created when any of the rows fails
+func (s Expenses) ImportExpenses(req models.ImportExpensesRequest) (models.ImportReport, error) {
+	rows, err := parseExpensesCSV(req.Content, req.Mapping, strings.ToUpper(req.Currency))
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+	return s.importRows(req.UserID, req.Mode, req.DryRun, rows)
+}
+
+// importRows validates the parsed rows of an imported file and creates the expenses of the valid ones,
+// rows of sources that were already imported get skipped
+func (s Expenses) importRows(userID, mode string, dryRun bool, rows []importedRow) (models.ImportReport, error) {
+	if mode == "" {
+		mode = models.RejectImportMode
+	}
+	if err := s.skipImportedSources(rows); err != nil {
+		return models.ImportReport{}, err
+	}
+	categories := map[string]error{}
+	for i := range rows {
+		rows[i].req.UserID = userID
+		rows[i].req.Tags = models.NormalizeTags(rows[i].req.Tags)
+		if rows[i].err != nil || rows[i].skip != "" {
+			continue
+		}
+		if rows[i].err = rows[i].req.Validate(); rows[i].err != nil {
+			continue
+		}
+		categoryID := rows[i].req.CategoryID
+		if _, ok := categories[categoryID]; !ok {
+			categories[categoryID] = s.checkCategory(userID, categoryID)
+		}
+		if _, ok := categories[categoryID].(models.DataValidationError); !ok && categories[categoryID] != nil {
+			return models.ImportReport{}, categories[categoryID]
+		}
+		rows[i].err = categories[categoryID]
+	}
+
+	report := models.ImportReport{Mode: mode, DryRun: dryRun, Rows: make([]models.ImportRow, 0, len(rows))}
+	valid := make([]importedRow, 0, len(rows))
+	failed := false
+	for _, row := range rows {
+		switch {
+		case row.err != nil:
+			failed = true
+		case row.skip == "":
+			valid = append(valid, row)
+		}
+	}
+	if mode == models.RejectImportMode && failed {
+		for _, row := range rows {
+			res := models.ImportRow{Line: row.line, Status: models.SkippedImportStatus, Reason: "import was rejected"}
+			if row.err != nil {
+				res.Status, res.Reason = models.FailedImportStatus, row.err.Error()
+			} else if row.skip != "" {
+				res.Reason = row.skip
+			}
+			report.Add(res)
+		}
+		return report, nil
+	}
+
+	created := map[int]models.Expense{}
+	var expenses []models.Expense
+	if dryRun {
+		for _, row := range valid {
+			created[row.line] = previewExpense(row.req)
+		}
+	} else {
+		reqs := make([]models.CreateExpenseRequest, 0, len(valid))
+		for _, row := range valid {
+			reqs = append(reqs, row.req)
+		}
+		var err error
+		expenses, err = s.ExpensesRepo.CreateExpenses(reqs)
+		if err != nil {
+			logging.Logger.Error("could not import expenses into db", zap.Error(err))
+			return models.ImportReport{}, classifyError(err)
+		}
+		for i, row := range valid {
+			created[row.line] = expenses[i]
+		}
+	}
+
+	for _, row := range rows {
+		switch {
+		case row.err != nil:
+			report.Add(models.ImportRow{Line: row.line, Status: models.FailedImportStatus, Reason: row.err.Error()})
+		case row.skip != "":
+			report.Add(models.ImportRow{Line: row.line, Status: models.SkippedImportStatus, Reason: row.skip})
+		case dryRun:
+			expense := created[row.line]
+			report.Add(models.ImportRow{Line: row.line, Status: models.CreatedImportStatus, Expense: &expense})
+		default:
+			report.Add(models.ImportRow{Line: row.line, Status: models.CreatedImportStatus, ExpenseID: created[row.line].ID})
+		}
+	}
+	s.evaluateBudgets(expenses...)
+	return report, nil
+}
+
+// skipImportedSources skips the rows whose source was already imported, either earlier or by a previous row
+func (s Expenses) skipImportedSources(rows []importedRow) error {
+	sourceIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if row.req.SourceID != "" && row.err == nil && row.skip == "" {
+			sourceIDs = append(sourceIDs, row.req.SourceID)
+		}
+	}
+	if len(sourceIDs) == 0 {
+		return nil
+	}
+	existing, err := s.ExpensesRepo.GetExistingSourceIDs(sourceIDs)
+	if err != nil {
+		logging.Logger.Error("could not get expense sources from db", zap.Error(err))
+		return classifyError(err)
+	}
+
+	seen := map[string]bool{}
+	for i, row := range rows {
+		if row.req.SourceID == "" || row.err != nil || row.skip != "" {
+			continue
+		}
+		switch {
+		case existing[row.req.SourceID]:
+			rows[i].skip = "already imported"
+		case seen[row.req.SourceID]:
+			rows[i].skip = "duplicate of a previous row"
+		}
+		seen[row.req.SourceID] = true
+	}
+	return nil
+}
+
+// previewExpense represents the expense that a given request would create, without an ID
+func previewExpense(req models.CreateExpenseRequest) models.Expense {
+	createdAt := req.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now().UTC()
+	}
+	return models.Expense{
+		OwnerID:     req.UserID,
+		Title:       req.Title,
+		Price:       req.Price,
+		CategoryID:  req.CategoryID,
+		Tags:        req.Tags,
+		Transaction: req.Transaction,
+		CreatedAt:   createdAt.UTC(),
+		ModifiedAt:  createdAt.UTC(),
+	}
+}
+
+// parseExpensesCSV parses the rows of a CSV file into create expense requests,
+// the header columns are matched to import fields by a given mapping or by the field name
+func parseExpensesCSV(r io.Reader, mapping map[string]string, currency string) ([]importedRow, error) {
+	reader := &csvLineReader{r: bufio.NewReader(r)}
+	header, _, err := reader.Read()
+	if err == io.EOF {
+		return nil, models.DataValidationError{Message: "missing csv header"}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := importColumns(header, mapping)
+	missing := []string{models.TitleImportField, models.PriceImportField}
+	if currency == "" {
+		missing = append(missing, models.CurrencyImportField)
+	}
+	for _, field := range missing {
+		if _, ok := columns[field]; !ok {
+			return nil, models.DataValidationError{Message: fmt.Sprintf("missing csv column of field: %s", field)}
+		}
+	}
+
+	rows := make([]importedRow, 0)
+	for {
+		record, line, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if _, ok := err.(models.DataValidationError); err != nil && !ok {
+			return nil, err
+		}
+		if len(rows) == maxImportRows {
+			return nil, models.DataValidationError{Message: fmt.Sprintf("at most %d rows may be imported at once", maxImportRows)}
+		}
+		row := importedRow{line: line, err: err}
+		if err == nil {
+			row.req, row.err = csvExpenseRequest(record, columns, currency)
+		}
+		rows = append(rows, row)
+	}
+}
+
+// importColumns finds the index of the column of every import field in a given header
+func importColumns(header []string, mapping map[string]string) map[string]int {
+	lookup := map[string]string{}
+	for column, field := range mapping {
+		lookup[strings.ToLower(strings.TrimSpace(column))] = field
+	}
+	columns := map[string]int{}
+	for i, column := range header {
+		name := strings.ToLower(strings.TrimSpace(column))
+		field, ok := lookup[name]
+		if !ok {
+			field = name
+		}
+		if _, taken := columns[field]; !taken {
+			columns[field] = i
+		}
+	}
+	return columns
+}
+
+func csvExpenseRequest(record []string, columns map[string]int, currency string) (models.CreateExpenseRequest, error) {
+	value := func(field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	if c := value(models.CurrencyImportField); c != "" {
+		currency = strings.ToUpper(c)
+	}
+	price, err := models.ParseMoney(value(models.PriceImportField), currency)
+	if err != nil {
+		return models.CreateExpenseRequest{}, err
+	}
+	req := models.CreateExpenseRequest{
+		Title:      value(models.TitleImportField),
+		Price:      price,
+		CategoryID: value(models.CategoryIDImportField),
+	}
+	for _, tag := range strings.Split(value(models.TagsImportField), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			req.Tags = append(req.Tags, tag)
+		}
+	}
+	if createdAt := value(models.CreatedAtImportField); createdAt != "" {
+		if req.CreatedAt, err = parseImportTime(createdAt); err != nil {
+			return models.CreateExpenseRequest{}, err
+		}
+	}
+	return req, nil
+}
+
+// parseImportTime parses RFC3339 times or plain dates
+func parseImportTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse(models.DateLayout, value)
+	if err != nil {
+		return time.Time{}, models.DataValidationError{
+			Message: fmt.Sprintf("invalid created_at: %s, expected a date or RFC3339 time", value),
+		}
+	}
+	return t, nil
+}
+
+// csvLineReader reads CSV records along with the line each record starts on.
+// A record spans more lines while it has an unterminated quoted field, empty lines are skipped
+type csvLineReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+// Read reads the next record, records that are not valid CSV come with a DataValidationError
+func (c *csvLineReader) Read() ([]string, int, error) {
+	for {
+		var text strings.Builder
+		start, quotes := c.line+1, 0
+		for {
+			s, err := c.r.ReadString('\n')
+			if s != "" {
+				if c.line == 0 {
+					s = strings.TrimPrefix(s, utf8BOM)
+				}
+				c.line++
+				quotes += strings.Count(s, `"`)
+				text.WriteString(s)
+			}
+			if err == io.EOF && text.Len() == 0 {
+				return nil, 0, io.EOF
+			}
+			if err != nil && err != io.EOF {
+				return nil, 0, err
+			}
+			if err == io.EOF || quotes%2 == 0 {
+				break
+			}
+		}
+		if strings.TrimSpace(text.String()) == "" {
+			continue
+		}
+
+		reader := csv.NewReader(strings.NewReader(text.String()))
+		reader.FieldsPerRecord = -1
+		record, err := reader.Read()
+		if pe, ok := err.(*csv.ParseError); ok {
+			err = pe.Err
+		}
+		if err != nil {
+			return nil, start, models.DataValidationError{Message: fmt.Sprintf("invalid csv: %v", err)}
+		}
+		return record, start, nil
+	}
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+func TestParseExpensesCSV(t *testing.T) {
+	content := "\ufeffName,Amount,Tags\n" +
+		"Coffee,3.50,\"a, b\"\n" +
+		"\n" +
+		"\"Multi\nline\",10,\n" +
+		"Bad,abc,\n" +
+		"\"broken,1\n"
+	mapping := map[string]string{"name": models.TitleImportField, "AMOUNT": models.PriceImportField}
+
+	rows, err := parseExpensesCSV(strings.NewReader(content), mapping, "EUR")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		line  int
+		title string
+		fails bool
+	}{
+		{line: 2, title: "Coffee"},
+		{line: 4, title: "Multi\nline"},
+		{line: 6, fails: true},
+		{line: 7, fails: true},
+	}
+	if len(rows) != len(tests) {
+		t.Fatalf("got %d rows, want %d", len(rows), len(tests))
+	}
+	for i, tt := range tests {
+		row := rows[i]
+		if row.line != tt.line {
+			t.Errorf("row %d: got line %d, want %d", i, row.line, tt.line)
+		}
+		if tt.fails {
+			if _, ok := row.err.(models.DataValidationError); !ok {
+				t.Errorf("line %d: got error %v, want a data validation error", row.line, row.err)
+			}
+			continue
+		}
+		if row.err != nil {
+			t.Errorf("line %d: unexpected error: %v", row.line, row.err)
+		}
+		if row.req.Title != tt.title {
+			t.Errorf("line %d: got title %q, want %q", row.line, row.req.Title, tt.title)
+		}
+	}
+	if got := rows[0].req.Tags; len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("got tags %v, want [a b]", got)
+	}
+	if got := rows[0].req.Price; got.Amount != 35000 || got.Currency != "EUR" {
+		t.Errorf("got price %v, want 3.50 EUR", got)
+	}
+}
+
+func TestParseExpensesCSVMissingColumn(t *testing.T) {
+	_, err := parseExpensesCSV(strings.NewReader("title,price\nCoffee,3.50\n"), nil, "")
+	if _, ok := err.(models.DataValidationError); !ok {
+		t.Fatalf("got error %v, want a data validation error", err)
+	}
+}
+
+func testImportRows() []importedRow {
+	price := models.Money{Amount: 35000, Currency: "EUR"}
+	return []importedRow{
+		{line: 2, req: models.CreateExpenseRequest{Title: "Coffee", Price: price}},
+		{line: 3, err: models.DataValidationError{Message: "invalid price"}},
+		{line: 4, req: models.CreateExpenseRequest{Price: price}},
+		{line: 5, req: models.CreateExpenseRequest{Title: "Tea", Price: price, SourceID: "s1"}},
+	}
+}
+
+func TestImportRows(t *testing.T) {
+	tests := []struct {
+		mode        string
+		wantStatus  []string
+		wantCreated int
+		wantCalls   int
+	}{
+		{
+			mode: models.RejectImportMode,
+			wantStatus: []string{
+				models.SkippedImportStatus,
+				models.FailedImportStatus,
+				models.FailedImportStatus,
+				models.SkippedImportStatus,
+			},
+		},
+		{
+			mode: models.SkipImportMode,
+			wantStatus: []string{
+				models.CreatedImportStatus,
+				models.FailedImportStatus,
+				models.FailedImportStatus,
+				models.SkippedImportStatus,
+			},
+			wantCreated: 1,
+			wantCalls:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			repo := newFakeImportRepo()
+			repo.sources["s1"] = true
+			report, err := Expenses{ExpensesRepo: repo}.importRows("u1", tt.mode, false, testImportRows())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if repo.createCalls != tt.wantCalls {
+				t.Errorf("got %d create calls, want %d", repo.createCalls, tt.wantCalls)
+			}
+			if report.Created != tt.wantCreated || report.Failed != 2 {
+				t.Errorf("got %d created and %d failed rows, want %d and 2", report.Created, report.Failed, tt.wantCreated)
+			}
+			if len(report.Rows) != len(tt.wantStatus) {
+				t.Fatalf("got %d rows, want %d", len(report.Rows), len(tt.wantStatus))
+			}
+			for i, status := range tt.wantStatus {
+				if row := report.Rows[i]; row.Status != status {
+					t.Errorf("line %d: got status %s, want %s, reason: %s", row.Line, row.Status, status, row.Reason)
+				}
+			}
+			if row := report.Rows[3]; row.Reason != "already imported" {
+				t.Errorf("got reason %q, want the already imported source to be reported", row.Reason)
+			}
+		})
+	}
+}
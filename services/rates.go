@@ -0,0 +1,183 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/repositories"
+)
+
+// ecbBaseCurrency represents the base currency of the rates published by the European Central Bank
+const ecbBaseCurrency = "EUR"
+
+// ecbDateLayouts represents the date layouts used by the ECB daily and historical rates files
+var ecbDateLayouts = []string{models.DateLayout, "2 January 2006"}
+
+// Rates represents the exchange Rates service
+type Rates struct {
+	RatesRepo repositories.Rates
+}
+
+// PutRates saves the exchange rates of a day, replacing the ones already stored for that day
+func (s Rates) PutRates(rates models.DailyRates) (models.DailyRates, error) {
+	normalized, err := normalizeRates(rates)
+	if err != nil {
+		return models.DailyRates{}, err
+	}
+	if err = s.RatesRepo.PutRates(normalized); err != nil {
+		logging.Logger.Error("could not save rates into db", zap.Error(err))
+		return models.DailyRates{}, classifyError(err)
+	}
+	return normalized, nil
+}
+
+// GetRates fetches the exchange rates of the days within an inclusive date range
+func (s Rates) GetRates(req models.GetRatesRequest) ([]models.DailyRates, error) {
+	rates, err := s.RatesRepo.GetRates(req.From, req.To)
+	if err != nil {
+		logging.Logger.Error("could not fetch rates from db", zap.Error(err))
+		return []models.DailyRates{}, classifyError(err)
+	}
+	return rates, nil
+}
+
+// ImportRates saves every day of an ECB style CSV or XML rates file in a single transaction,
+// replacing the rates already stored for those days, and returns the amount of saved days
+func (s Rates) ImportRates(format string, r io.Reader) (int, error) {
+	return s.importRates(format, r, true)
+}
+
+// ImportMissingRates saves the days of an ECB style CSV or XML rates file that have no rates stored yet
+// in a single transaction, and returns the amount of saved days.
+// Importing the same file again saves nothing, and never overwrites the rates saved by PutRates
+func (s Rates) ImportMissingRates(format string, r io.Reader) (int, error) {
+	return s.importRates(format, r, false)
+}
+
+func (s Rates) importRates(format string, r io.Reader, replace bool) (int, error) {
+	var days []models.DailyRates
+	var err error
+	switch format {
+	case models.CSVRatesFormat:
+		days, err = parseECBCSV(r)
+	case models.XMLRatesFormat:
+		days, err = parseECBXML(r)
+	default:
+		err = models.DataValidationError{Message: fmt.Sprintf("unsupported rates format: %s", format)}
+	}
+	if err != nil {
+		return 0, err
+	}
+	for i, day := range days {
+		if days[i], err = normalizeRates(day); err != nil {
+			return 0, err
+		}
+	}
+
+	saved, err := s.RatesRepo.ImportRates(days, replace)
+	if err != nil {
+		logging.Logger.Error("could not import rates into db", zap.Error(err))
+		return 0, classifyError(err)
+	}
+	return saved, nil
+}
+
+// normalizeRates upper cases the currencies of the rates of a day, trims the rates and validates them
+func normalizeRates(rates models.DailyRates) (models.DailyRates, error) {
+	normalized := models.DailyRates{
+		Date:  rates.Date,
+		Base:  strings.ToUpper(strings.TrimSpace(rates.Base)),
+		Rates: make(map[string]string, len(rates.Rates)),
+	}
+	for currency, rate := range rates.Rates {
+		normalized.Rates[strings.ToUpper(strings.TrimSpace(currency))] = strings.TrimSpace(rate)
+	}
+	if err := normalized.Validate(); err != nil {
+		return models.DailyRates{}, err
+	}
+	return normalized, nil
+}
+
+// parseECBCSV parses rates files such as eurofxref-hist.csv, with a date column and one column per currency
+func parseECBCSV(r io.Reader) ([]models.DailyRates, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, models.DataValidationError{Message: fmt.Sprintf("invalid rates csv: %v", err)}
+	}
+	if len(records) == 0 || !strings.EqualFold(strings.TrimSpace(records[0][0]), "date") {
+		return nil, models.DataValidationError{Message: "invalid rates csv: missing Date header"}
+	}
+
+	header := records[0]
+	days := make([]models.DailyRates, 0, len(records)-1)
+	for _, record := range records[1:] {
+		date, err := parseECBDate(record[0])
+		if err != nil {
+			return nil, err
+		}
+		day := models.DailyRates{Date: date, Base: ecbBaseCurrency, Rates: map[string]string{}}
+		for i := 1; i < len(record) && i < len(header); i++ {
+			currency, rate := strings.TrimSpace(header[i]), strings.TrimSpace(record[i])
+			if currency == "" || rate == "" || rate == "N/A" {
+				continue
+			}
+			day.Rates[currency] = rate
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// ecbEnvelope represents the ECB XML rates document, such as eurofxref-daily.xml
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// parseECBXML parses rates files such as eurofxref-hist.xml, with one Cube element per day
+func parseECBXML(r io.Reader) ([]models.DailyRates, error) {
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, models.DataValidationError{Message: fmt.Sprintf("invalid rates xml: %v", err)}
+	}
+
+	days := make([]models.DailyRates, 0, len(envelope.Days))
+	for _, d := range envelope.Days {
+		date, err := parseECBDate(d.Time)
+		if err != nil {
+			return nil, err
+		}
+		day := models.DailyRates{Date: date, Base: ecbBaseCurrency, Rates: map[string]string{}}
+		for _, rate := range d.Rates {
+			day.Rates[strings.TrimSpace(rate.Currency)] = strings.TrimSpace(rate.Rate)
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func parseECBDate(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range ecbDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.Format(models.DateLayout), nil
+		}
+	}
+	return "", models.DataValidationError{Message: fmt.Sprintf("invalid rates date: %s", s)}
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/repositories"
+)
+
+// fakeRatesRepo represents a rates repository that keeps rates in memory and records import transactions
+type fakeRatesRepo struct {
+	repositories.Rates
+	stored  map[string]models.DailyRates
+	imports int
+}
+
+func (r *fakeRatesRepo) ImportRates(days []models.DailyRates, replace bool) (int, error) {
+	r.imports++
+	saved := 0
+	for _, day := range days {
+		if _, ok := r.stored[day.Date]; ok && !replace {
+			continue
+		}
+		r.stored[day.Date] = day
+		saved++
+	}
+	return saved, nil
+}
+
+func TestImportRates(t *testing.T) {
+	content := "Date, USD, GBP,\n" +
+		"2024-01-03, 1.0919, 0.86290,\n" +
+		"2024-01-02, 1.0956, 0.86665,\n"
+	putByHand := models.DailyRates{Date: "2024-01-02", Base: "EUR", Rates: map[string]string{"USD": "1.1"}}
+	tests := []struct {
+		name      string
+		missing   bool
+		wantSaved int
+		wantUSD   string
+	}{
+		{
+			name:      "uploads replace stored days",
+			wantSaved: 2,
+			wantUSD:   "1.0956",
+		},
+		{
+			name:      "startup imports keep stored days",
+			missing:   true,
+			wantSaved: 1,
+			wantUSD:   "1.1",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repo := &fakeRatesRepo{stored: map[string]models.DailyRates{putByHand.Date: putByHand}}
+			service := Rates{RatesRepo: repo}
+			importFn := service.ImportRates
+			if test.missing {
+				importFn = service.ImportMissingRates
+			}
+
+			saved, err := importFn(models.CSVRatesFormat, strings.NewReader(content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if saved != test.wantSaved || repo.imports != 1 {
+				t.Fatalf("got %d saved days in %d transactions, want %d in 1", saved, repo.imports, test.wantSaved)
+			}
+			if got := repo.stored["2024-01-02"].Rates["USD"]; got != test.wantUSD {
+				t.Fatalf("got USD rate %s, want %s", got, test.wantUSD)
+			}
+			if got := repo.stored["2024-01-03"].Rates["GBP"]; got != "0.86290" {
+				t.Fatalf("got GBP rate %s, want 0.86290", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/repositories"
+)
+
+// Recurring represents the Recurring expenses service
+type Recurring struct {
+	RecurringRepo repositories.Recurring
+	Expenses      Expenses
+}
+
+// GetRecurringExpenses fetches all the recurring expenses of a given user
+func (s Recurring) GetRecurringExpenses(userID string) ([]models.RecurringExpense, error) {
+	recurring, err := s.RecurringRepo.GetRecurringExpenses(userID)
+	if err != nil {
+		logging.Logger.Error("could not fetch recurring expenses from db", zap.Error(err))
+		return []models.RecurringExpense{}, classifyError(err)
+	}
+	return recurring, nil
+}
+
+// GetRecurringExpense fetches a recurring expense of a given user by a given ID
+func (s Recurring) GetRecurringExpense(userID, id string) (models.RecurringExpense, error) {
+	recurring, err := s.RecurringRepo.GetRecurringExpense(userID, id)
+	return recurring, classifyError(err)
+}
+
+// CreateRecurringExpense creates a brand new recurring expense, its due occurrences get created by the scheduler
+func (s Recurring) CreateRecurringExpense(req models.CreateRecurringExpenseRequest) (models.RecurringExpense, error) {
+	if err := s.Expenses.checkCategory(req.UserID, req.Template.CategoryID); err != nil {
+		return models.RecurringExpense{}, err
+	}
+	req.Template.Tags = models.NormalizeTags(req.Template.Tags)
+	if req.Schedule.Interval == 0 {
+		req.Schedule.Interval = 1
+	}
+	recurring, err := s.RecurringRepo.CreateRecurringExpense(req)
+	if err != nil {
+		logging.Logger.Error("could not create recurring expense in db", zap.Error(err))
+		return models.RecurringExpense{}, classifyError(err)
+	}
+	return recurring, nil
+}
+
+// UpdateRecurringExpense updates an existing recurring expense and returns its updated version,
+// the expenses it already created are left untouched
+func (s Recurring) UpdateRecurringExpense(req models.UpdateRecurringExpenseRequest) (models.RecurringExpense, error) {
+	if req.Template != nil {
+		if err := s.Expenses.checkCategory(req.UserID, req.Template.CategoryID); err != nil {
+			return models.RecurringExpense{}, err
+		}
+		req.Template.Tags = models.NormalizeTags(req.Template.Tags)
+	}
+	if req.Schedule != nil && req.Schedule.Interval == 0 {
+		req.Schedule.Interval = 1
+	}
+	recurring, err := s.RecurringRepo.UpdateRecurringExpense(req)
+	if err != nil {
+		logging.Logger.Error("could not update recurring expense in db", zap.Error(err))
+		return models.RecurringExpense{}, classifyError(err)
+	}
+	return recurring, nil
+}
+
+// DeleteRecurringExpense deletes a recurring expense of a given user by a given ID
+func (s Recurring) DeleteRecurringExpense(userID, id string) error {
+	return classifyError(s.RecurringRepo.DeleteRecurringExpense(userID, id))
+}
+
+// Materialize creates an expense for every occurrence of every recurring expense that is due by a given time,
+// including the ones missed while the application was down. Every occurrence has its own expense source ID,
+// so an occurrence is never created twice, even when the last run could not be saved
+func (s Recurring) Materialize(ctx context.Context, now time.Time) (int, error) {
+	all, err := s.RecurringRepo.GetAllRecurringExpenses()
+	if err != nil {
+		logging.Logger.Error("could not fetch recurring expenses from db", zap.Error(err))
+		return 0, classifyError(err)
+	}
+
+	var created int
+	for _, recurring := range all {
+		if ctx.Err() != nil {
+			break
+		}
+		lastRun := ""
+		for _, occurrence := range recurring.DueOccurrences(now) {
+			if ctx.Err() != nil {
+				break
+			}
+			_, err := s.Expenses.CreateExpense(models.CreateExpenseRequest{
+				UserID:     recurring.OwnerID,
+				Title:      recurring.Template.Title,
+				Price:      recurring.Template.Price,
+				CategoryID: recurring.Template.CategoryID,
+				Tags:       recurring.Template.Tags,
+				SourceID:   recurring.SourceID(occurrence),
+				CreatedAt:  occurrence,
+			})
+			if _, ok := err.(models.ConflictError); err != nil && !ok {
+				logging.Logger.Warn(
+					"could not create recurring expense occurrence",
+					zap.String("id", recurring.ID),
+					zap.Time("occurrence", occurrence),
+					zap.Error(err),
+				)
+				break
+			}
+			if err == nil {
+				created++
+			}
+			lastRun = occurrence.Format(models.DateLayout)
+		}
+		if lastRun == "" {
+			continue
+		}
+		if err := s.RecurringRepo.SetRecurringLastRun(recurring.ID, lastRun); err != nil {
+			logging.Logger.Error("could not save recurring expense last run", zap.String("id", recurring.ID), zap.Error(err))
+		}
+	}
+	return created, ctx.Err()
+}
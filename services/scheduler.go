@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+)
+
+const defaultSchedulerInterval = time.Minute
+
+// Scheduler represents the in-process scheduler that materializes due recurring expenses in the background
+type Scheduler struct {
+	Recurring Recurring
+	Interval  time.Duration
+	cancel    context.CancelFunc
+	done      sync.WaitGroup
+}
+
+// Start runs the scheduler right away, catching up on the occurrences missed while the application was down,
+// and then once every interval until the scheduler is stopped
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done.Add(1)
+	go func() {
+		defer s.done.Done()
+		interval := s.Interval
+		if interval <= 0 {
+			interval = defaultSchedulerInterval
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			s.run(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the scheduler and waits for the ongoing run to finish
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.done.Wait()
+}
+
+func (s *Scheduler) run(ctx context.Context) {
+	created, err := s.Recurring.Materialize(ctx, time.Now().UTC())
+	if err != nil && ctx.Err() == nil {
+		logging.Logger.Error("could not materialize recurring expenses", zap.Error(err))
+		return
+	}
+	if created > 0 {
+		logging.Logger.Info("created recurring expenses", zap.Int("count", created))
+	}
+}
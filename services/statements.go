@@ -0,0 +1,478 @@
+package services
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// qifTransactionTypes represents the QIF account types whose records are bank transactions
+var qifTransactionTypes = map[string]bool{
+	"bank":  true,
+	"cash":  true,
+	"ccard": true,
+	"oth a": true,
+	"oth l": true,
+}
+
+// statementTransaction represents a transaction parsed from a statement along with the line it starts on,
+// transactions that could not be parsed carry the reason
+type statementTransaction struct {
+	line int
+	txn  models.StatementTransaction
+	err  error
+}
+
+// ImportStatement creates expenses from the debit transactions of an OFX, QFX or QIF bank statement.
+// Transactions are deduplicated by the transaction ID of the bank, so importing the same statement twice creates nothing
+func (s Expenses) ImportStatement(req models.ImportStatementRequest) (models.ImportReport, error) {
+	var transactions []statementTransaction
+	var err error
+	currency := strings.ToUpper(req.Currency)
+	switch req.Format {
+	case models.OFXStatementFormat:
+		transactions, err = parseOFX(req.Content, currency)
+	case models.QIFStatementFormat:
+		transactions, err = parseQIF(req.Content, currency, req.DateOrder)
+	}
+	if err != nil {
+		return models.ImportReport{}, err
+	}
+	if len(transactions) > maxImportRows {
+		return models.ImportReport{}, models.DataValidationError{
+			Message: fmt.Sprintf("at most %d transactions may be imported at once", maxImportRows),
+		}
+	}
+
+	rows := make([]importedRow, 0, len(transactions))
+	for _, t := range transactions {
+		row := importedRow{line: t.line, err: t.err}
+		if t.err == nil {
+			row.req, row.skip = statementExpenseRequest(req.UserID, t.txn)
+		}
+		rows = append(rows, row)
+	}
+	return s.importRows(req.UserID, req.Mode, req.DryRun, rows)
+}
+
+// statementExpenseRequest maps a debit transaction to a create expense request, credits are skipped with a reason
+func statementExpenseRequest(userID string, t models.StatementTransaction) (models.CreateExpenseRequest, string) {
+	if t.Amount.Amount >= 0 {
+		return models.CreateExpenseRequest{}, "not a debit transaction"
+	}
+	title := t.Payee
+	if title == "" {
+		title = t.Memo
+	}
+	createdAt := t.PostedAt
+	if !t.UserAt.IsZero() {
+		createdAt = t.UserAt
+	}
+	req := models.CreateExpenseRequest{
+		Title:       title,
+		Price:       models.Money{Amount: -t.Amount.Amount, Currency: t.Amount.Currency},
+		SourceID:    t.SourceID(userID),
+		Transaction: &models.BankTransaction{ID: t.ID, PostedAt: t.PostedAt},
+		CreatedAt:   createdAt,
+	}
+	if t.Category != "" {
+		req.Tags = []string{t.Category}
+	}
+	return req, ""
+}
+
+// parseOFX parses the transactions of an OFX or QFX statement. SGML statements leave the elements that hold values
+// unclosed, so both flavours are read as a stream of tags where the text after an opening tag is its value
+func parseOFX(r io.Reader, currency string) ([]statementTransaction, error) {
+	content, err := readStatement(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(strings.ToUpper(content), "<OFX>") {
+		return nil, models.DataValidationError{Message: "invalid OFX statement, missing the OFX element"}
+	}
+
+	transactions := make([]statementTransaction, 0)
+	var current *ofxTransaction
+	var account string
+	var inCurrency bool
+	line := 1
+	for pos := 0; pos < len(content); {
+		start := strings.IndexByte(content[pos:], '<')
+		if start < 0 {
+			break
+		}
+		line += strings.Count(content[pos:pos+start], "\n")
+		pos += start
+		end := strings.IndexByte(content[pos:], '>')
+		if end < 0 {
+			break
+		}
+		tag := strings.ToUpper(strings.TrimSpace(content[pos+1 : pos+end]))
+		pos += end + 1
+		next := strings.IndexByte(content[pos:], '<')
+		if next < 0 {
+			next = len(content) - pos
+		}
+		// banks write the character entities of HTML into SGML statements as well
+		value := strings.TrimSpace(html.UnescapeString(content[pos : pos+next]))
+
+		switch {
+		case strings.HasPrefix(tag, "?"), strings.HasPrefix(tag, "!"):
+		case tag == "STMTTRN":
+			current = &ofxTransaction{line: line, account: account, currency: currency}
+		case tag == "/STMTTRN" && current != nil:
+			transactions = append(transactions, current.transaction())
+			current = nil
+		case tag == "CURRENCY", tag == "/CURRENCY":
+			inCurrency = tag == "CURRENCY"
+		case tag == "CURDEF":
+			currency = strings.ToUpper(value)
+		case tag == "ACCTID" && current == nil:
+			account = value
+		case current != nil:
+			current.set(tag, value, inCurrency)
+		}
+	}
+	return transactions, nil
+}
+
+// ofxTransaction represents the raw values of an OFX STMTTRN element
+type ofxTransaction struct {
+	line                           int
+	account, currency              string
+	id, posted, user, amount, name string
+	memo                           string
+}
+
+func (t *ofxTransaction) set(tag, value string, inCurrency bool) {
+	switch tag {
+	case "FITID":
+		t.id = value
+	case "DTPOSTED":
+		t.posted = value
+	case "DTUSER":
+		t.user = value
+	case "TRNAMT":
+		t.amount = value
+	case "NAME":
+		t.name = value
+	case "MEMO":
+		t.memo = value
+	case "CURSYM":
+		// amounts are in the currency of a CURRENCY element, ORIGCURRENCY only tells the original currency
+		if inCurrency {
+			t.currency = strings.ToUpper(value)
+		}
+	}
+}
+
+func (t *ofxTransaction) transaction() statementTransaction {
+	res := statementTransaction{line: t.line}
+	if t.id == "" {
+		res.err = models.DataValidationError{Message: "transaction has no FITID"}
+		return res
+	}
+	posted, err := parseOFXTime(t.posted)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	var user time.Time
+	if t.user != "" {
+		if user, err = parseOFXTime(t.user); err != nil {
+			res.err = err
+			return res
+		}
+	}
+	amount, err := parseStatementAmount(t.amount, t.currency)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	res.txn = models.StatementTransaction{
+		ID:       t.id,
+		Account:  t.account,
+		PostedAt: posted,
+		UserAt:   user,
+		Amount:   amount,
+		Payee:    t.name,
+		Memo:     t.memo,
+	}
+	return res
+}
+
+// parseOFXTime parses OFX date times, such as 20200131, 20200131120000 or 20200131120000.000[-5:EST]
+func parseOFXTime(value string) (time.Time, error) {
+	invalidErr := models.DataValidationError{Message: fmt.Sprintf("invalid OFX date: %q", value)}
+	digits, zone := value, ""
+	if i := strings.IndexByte(value, '['); i >= 0 {
+		digits, zone = value[:i], strings.TrimSuffix(value[i+1:], "]")
+	}
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		digits = digits[:i]
+	}
+	layouts := map[int]string{8: "20060102", 12: "200601021504", 14: "20060102150405"}
+	layout, ok := layouts[len(digits)]
+	if !ok {
+		return time.Time{}, invalidErr
+	}
+	t, err := time.Parse(layout, digits)
+	if err != nil {
+		return time.Time{}, invalidErr
+	}
+	if zone == "" {
+		return t, nil
+	}
+	offset, err := strconv.ParseFloat(strings.SplitN(zone, ":", 2)[0], 64)
+	if err != nil {
+		return time.Time{}, invalidErr
+	}
+	return t.Add(-time.Duration(offset * float64(time.Hour))).UTC(), nil
+}
+
+// parseQIF parses the transactions of the bank, cash and credit card accounts of a QIF statement.
+// QIF has no transaction IDs, so transactions are identified by a hash of their fields and of their occurrence,
+// which stays the same when the same statement is imported again
+func parseQIF(r io.Reader, currency, dateOrder string) ([]statementTransaction, error) {
+	content, err := readStatement(r)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*qifRecord, 0)
+	var current *qifRecord
+	var account, section string
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		if strings.HasPrefix(text, "!") {
+			switch header := strings.ToLower(strings.TrimSpace(text)); {
+			case header == "!account":
+				section = "account"
+			case strings.HasPrefix(header, "!type:"):
+				section = strings.TrimSpace(strings.TrimPrefix(header, "!type:"))
+			}
+			current = nil
+			continue
+		}
+
+		code, value := text[0], strings.TrimSpace(text[1:])
+		if code == '^' {
+			if current != nil {
+				records = append(records, current)
+			}
+			current = nil
+			continue
+		}
+		if section == "account" {
+			if code == 'N' {
+				account = value
+			}
+			continue
+		}
+		if !qifTransactionTypes[section] {
+			continue
+		}
+		if current == nil {
+			current = &qifRecord{line: line, account: account}
+		}
+		current.set(code, value)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	if current != nil {
+		records = append(records, current)
+	}
+
+	if dateOrder == "" {
+		if dateOrder, err = detectQIFDateOrder(records); err != nil {
+			return nil, err
+		}
+	}
+	occurrences := map[string]int{}
+	transactions := make([]statementTransaction, 0, len(records))
+	for _, record := range records {
+		transactions = append(transactions, record.transaction(currency, dateOrder, occurrences))
+	}
+	return transactions, nil
+}
+
+// qifRecord represents the raw fields of a QIF transaction record
+type qifRecord struct {
+	line                   int
+	account                string
+	date, amount, payee    string
+	memo, category, number string
+}
+
+func (r *qifRecord) set(code byte, value string) {
+	switch code {
+	case 'D':
+		r.date = value
+	case 'T', 'U':
+		if r.amount == "" {
+			r.amount = value
+		}
+	case 'P':
+		r.payee = value
+	case 'M':
+		r.memo = value
+	case 'L':
+		// categories in brackets are transfers between accounts
+		if !strings.HasPrefix(value, "[") {
+			r.category = value
+		}
+	case 'N':
+		r.number = value
+	}
+}
+
+func (r *qifRecord) transaction(currency, dateOrder string, occurrences map[string]int) statementTransaction {
+	res := statementTransaction{line: r.line}
+	parsed, err := parseQIFDate(r.date)
+	if err != nil {
+		res.err = err
+		return res
+	}
+	date, ok := parsed.time(dateOrder)
+	if !ok {
+		res.err = models.DataValidationError{Message: fmt.Sprintf("invalid QIF date: %q", r.date)}
+		return res
+	}
+	amount, err := parseStatementAmount(r.amount, currency)
+	if err != nil {
+		res.err = err
+		return res
+	}
+
+	key := strings.Join([]string{date.Format(models.DateLayout), amount.String(), r.payee, r.memo, r.number}, "\x00")
+	occurrences[key]++
+	sum := sha256.Sum256([]byte(key + "\x00" + strconv.Itoa(occurrences[key])))
+	res.txn = models.StatementTransaction{
+		ID:       "qif:" + hex.EncodeToString(sum[:8]),
+		Account:  r.account,
+		PostedAt: date,
+		Amount:   amount,
+		Payee:    r.payee,
+		Memo:     r.memo,
+		Category: r.category,
+	}
+	return res
+}
+
+// qifDate represents the numbers of a QIF date, the order of the first two depends on the statement
+// unless the date is written year first, such as 2020-01-31
+type qifDate struct {
+	year, first, second int
+	yearFirst           bool
+}
+
+// parseQIFDate parses QIF dates, such as 1/31/2020, 1/31'20, 31.01.2020 or 2020-01-31.
+// Two digit years are taken as 1970 to 2069
+func parseQIFDate(value string) (qifDate, error) {
+	invalidErr := models.DataValidationError{Message: fmt.Sprintf("invalid QIF date: %q", value)}
+	fields := strings.FieldsFunc(strings.ReplaceAll(value, " ", ""), func(r rune) bool {
+		return r == '/' || r == '\'' || r == '.' || r == '-'
+	})
+	if len(fields) != 3 {
+		return qifDate{}, invalidErr
+	}
+	var numbers [3]int
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return qifDate{}, invalidErr
+		}
+		numbers[i] = n
+	}
+	if len(fields[0]) == 4 {
+		return qifDate{year: numbers[0], first: numbers[1], second: numbers[2], yearFirst: true}, nil
+	}
+	date := qifDate{year: numbers[2], first: numbers[0], second: numbers[1]}
+	if len(fields[2]) <= 2 {
+		date.year += 1900
+		if numbers[2] < 70 {
+			date.year += 100
+		}
+	}
+	return date, nil
+}
+
+// time returns the date for a given date order, it is not ok when the date does not exist
+func (d qifDate) time(dateOrder string) (time.Time, bool) {
+	month, day := d.first, d.second
+	if !d.yearFirst && dateOrder == models.DayFirstDateOrder {
+		month, day = d.second, d.first
+	}
+	t := time.Date(d.year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	return t, t.Month() == time.Month(month) && t.Day() == day
+}
+
+// detectQIFDateOrder tells whether the dates of a QIF statement are written month or day first, month first by default
+func detectQIFDateOrder(records []*qifRecord) (string, error) {
+	var monthFirst, dayFirst bool
+	for _, record := range records {
+		date, err := parseQIFDate(record.date)
+		if err != nil || date.yearFirst {
+			continue
+		}
+		monthFirst = monthFirst || date.second > 12
+		dayFirst = dayFirst || date.first > 12
+	}
+	if monthFirst && dayFirst {
+		return "", models.DataValidationError{Message: "QIF dates are written both month and day first, set date_order"}
+	}
+	if dayFirst {
+		return models.DayFirstDateOrder, nil
+	}
+	return models.MonthFirstDateOrder, nil
+}
+
+// parseStatementAmount parses signed statement amounts, either with decimal points or with decimal commas,
+// such as -1,234.56 or -1.234,56
+func parseStatementAmount(value, currency string) (models.Money, error) {
+	amount := strings.TrimPrefix(strings.ReplaceAll(value, " ", ""), "+")
+	lastComma, lastPoint := strings.LastIndexByte(amount, ','), strings.LastIndexByte(amount, '.')
+	switch {
+	case lastComma > lastPoint && (lastPoint >= 0 || strings.Count(amount, ",") == 1 && len(amount)-lastComma-1 != 3):
+		amount = strings.ReplaceAll(amount, ".", "")
+		amount = strings.Replace(amount, ",", ".", 1)
+	default:
+		amount = strings.ReplaceAll(amount, ",", "")
+	}
+	if amount == "" {
+		return models.Money{}, models.DataValidationError{Message: "transaction has no amount"}
+	}
+	return models.ParseMoney(amount, currency)
+}
+
+// readStatement reads a whole statement as UTF-8, statements of other charsets are taken as Latin-1
+func readStatement(r io.Reader) (string, error) {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	if utf8.Valid(content) {
+		return strings.TrimPrefix(string(content), utf8BOM), nil
+	}
+	runes := make([]rune, len(content))
+	for i, b := range content {
+		runes[i] = rune(b)
+	}
+	return string(runes), nil
+}
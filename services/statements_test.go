@@ -0,0 +1,209 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/repositories"
+)
+
+// fakeImportRepo represents an expenses repository that keeps the sources of the expenses it created
+type fakeImportRepo struct {
+	repositories.Expenses
+	sources     map[string]bool
+	createCalls int
+}
+
+func newFakeImportRepo() *fakeImportRepo {
+	return &fakeImportRepo{sources: map[string]bool{}}
+}
+
+func (r *fakeImportRepo) GetExistingSourceIDs(sourceIDs []string) (map[string]bool, error) {
+	existing := map[string]bool{}
+	for _, sourceID := range sourceIDs {
+		existing[sourceID] = r.sources[sourceID]
+	}
+	return existing, nil
+}
+
+func (r *fakeImportRepo) CreateExpenses(reqs []models.CreateExpenseRequest) ([]models.Expense, error) {
+	r.createCalls++
+	expenses := make([]models.Expense, 0, len(reqs))
+	for _, req := range reqs {
+		if req.SourceID != "" {
+			r.sources[req.SourceID] = true
+		}
+		expenses = append(expenses, models.Expense{ID: fmt.Sprintf("e%d", len(r.sources)), Title: req.Title})
+	}
+	return expenses, nil
+}
+
+const testStatement = "<OFX><STMTRS><CURDEF>EUR</CURDEF><BANKACCTFROM><ACCTID>ACC-1</ACCTID></BANKACCTFROM>\n" +
+	"<STMTTRN><DTPOSTED>20240102</DTPOSTED><TRNAMT>-12.50</TRNAMT><FITID>T1</FITID><NAME>Cafe</NAME></STMTTRN>\n" +
+	"<STMTTRN><DTPOSTED>20240103</DTPOSTED><TRNAMT>-7.00</TRNAMT><FITID>T2</FITID><NAME>Bakery</NAME></STMTTRN>\n" +
+	"</STMTRS></OFX>\n"
+
+func importTestStatement(s Expenses, content string, dryRun bool) (models.ImportReport, error) {
+	return s.ImportStatement(models.ImportStatementRequest{
+		UserID:  "u1",
+		Format:  models.OFXStatementFormat,
+		Mode:    models.SkipImportMode,
+		DryRun:  dryRun,
+		Content: strings.NewReader(content),
+	})
+}
+
+func TestImportStatementTwiceCreatesNothing(t *testing.T) {
+	repo := newFakeImportRepo()
+	service := Expenses{ExpensesRepo: repo}
+	report, err := importTestStatement(service, testStatement, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Created != 2 {
+		t.Fatalf("expected 2 created expenses, got: %+v", report)
+	}
+
+	report, err = importTestStatement(service, testStatement, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Created != 0 || report.Skipped != 2 {
+		t.Fatalf("expected every transaction to be skipped, got: %+v", report)
+	}
+	for _, row := range report.Rows {
+		if row.Status != models.SkippedImportStatus || row.Reason != "already imported" {
+			t.Fatalf("expected the row to be already imported, got: %+v", row)
+		}
+	}
+}
+
+func TestImportStatementDryRunCreatesNothing(t *testing.T) {
+	repo := newFakeImportRepo()
+	report, err := importTestStatement(Expenses{ExpensesRepo: repo}, testStatement, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.createCalls != 0 {
+		t.Fatalf("expected a dry run not to create expenses, got: %d calls", repo.createCalls)
+	}
+	if !report.DryRun || report.Created != 2 || report.Rows[0].Expense == nil || report.Rows[0].Expense.Title != "Cafe" {
+		t.Fatalf("expected the previews of both expenses, got: %+v", report)
+	}
+}
+
+func TestImportStatementDuplicateTransactions(t *testing.T) {
+	content := strings.Replace(testStatement, "<FITID>T2</FITID>", "<FITID>T1</FITID>", 1)
+	report, err := importTestStatement(Expenses{ExpensesRepo: newFakeImportRepo()}, content, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Created != 1 || report.Skipped != 1 {
+		t.Fatalf("expected one created and one skipped transaction, got: %+v", report)
+	}
+	if row := report.Rows[1]; row.Status != models.SkippedImportStatus || row.Reason != "duplicate of a previous row" {
+		t.Fatalf("expected the second transaction to be a duplicate, got: %+v", row)
+	}
+}
+
+func TestParseOFX(t *testing.T) {
+	sgml := "OFXHEADER:100\nDATA:OFXSGML\n\n<OFX>\n<STMTRS><CURDEF>EUR\n" +
+		"<BANKACCTFROM><ACCTID>ACC-1</BANKACCTFROM>\n" +
+		"<STMTTRN>\n<DTPOSTED>20240102120000.000[-5:EST]\n<TRNAMT>-12,50\n<FITID>T1\n<NAME>Caf&eacute; &amp; Co\n</STMTTRN>\n" +
+		"<STMTTRN><DTPOSTED>20240104<TRNAMT>-7.00<FITID>T2<CURRENCY><CURSYM>USD</CURRENCY></STMTTRN>\n" +
+		"<STMTTRN><DTPOSTED>bad<TRNAMT>-1.00<FITID>T3</STMTTRN>\n" +
+		"</STMTRS></OFX>\n"
+	xml := `<?xml version="1.0"?><?OFX OFXHEADER="200"?>` + "\n" +
+		"<OFX><STMTRS><CURDEF>EUR</CURDEF><BANKACCTFROM><ACCTID>ACC-1</ACCTID></BANKACCTFROM>\n" +
+		"<STMTTRN><DTPOSTED>20240102170000</DTPOSTED><TRNAMT>-12.50</TRNAMT><FITID>T1</FITID>" +
+		"<NAME>Caf&#233; &amp; Co</NAME></STMTTRN>\n" +
+		"</STMTRS></OFX>\n"
+
+	for name, content := range map[string]string{"sgml": sgml, "xml": xml} {
+		transactions, err := parseOFX(strings.NewReader(content), "")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if len(transactions) == 0 || transactions[0].err != nil {
+			t.Fatalf("%s: got transactions %+v, want a valid first transaction", name, transactions)
+		}
+		want := models.StatementTransaction{
+			ID:       "T1",
+			Account:  "ACC-1",
+			PostedAt: time.Date(2024, 1, 2, 17, 0, 0, 0, time.UTC),
+			Amount:   models.Money{Amount: -125000, Currency: "EUR"},
+			Payee:    "Café & Co",
+		}
+		if got := transactions[0].txn; got != want {
+			t.Errorf("%s: got transaction %+v, want %+v", name, got, want)
+		}
+	}
+
+	transactions, _ := parseOFX(strings.NewReader(sgml), "")
+	if len(transactions) != 3 {
+		t.Fatalf("got %d transactions, want 3", len(transactions))
+	}
+	if got := transactions[1].txn.Amount; got.Currency != "USD" {
+		t.Errorf("got currency %s, want the currency of the CURRENCY element", got.Currency)
+	}
+	if transactions[2].line != 14 || transactions[2].err == nil {
+		t.Errorf("got line %d and error %v, want an error on line 14", transactions[2].line, transactions[2].err)
+	}
+}
+
+func TestParseQIF(t *testing.T) {
+	content := "!Type:Bank\nD13/01/2024\nT-1.234,56\nPRent\nLHousing\n^\n" +
+		"D14/01/2024\nT-5,00\nPCoffee\n^\nD14/01/2024\nT-5,00\nPCoffee\n^\n" +
+		"!Type:Cat\nNFood\n^\n"
+
+	transactions, err := parseQIF(strings.NewReader(content), "EUR", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(transactions) != 3 {
+		t.Fatalf("got %d transactions, want 3", len(transactions))
+	}
+	rent := transactions[0].txn
+	if rent.PostedAt != time.Date(2024, 1, 13, 0, 0, 0, 0, time.UTC) {
+		t.Errorf("got date %v, want the day first date 2024-01-13", rent.PostedAt)
+	}
+	if rent.Amount.Amount != -12345600 || rent.Category != "Housing" {
+		t.Errorf("got amount %d and category %q, want -12345600 and Housing", rent.Amount.Amount, rent.Category)
+	}
+	if transactions[1].txn.ID == transactions[2].txn.ID {
+		t.Errorf("got the same ID %s for identical transactions, want distinct IDs", transactions[1].txn.ID)
+	}
+
+	again, _ := parseQIF(strings.NewReader(content), "EUR", "")
+	for i := range transactions {
+		if transactions[i].txn.ID != again[i].txn.ID {
+			t.Errorf("transaction %d: got ID %s on the second parse, want %s", i, again[i].txn.ID, transactions[i].txn.ID)
+		}
+	}
+}
+
+func TestParseStatementAmount(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+	}{
+		{value: "-12.50", want: -125000},
+		{value: "-12,50", want: -125000},
+		{value: "+1,234.56", want: 12345600},
+		{value: "-1.234,56", want: -12345600},
+		{value: "1,234", want: 12340000},
+	}
+	for _, tt := range tests {
+		got, err := parseStatementAmount(tt.value, "EUR")
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.value, err)
+			continue
+		}
+		if got.Amount != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.value, got.Amount, tt.want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/steevehook/expenses-rest-api/models"
+	"github.com/steevehook/expenses-rest-api/repositories"
+)
+
+// fakeSummaryRepo represents an expenses repository that buckets its expenses with models.SummaryKey,
+// the same way BoltDB does
+type fakeSummaryRepo struct {
+	repositories.Expenses
+	expenses []models.Expense
+}
+
+func (r fakeSummaryRepo) Summarize(req models.SummaryRequest) ([]models.SummaryBucket, error) {
+	type bucketID struct{ key, currency, date string }
+	buckets := map[bucketID]*models.SummaryBucket{}
+	ids := make([]bucketID, 0)
+	for _, expense := range r.expenses {
+		id := bucketID{key: models.SummaryKey(req.GroupBy, expense), currency: expense.Price.Currency}
+		if req.ConvertTo != "" {
+			id.date = expense.CreatedAt.UTC().Format(models.DateLayout)
+		}
+		if _, ok := buckets[id]; !ok {
+			buckets[id] = &models.SummaryBucket{Key: id.key, Currency: id.currency, Date: id.date}
+			ids = append(ids, id)
+		}
+		buckets[id].Add(expense.Price)
+	}
+	res := make([]models.SummaryBucket, 0, len(ids))
+	for _, id := range ids {
+		res = append(res, *buckets[id])
+	}
+	return res, nil
+}
+
+// fakeRatesOnRepo represents a rates repository that finds the latest rates on or before a date
+// and records the dates it was asked for
+type fakeRatesOnRepo struct {
+	repositories.Rates
+	days    []models.DailyRates
+	lookups *[]string
+}
+
+func (r fakeRatesOnRepo) GetRatesOn(date string) (models.DailyRates, error) {
+	*r.lookups = append(*r.lookups, date)
+	for i := len(r.days) - 1; i >= 0; i-- {
+		if r.days[i].Date <= date {
+			return r.days[i], nil
+		}
+	}
+	return models.DailyRates{}, models.ResourceNotFoundError{Message: "no rates"}
+}
+
+func TestSummarizeBucketsLikeMariaDB(t *testing.T) {
+	expenses := []models.Expense{
+		summaryExpense("2019-12-30T10:00:00Z", 100, "USD"),
+		summaryExpense("2021-01-01T10:00:00Z", 200, "USD"),
+		summaryExpense("2021-01-03T23:30:00Z", 300, "USD"),
+		summaryExpense("2021-01-04T00:30:00+02:00", 400, "USD"),
+		summaryExpense("2024-12-31T10:00:00Z", 500, "USD"),
+	}
+	tests := []struct {
+		groupBy  string
+		wantKeys []string
+	}{
+		{
+			// DATE_FORMAT(created_at, '%x-W%v') uses ISO weeks and ISO week years
+			groupBy:  models.WeekSummaryGroup,
+			wantKeys: []string{"2020-W01", "2020-W53", "2025-W01"},
+		},
+		{
+			// DATE_FORMAT(created_at, '%Y-%m') of UTC creation times
+			groupBy:  models.MonthSummaryGroup,
+			wantKeys: []string{"2019-12", "2021-01", "2024-12"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.groupBy, func(t *testing.T) {
+			service := Expenses{ExpensesRepo: fakeSummaryRepo{expenses: expenses}}
+			buckets, err := service.Summarize(models.SummaryRequest{GroupBy: test.groupBy})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			keys := make([]string, 0, len(buckets))
+			for _, bucket := range buckets {
+				keys = append(keys, bucket.Key)
+			}
+			if !reflect.DeepEqual(keys, test.wantKeys) {
+				t.Fatalf("expected keys: %v, got: %v", test.wantKeys, keys)
+			}
+			if buckets[1].Count != 3 || buckets[1].Total.String() != "9.00" {
+				t.Fatalf("expected the 3 expenses of early 2021 in one bucket, got: %+v", buckets[1])
+			}
+		})
+	}
+}
+
+func TestSummarizeConvertsBucketsWithTheRatesOfTheirDay(t *testing.T) {
+	expenses := []models.Expense{
+		summaryExpense("2021-03-01T10:00:00Z", 1000, "USD"),
+		summaryExpense("2021-03-01T12:00:00Z", 3000, "USD"),
+		summaryExpense("2021-03-03T10:00:00Z", 1000, "USD"),
+		summaryExpense("2021-03-03T11:00:00Z", 500, "EUR"),
+	}
+	rates := []models.DailyRates{
+		{Date: "2021-03-01", Base: "EUR", Rates: map[string]string{"USD": "1.25"}},
+		{Date: "2021-03-02", Base: "EUR", Rates: map[string]string{"USD": "2"}},
+	}
+	var lookups []string
+	service := Expenses{
+		ExpensesRepo: fakeSummaryRepo{expenses: expenses},
+		RatesRepo:    fakeRatesOnRepo{days: rates, lookups: &lookups},
+	}
+
+	buckets, err := service.Summarize(models.SummaryRequest{GroupBy: models.MonthSummaryGroup, ConvertTo: "EUR"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(buckets) != 1 {
+		t.Fatalf("expected one bucket, got: %+v", buckets)
+	}
+	// 40 USD at 1.25 on 03-01, 10 USD at 2 on 03-03 using the rates of 03-02, and 5 EUR
+	got := buckets[0]
+	want := map[string]string{"total": "42.00", "min": "5.00", "max": "24.00", "average": "10.50"}
+	for name, m := range map[string]models.Money{"total": got.Total, "min": got.Min, "max": got.Max, "average": got.Average} {
+		if m.Currency != "EUR" || m.String() != want[name] {
+			t.Fatalf("expected %s: %s EUR, got: %s %s", name, want[name], m, m.Currency)
+		}
+	}
+	if got.Count != 4 {
+		t.Fatalf("expected count: 4, got: %d", got.Count)
+	}
+
+	sort.Strings(lookups)
+	if wantLookups := []string{"2021-03-01", "2021-03-03"}; !reflect.DeepEqual(lookups, wantLookups) {
+		t.Fatalf("expected one rates lookup per converted day: %v, got: %v", wantLookups, lookups)
+	}
+}
+
+func TestSummarizeWithoutRates(t *testing.T) {
+	var lookups []string
+	service := Expenses{
+		ExpensesRepo: fakeSummaryRepo{expenses: []models.Expense{summaryExpense("2021-03-01T10:00:00Z", 1000, "USD")}},
+		RatesRepo:    fakeRatesOnRepo{lookups: &lookups},
+	}
+
+	_, err := service.Summarize(models.SummaryRequest{GroupBy: models.DaySummaryGroup, ConvertTo: "EUR"})
+	if _, ok := err.(models.DataValidationError); !ok {
+		t.Fatalf("expected data validation error, got: %v", err)
+	}
+}
+
+func summaryExpense(createdAt string, cents int64, currency string) models.Expense {
+	t, _ := time.Parse(time.RFC3339, createdAt)
+	return models.Expense{CreatedAt: t, Price: models.Money{Amount: cents * 100, Currency: currency}}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// Webhooks represents the delivery of events to the configured webhook URLs
+type Webhooks struct {
+	URLs []string
+	// Secret signs the request bodies with HMAC-SHA256 when set, see models.WebhookSignatureHeader
+	Secret string
+	// MaxAttempts and RetryBackoff control the retries of every URL, the backoff doubles after each attempt
+	MaxAttempts  int
+	RetryBackoff time.Duration
+	Client       *http.Client
+}
+
+// Deliver posts an event to every webhook URL, retrying failed attempts until a given context is done,
+// and returns the total amount of attempts along with the last delivery error
+func (w Webhooks) Deliver(ctx context.Context, event models.WebhookEvent) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		logging.Logger.Error("could not marshal webhook event", zap.Error(err))
+		return 0, err
+	}
+
+	maxAttempts := w.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	var attempts int
+	var lastErr error
+	for _, url := range w.URLs {
+		backoff := w.RetryBackoff
+		for attempt := 1; attempt <= maxAttempts; attempt++ {
+			attempts++
+			retry, err := w.post(ctx, url, body)
+			if err == nil {
+				break
+			}
+			logging.Logger.Warn(
+				"could not deliver webhook event",
+				zap.String("url", url),
+				zap.Int("attempt", attempt),
+				zap.Error(err),
+			)
+			if !retry || attempt == maxAttempts || ctx.Err() != nil {
+				lastErr = err
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return attempts, ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+	}
+	return attempts, lastErr
+}
+
+// post sends a single webhook request and reports whether a failed request is worth retrying
+func (w Webhooks) post(ctx context.Context, url string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set(models.ContentType, models.ApplicationJSONType)
+	if w.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.Secret))
+		mac.Write(body)
+		req.Header.Set(models.WebhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, nil
+	}
+	retry := res.StatusCode >= 500 || res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusRequestTimeout
+	return retry, fmt.Errorf("webhook responded with status: %d", res.StatusCode)
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+func TestMain(m *testing.M) {
+	logging.Logger = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+func TestWebhooksDeliver(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{
+			name:         "delivered at once",
+			statuses:     []int{http.StatusOK},
+			wantAttempts: 1,
+		},
+		{
+			name:         "retried after server errors",
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusNoContent},
+			wantAttempts: 3,
+		},
+		{
+			name:         "gives up after max attempts",
+			statuses:     []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+			wantAttempts: 3,
+			wantErr:      true,
+		},
+		{
+			name:         "client errors are not retried",
+			statuses:     []int{http.StatusBadRequest, http.StatusOK},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get(models.WebhookSignatureHeader) == "" {
+					t.Errorf("expected signed webhook request")
+				}
+				w.WriteHeader(test.statuses[calls])
+				calls++
+			}))
+			defer server.Close()
+
+			webhooks := Webhooks{URLs: []string{server.URL}, Secret: "secret", MaxAttempts: 3}
+			attempts, err := webhooks.Deliver(context.Background(), models.WebhookEvent{Event: models.BudgetThresholdEvent})
+			if attempts != test.wantAttempts {
+				t.Fatalf("expected attempts: %d, got: %d", test.wantAttempts, attempts)
+			}
+			if (err != nil) != test.wantErr {
+				t.Fatalf("expected error: %v, got: %v", test.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package transport
+
+import (
+	"encoding/csv"
+	"errors"
+	"mime"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/steevehook/expenses-rest-api/logging"
+	"github.com/steevehook/expenses-rest-api/models"
+)
+
+// csvFlushRows represents the amount of rows after which the CSV response gets flushed to the client
+const csvFlushRows = 200
+
+// CSVWriter streams CSV responses, rows are flushed to the client every few rows instead of buffering
+// the whole response. The response starts with the first row, so errors can still be sent as JSON until then.
+// When the server allows it, every flush moves the write deadline by the write timeout,
+// so large exports are only bound by the write timeout between two flushes
+type CSVWriter struct {
+	w            http.ResponseWriter
+	rc           *http.ResponseController
+	csv          *csv.Writer
+	filename     string
+	header       []string
+	writeTimeout time.Duration
+	rows         int
+	started      bool
+}
+
+// NewCSVWriter creates a new CSV response writer, the response is sent as an attachment of a given file name
+func NewCSVWriter(w http.ResponseWriter, filename string, header []string, writeTimeout time.Duration) *CSVWriter {
+	return &CSVWriter{
+		w:            w,
+		rc:           http.NewResponseController(w),
+		csv:          csv.NewWriter(w),
+		filename:     filename,
+		header:       header,
+		writeTimeout: writeTimeout,
+	}
+}
+
+// Write writes a row, starting the response on the first row
+func (c *CSVWriter) Write(row []string) error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	if err := c.csv.Write(row); err != nil {
+		return err
+	}
+	c.rows++
+	if c.rows%csvFlushRows == 0 {
+		return c.flush()
+	}
+	return nil
+}
+
+// Close flushes the rows that were not sent yet, responses without rows only have the header
+func (c *CSVWriter) Close() error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	return c.flush()
+}
+
+// Fail reports an error that occurred while streaming. The error is sent as JSON if the response did not start yet,
+// otherwise the response gets aborted, so that the client does not mistake a partial response for a complete one
+func (c *CSVWriter) Fail(err error) {
+	if !c.started {
+		SendHTTPError(c.w, err)
+		return
+	}
+	logging.Logger.Error("aborting csv response", zap.Int("rows", c.rows), zap.Error(err))
+	panic(http.ErrAbortHandler)
+}
+
+func (c *CSVWriter) start() error {
+	if c.started {
+		return nil
+	}
+	c.started = true
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": c.filename})
+	c.w.Header().Set(models.ContentType, models.TextCSVType+"; charset=utf-8")
+	c.w.Header().Set(models.ContentDispositionHeader, disposition)
+	c.w.WriteHeader(http.StatusOK)
+	c.extendDeadline()
+	return c.csv.Write(c.header)
+}
+
+func (c *CSVWriter) flush() error {
+	c.csv.Flush()
+	if err := c.csv.Error(); err != nil {
+		return err
+	}
+	if err := c.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	c.extendDeadline()
+	return nil
+}
+
+func (c *CSVWriter) extendDeadline() {
+	if c.writeTimeout <= 0 {
+		return
+	}
+	if err := c.rc.SetWriteDeadline(time.Now().Add(c.writeTimeout)); err != nil {
+		logging.Logger.Debug("could not extend the write deadline", zap.Error(err))
+	}
+}
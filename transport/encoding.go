@@ -2,6 +2,8 @@ package transport
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 
 	"go.uber.org/zap"
@@ -17,13 +19,23 @@ const (
 	FormatValidationErrorType = "format_validation_error"
 	// ResourceNotFoundErrorType describes a severe resource not found
 	ResourceNotFoundErrorType = "resource_not_found"
+	// UnauthorizedErrorType describes missing or invalid credentials
+	UnauthorizedErrorType = "unauthorized"
+	// ForbiddenErrorType describes callers that are not allowed to perform an action
+	ForbiddenErrorType = "forbidden"
+	// ConflictErrorType describes requests that conflict with the current state of a resource
+	ConflictErrorType = "conflict"
+	// PayloadTooLargeErrorType describes request bodies over the size limit of an endpoint
+	PayloadTooLargeErrorType = "payload_too_large"
+	// StorageUnavailableErrorType describes transient storage failures
+	StorageUnavailableErrorType = "storage_unavailable"
 	// ServiceErrorType describes a severe generic server error
 	ServiceErrorType = "service_error"
 )
 
 // SendHTTPError converts errors into HTTP JSON errors
 func SendHTTPError(w http.ResponseWriter, err error) {
-	httpError := toHTTPError(err)
+	httpError := ToHTTPError(err)
 	SendJSON(w, httpError.Code, httpError)
 }
 
@@ -37,7 +49,17 @@ func SendJSON(w http.ResponseWriter, statusCode int, response interface{}) {
 	}
 }
 
-func toHTTPError(err error) models.HTTPError {
+// ToHTTPError converts errors into HTTP errors along with their status code
+func ToHTTPError(err error) models.HTTPError {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return models.HTTPError{
+			Code:    http.StatusRequestEntityTooLarge,
+			Type:    PayloadTooLargeErrorType,
+			Message: fmt.Sprintf("request body must not be larger than %d bytes", maxBytesErr.Limit),
+		}
+	}
+
 	switch e := err.(type) {
 	case models.HTTPError:
 		return e
@@ -70,6 +92,34 @@ func toHTTPError(err error) models.HTTPError {
 			Message: e.Message,
 		}
 
+	case models.UnauthorizedError:
+		return models.HTTPError{
+			Code:    http.StatusUnauthorized,
+			Type:    UnauthorizedErrorType,
+			Message: e.Error(),
+		}
+
+	case models.ForbiddenError:
+		return models.HTTPError{
+			Code:    http.StatusForbidden,
+			Type:    ForbiddenErrorType,
+			Message: e.Error(),
+		}
+
+	case models.ConflictError:
+		return models.HTTPError{
+			Code:    http.StatusConflict,
+			Type:    ConflictErrorType,
+			Message: e.Error(),
+		}
+
+	case models.StorageUnavailableError:
+		return models.HTTPError{
+			Code:    http.StatusServiceUnavailable,
+			Type:    StorageUnavailableErrorType,
+			Message: e.Error(),
+		}
+
 	default:
 		return models.HTTPError{
 			Code:    http.StatusInternalServerError,